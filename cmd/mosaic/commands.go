@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/report"
+	"github.com/senayuki/mosaic/types"
+)
+
+// rules & inputs of command
+type job struct {
+	rules  types.KVRules
+	m      processer.KVProcesser
+	format inputFormat
+	inputs []input
+}
+
+func (c *cli) load(common commonFlags, paths []string) (job, error) {
+	var j job
+	var err error
+	if j.rules, j.m, err = loadRules(common.rules); err != nil {
+		return j, err
+	}
+	if j.format, err = parseFormat(common.format); err != nil {
+		return j, err
+	}
+	j.inputs, err = c.readInputs(paths)
+	return j, err
+}
+
+func (c *cli) scan(ctx context.Context, args []string) error {
+	var common commonFlags
+	fs := c.flagSet("scan", "scan [flags] [path ...]", &common)
+	output := fs.String("output", string(reportTable), "report format: table, json, sarif or junit")
+	paths, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if _, ok := reportFormats[reportFormat(*output)]; !ok {
+		return fmt.Errorf("unknown output %q", *output)
+	}
+	j, err := c.load(common, paths)
+	if err != nil {
+		return err
+	}
+	var findings []report.Finding
+	var failed bool
+	files := make([]string, 0, len(j.inputs))
+	found := map[string]struct{}{}
+	for _, in := range j.inputs {
+		files = append(files, in.name)
+		_, lines, err := maskInput(ctx, j.m, in.format(j.format), in.data)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "mosaic: %s: %v\n", in.name, err)
+			failed = true
+			continue
+		}
+		for _, line := range lines {
+			doc := report.Document{File: in.name, Input: in.data[line.start:line.end], Offset: line.start, Line: line.line}
+			findings = append(findings, doc.Findings(line.pairs)...)
+			found[in.name] = struct{}{}
+		}
+	}
+	r := report.Report{Rules: report.NewRules(j.rules), Files: files, Findings: findings}
+	if err := writeReport(c.stdout, reportFormat(*output), r); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stderr, "%d findings in %d of %d files\n", len(findings), len(found), len(j.inputs))
+	switch {
+	case failed:
+		return exitCode(exitError)
+	case len(findings) > 0:
+		return exitCode(exitDetected)
+	}
+	return nil
+}
+
+func (c *cli) mask(ctx context.Context, args []string) error {
+	var common commonFlags
+	fs := c.flagSet("mask", "mask [flags] [path ...]", &common)
+	pairsPath := fs.String("pairs", "", "write masked pairs with original values to file, for unmask")
+	outDir := fs.String("out-dir", "", "write masked files to directory, keeping paths relative to walked directories")
+	inPlace := fs.Bool("in-place", false, "overwrite input files by masked output")
+	paths, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if *inPlace && *outDir != "" {
+		return fmt.Errorf("-in-place and -out-dir are exclusive")
+	}
+	j, err := c.load(common, paths)
+	if err != nil {
+		return err
+	}
+	if len(j.inputs) > 1 && !*inPlace && *outDir == "" {
+		return fmt.Errorf("%d inputs, write them by -out-dir or -in-place", len(j.inputs))
+	}
+	var records []pairRecord
+	for _, in := range j.inputs {
+		if in.name == "-" && *inPlace {
+			return fmt.Errorf("stdin can not be masked in place")
+		}
+		masked, lines, err := maskInput(ctx, j.m, in.format(j.format), in.data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", in.name, err)
+		}
+		for _, line := range lines {
+			for _, pair := range line.pairs {
+				record, err := newPairRecord(in.name, line.line, pair, true)
+				if err != nil {
+					return fmt.Errorf("%s: %w", in.name, err)
+				}
+				records = append(records, record)
+			}
+		}
+		if err := c.writeOutput(in, masked, *outDir, *inPlace); err != nil {
+			return err
+		}
+	}
+	if *pairsPath != "" {
+		return writePairs(*pairsPath, records)
+	}
+	return nil
+}
+
+// write masked input to stdout, out dir or input file
+func (c *cli) writeOutput(in input, data []byte, outDir string, inPlace bool) error {
+	switch {
+	case inPlace:
+		info, err := os.Stat(in.name)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(in.name, data, info.Mode().Perm())
+	case outDir != "":
+		name := filepath.Join(outDir, in.rel)
+		if in.name == "-" {
+			name = filepath.Join(outDir, "stdin")
+		}
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(name, data, 0o644)
+	default:
+		_, err := c.stdout.Write(data)
+		return err
+	}
+}
+
+func (c *cli) unmask(ctx context.Context, args []string) error {
+	var common commonFlags
+	fs := c.flagSet("unmask", "unmask -pairs FILE [flags] [path]", &common)
+	pairsPath := fs.String("pairs", "", "pairs file written by mask -pairs (required)")
+	file := fs.String("file", "", "use pairs of this input of mask, required if pairs file has pairs of many inputs")
+	paths, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if *pairsPath == "" {
+		return fmt.Errorf("-pairs is required")
+	}
+	if len(paths) > 1 {
+		return fmt.Errorf("unmask accepts one input, got %d", len(paths))
+	}
+	j, err := c.load(common, paths)
+	if err != nil {
+		return err
+	}
+	if len(j.inputs) != 1 {
+		return fmt.Errorf("unmask accepts one input, got %d", len(j.inputs))
+	}
+	records, err := readPairs(*pairsPath)
+	if err != nil {
+		return err
+	}
+	pairs, err := selectPairs(records, *file)
+	if err != nil {
+		return err
+	}
+	in := j.inputs[0]
+	unmasked, err := unmaskInput(ctx, j.m, in.format(j.format), in.data, pairs)
+	if err != nil {
+		return fmt.Errorf("%s: %w", in.name, err)
+	}
+	_, err = c.stdout.Write(unmasked)
+	return err
+}
+
+// pairs of file grouped by line, all pairs if file is empty & pairs are of one input
+func selectPairs(records []pairRecord, file string) (map[int][]types.KVPair, error) {
+	if file == "" {
+		files := map[string]struct{}{}
+		for _, r := range records {
+			files[r.File] = struct{}{}
+		}
+		if len(files) > 1 {
+			names := make([]string, 0, len(files))
+			for name := range files {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("pairs of %d inputs %v, select one by -file", len(names), names)
+		}
+	}
+	pairs := map[int][]types.KVPair{}
+	for _, r := range records {
+		if file != "" && r.File != file {
+			continue
+		}
+		pair, err := r.pair()
+		if err != nil {
+			return nil, fmt.Errorf("pair at %s: %w", r.Path, err)
+		}
+		pairs[r.Line] = append(pairs[r.Line], pair)
+	}
+	return pairs, nil
+}
@@ -0,0 +1,13 @@
+module github.com/senayuki/mosaic/cmd/mosaic
+
+go 1.18
+
+replace github.com/senayuki/mosaic => ../..
+
+require github.com/senayuki/mosaic v0.0.0-00010101000000-000000000000
+
+require (
+	github.com/valyala/fastjson v1.6.4 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
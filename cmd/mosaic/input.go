@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+)
+
+type inputFormat string
+
+const (
+	formatAuto   inputFormat = "auto"   // by extension, or by content: JSON, NDJSON, then text
+	formatJSON   inputFormat = "json"   // one JSON document
+	formatNDJSON inputFormat = "ndjson" // JSON document per line, blank lines are kept
+	formatText   inputFormat = "text"   // free text per line, scanned by KVProcesser.MaskText
+)
+
+func parseFormat(s string) (inputFormat, error) {
+	switch f := inputFormat(s); f {
+	case formatAuto, formatJSON, formatNDJSON, formatText:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown format %q", s)
+}
+
+type input struct {
+	name string // path, "-" for stdin
+	rel  string // path relative to walked directory, base name for files
+	data []byte
+}
+
+// read inputs of paths, directories are walked recursively without hidden directories
+// binary files in directories are skipped
+func (c *cli) readInputs(paths []string) ([]input, error) {
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+	var inputs []input
+	for _, path := range paths {
+		if path == "-" {
+			data, err := io.ReadAll(c.stdin)
+			if err != nil {
+				return nil, fmt.Errorf("stdin: %w", err)
+			}
+			inputs = append(inputs, input{name: "-", rel: "-", data: data})
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, input{name: path, rel: filepath.Base(path), data: data})
+			continue
+		}
+		err = filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if name != path && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			data, err := os.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			if isBinary(data) {
+				return nil
+			}
+			rel, err := filepath.Rel(path, name)
+			if err != nil {
+				return err
+			}
+			inputs = append(inputs, input{name: name, rel: rel, data: data})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return inputs, nil
+}
+
+// NUL in the first 8000 bytes like git
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// format of input, auto is resolved by extension, then by content
+func (in input) format(format inputFormat) inputFormat {
+	if format != formatAuto {
+		return format
+	}
+	switch strings.ToLower(filepath.Ext(in.name)) {
+	case ".json":
+		return formatJSON
+	case ".ndjson", ".jsonl":
+		return formatNDJSON
+	}
+	trimmed := bytes.TrimSpace(in.data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		if json.Valid(trimmed) {
+			return formatJSON
+		}
+		ndjson := true
+		for _, line := range bytes.Split(trimmed, []byte("\n")) {
+			if line = bytes.TrimSpace(line); len(line) > 0 && !json.Valid(line) {
+				ndjson = false
+				break
+			}
+		}
+		if ndjson {
+			return formatNDJSON
+		}
+	}
+	return formatText
+}
+
+// pairs masked in document, line is 1-based line of NDJSON & text, 0 for JSON
+// [start, end) is byte span of document in input
+type linePairs struct {
+	line       int
+	start, end int
+	pairs      []types.KVPair
+}
+
+/*
+mask input, returns masked data & pairs of lines
+NDJSON & text are masked line by line, line endings & blank lines are kept
+*/
+func maskInput(ctx context.Context, m processer.KVProcesser, format inputFormat, data []byte) ([]byte, []linePairs, error) {
+	if format == formatJSON {
+		masked, pairs, err := m.Mask(ctx, data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(pairs) == 0 {
+			return data, nil, nil
+		}
+		return masked, []linePairs{{end: len(data), pairs: pairs}}, nil
+	}
+	var out bytes.Buffer
+	out.Grow(len(data))
+	var result []linePairs
+	offset := 0
+	for idx, line := range bytes.SplitAfter(data, []byte("\n")) {
+		start := offset
+		offset += len(line)
+		content, ending := splitLineEnding(line)
+		if len(bytes.TrimSpace(content)) == 0 {
+			out.Write(line)
+			continue
+		}
+		var masked []byte
+		var pairs []types.KVPair
+		var err error
+		if format == formatText {
+			var text string
+			text, pairs, err = m.MaskText(ctx, string(content))
+			masked = []byte(text)
+		} else {
+			masked, pairs, err = m.Mask(ctx, content)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", idx+1, err)
+		}
+		if len(pairs) == 0 {
+			out.Write(line)
+			continue
+		}
+		out.Write(masked)
+		out.Write(ending)
+		result = append(result, linePairs{line: idx + 1, start: start, end: start + len(content), pairs: pairs})
+	}
+	return out.Bytes(), result, nil
+}
+
+// restore input masked by maskInput, text can not be unmasked
+func unmaskInput(ctx context.Context, m processer.KVProcesser, format inputFormat, data []byte, pairs map[int][]types.KVPair) ([]byte, error) {
+	switch format {
+	case formatJSON:
+		return m.Unmask(ctx, data, pairs[0])
+	case formatText:
+		return nil, fmt.Errorf("unmask of text is not supported")
+	}
+	var out bytes.Buffer
+	out.Grow(len(data))
+	for idx, line := range bytes.SplitAfter(data, []byte("\n")) {
+		linePairs, ok := pairs[idx+1]
+		if !ok {
+			out.Write(line)
+			continue
+		}
+		content, ending := splitLineEnding(line)
+		unmasked, err := m.Unmask(ctx, content, linePairs)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", idx+1, err)
+		}
+		out.Write(unmasked)
+		out.Write(ending)
+	}
+	return out.Bytes(), nil
+}
+
+func splitLineEnding(line []byte) ([]byte, []byte) {
+	content := bytes.TrimRight(line, "\r\n")
+	return content, line[len(content):]
+}
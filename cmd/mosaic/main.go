@@ -0,0 +1,120 @@
+// command mosaic scans, masks & unmasks JSON, NDJSON and text files by rules
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	exitOK       = 0
+	exitDetected = 1 // sensitive data found by scan
+	exitError    = 2 // usage, rules or input error
+)
+
+const usage = `usage: mosaic <command> [flags] [path ...]
+
+commands:
+  scan    report sensitive data, exits with 1 if found
+  mask    write masked output
+  unmask  restore masked output by pairs written by mask --pairs
+  serve   serve HTTP API of detect, mask & unmask with rule set management
+
+paths are files or directories walked recursively, stdin is read if no path or "-"
+run "mosaic <command> -h" for flags of command
+`
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run command, returns exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitError
+	}
+	c := &cli{stdin: stdin, stdout: stdout, stderr: stderr}
+	var err error
+	switch args[0] {
+	case "scan":
+		err = c.scan(ctx, args[1:])
+	case "mask":
+		err = c.mask(ctx, args[1:])
+	case "unmask":
+		err = c.unmask(ctx, args[1:])
+	case "serve":
+		err = c.serve(ctx, args[1:])
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "mosaic: unknown command %q\n\n%s", args[0], usage)
+		return exitError
+	}
+	var exit exitCode
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &exit):
+		return int(exit)
+	default:
+		fmt.Fprintf(stderr, "mosaic: %v\n", err)
+		return exitError
+	}
+}
+
+// exit with code, message is printed already
+type exitCode int
+
+func (e exitCode) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+type cli struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+// flag set of command with shared flags
+type commonFlags struct {
+	rules  string
+	format string
+}
+
+func (c *cli) flagSet(name, usage string, common *commonFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: mosaic %s\n\nflags:\n", usage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&common.rules, "rules", "", "rules file in YAML or JSON (required)")
+	fs.StringVar(&common.format, "format", string(formatAuto), "input format: auto, json, ndjson or text")
+	return fs
+}
+
+// parse flags placed before or after paths, args after "--" are paths
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var paths []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(paths, rest...), nil
+		}
+		if len(rest) == 0 {
+			return paths, nil
+		}
+		paths = append(paths, rest[0])
+		args = rest[1:]
+	}
+}
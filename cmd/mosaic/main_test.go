@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testRules = `
+detectRules:
+  - keyEqs: [password, phone]
+  - id: token
+    severity: high
+    category: credential
+    keyEqs: [token]
+    maskRef: drop
+maskRules:
+  - ruleName: drop
+    maskType: drop
+`
+
+// write files under temp dir, returns dir
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// run in dir, so paths are relative to dir
+func runCLI(t *testing.T, dir, stdin string, args ...string) (int, string, string) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	t.Setenv(adminTokenEnv, "")
+	files := map[string]string{
+		"rules.yaml":       testRules,
+		"data/a.json":      `{"password":"abc","n":1,"token":"t"}`,
+		"data/b.ndjson":    "{\"phone\":123}\n\n{\"name\":\"x\"}\n",
+		"data/c.log":       "login password=secret ok\n",
+		"data/.git/x.json": `{"password":"hidden"}`,
+		"clean.json":       `{"name":"a"}`,
+		"token.json":       `{"token":"t"}`,
+	}
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		wantCode   int
+		wantStdout string
+	}{
+		{
+			name:     "scan directory",
+			args:     []string{"scan", "-rules", "rules.yaml", "data"},
+			wantCode: exitDetected,
+			wantStdout: `FILE           LINE  PATH      KEY       MASKED
+data/a.json    1     password  password  "***"
+data/a.json    1     token     token     <dropped>
+data/b.ndjson  1     phone     phone     "***"
+data/c.log     1     password  password  "******"
+`,
+		},
+		{
+			name:       "scan json output & flags after paths",
+			args:       []string{"scan", "data/b.ndjson", "-output", "json", "-rules", "rules.yaml"},
+			wantCode:   exitDetected,
+			wantStdout: "[\n  {\n    \"file\": \"data/b.ndjson\",\n    \"line\": 1,\n    \"column\": 10,\n    \"key\": \"phone\",\n    \"path\": [\n      \"phone\"\n    ],\n    \"valMasked\": \"***\"\n  }\n]\n",
+		},
+		{
+			name:       "scan json output with rule metadata",
+			args:       []string{"scan", "-rules", "rules.yaml", "-output", "json", "token.json"},
+			wantCode:   exitDetected,
+			wantStdout: "[\n  {\n    \"file\": \"token.json\",\n    \"line\": 1,\n    \"column\": 10,\n    \"key\": \"token\",\n    \"path\": [\n      \"token\"\n    ],\n    \"dropped\": true,\n    \"rule\": \"token\",\n    \"severity\": \"high\",\n    \"category\": \"credential\"\n  }\n]\n",
+		},
+		{
+			name:       "scan clean file",
+			args:       []string{"scan", "-rules", "rules.yaml", "-output", "json", "clean.json"},
+			wantCode:   exitOK,
+			wantStdout: "[]\n",
+		},
+		{
+			name:       "mask stdin as text",
+			args:       []string{"mask", "-rules", "rules.yaml", "-format", "text"},
+			stdin:      "phone: 123, token=abc\n",
+			wantCode:   exitOK,
+			wantStdout: "phone: ***, token=\n",
+		},
+		{
+			name:       "mask stdin as ndjson",
+			args:       []string{"mask", "-rules", "rules.yaml"},
+			stdin:      "{\"password\":\"abc\"}\r\n{\"token\":1}\n",
+			wantCode:   exitOK,
+			wantStdout: "{\"password\":\"***\"}\r\n{}\n",
+		},
+		{
+			name:     "mask many inputs to stdout",
+			args:     []string{"mask", "-rules", "rules.yaml", "data"},
+			wantCode: exitError,
+		},
+		{
+			name:     "missing rules",
+			args:     []string{"scan", "data"},
+			wantCode: exitError,
+		},
+		{
+			name:     "unknown rule field",
+			args:     []string{"scan", "-rules", "data/a.json", "data"},
+			wantCode: exitError,
+		},
+		{
+			name:     "serve invalid rules",
+			args:     []string{"serve", "-addr", "127.0.0.1:0", "-rules", "data/a.json"},
+			wantCode: exitError,
+		},
+		{
+			name:     "serve admin without token",
+			args:     []string{"serve", "-addr", ":0", "-rules", "rules.yaml"},
+			wantCode: exitError,
+		},
+		{
+			name:     "unknown command",
+			args:     []string{"check"},
+			wantCode: exitError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, files)
+			code, stdout, stderr := runCLI(t, dir, tt.stdin, tt.args...)
+			if code != tt.wantCode {
+				t.Fatalf("run() = %d, want %d, stderr: %s", code, tt.wantCode, stderr)
+			}
+			if tt.wantStdout != "" && stdout != tt.wantStdout {
+				t.Errorf("run() stdout = %q, want %q", stdout, tt.wantStdout)
+			}
+		})
+	}
+}
+
+func TestRun_MaskUnmask(t *testing.T) {
+	input := "{\"phone\":12345678901234567890,\"token\":\"t\",\"name\":\"a\"}\n\n{\"password\":\"abc\"}\n"
+	dir := writeFiles(t, map[string]string{"rules.yaml": testRules, "data/in.ndjson": input})
+	code, _, stderr := runCLI(t, dir, "", "mask", "-rules", "rules.yaml", "-pairs", "pairs.json", "-out-dir", "out", "data")
+	if code != exitOK {
+		t.Fatalf("mask = %d, stderr: %s", code, stderr)
+	}
+	masked, err := os.ReadFile(filepath.Join(dir, "out", "in.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"phone\":\"********************\",\"name\":\"a\"}\n\n{\"password\":\"***\"}\n"; string(masked) != want {
+		t.Errorf("mask = %q, want %q", masked, want)
+	}
+	code, stdout, stderr := runCLI(t, dir, "", "unmask", "-rules", "rules.yaml", "-pairs", "pairs.json", "out/in.ndjson")
+	if code != exitOK {
+		t.Fatalf("unmask = %d, stderr: %s", code, stderr)
+	}
+	if want := "{\"phone\":12345678901234567890,\"name\":\"a\",\"token\":\"t\"}\n\n{\"password\":\"abc\"}\n"; stdout != want {
+		t.Errorf("unmask = %q, want %q", stdout, want)
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1:8080": true,
+		"[::1]:8080":     true,
+		"localhost:8080": true,
+		":8080":          false,
+		"0.0.0.0:8080":   false,
+		"10.0.0.1:8080":  false,
+		"invalid":        false,
+	} {
+		if got := isLoopback(addr); got != want {
+			t.Errorf("isLoopback(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/senayuki/mosaic/report"
+	"github.com/senayuki/mosaic/types"
+)
+
+/*
+masked pair in JSON, values are JSON values, numbers are kept as written
+original value is written only in pairs files for unmask, never in scan reports
+*/
+type pairRecord struct {
+	File       string          `json:"file,omitempty"`
+	Line       int             `json:"line,omitempty"`
+	Column     int             `json:"column,omitempty"` // of value in scan reports
+	Key        string          `json:"key"`
+	Path       types.JSONPath  `json:"path"`
+	Val        json.RawMessage `json:"val,omitempty"`
+	ValMasked  json.RawMessage `json:"valMasked,omitempty"`
+	Dropped    bool            `json:"dropped,omitempty"`
+	ObjectKey  bool            `json:"objectKey,omitempty"`
+	KVFieldRel *types.KVField  `json:"kvFieldRel,omitempty"`
+	Rule       string          `json:"rule,omitempty"` // ID of matched rule
+	Severity   string          `json:"severity,omitempty"`
+	Category   string          `json:"category,omitempty"`
+}
+
+func newPairRecord(file string, line int, pair types.KVPair, withVal bool) (pairRecord, error) {
+	record := pairRecord{File: file, Line: line, Key: pair.Key, Path: pair.ValJSONPath, ObjectKey: pair.ObjectKey, KVFieldRel: pair.KVFieldRel}
+	if rule := pair.Rule; rule != nil {
+		record.Rule, record.Severity, record.Category = rule.ID, string(rule.Severity), string(rule.Category)
+	}
+	var err error
+	if withVal {
+		if record.Val, err = json.Marshal(pair.Val); err != nil {
+			return record, err
+		}
+	}
+	if _, dropped := pair.ValMasked.(types.KVDropped); dropped {
+		record.Dropped = true
+	} else if record.ValMasked, err = json.Marshal(pair.ValMasked); err != nil {
+		return record, err
+	}
+	return record, nil
+}
+
+// pair of record, numbers are decoded as json.Number
+func (r pairRecord) pair() (types.KVPair, error) {
+	pair := types.KVPair{Key: r.Key, ValJSONPath: r.Path, ObjectKey: r.ObjectKey, KVFieldRel: r.KVFieldRel}
+	if pair.ValJSONPath.Elements() == nil {
+		pair.ValJSONPath = types.NewJSONPath()
+	}
+	var err error
+	if pair.Val, err = decodeJSONValue(r.Val); err != nil {
+		return pair, err
+	}
+	if r.Dropped {
+		pair.ValMasked = types.KVDropped{}
+	} else if pair.ValMasked, err = decodeJSONValue(r.ValMasked); err != nil {
+		return pair, err
+	}
+	return pair, nil
+}
+
+// empty value is null
+func decodeJSONValue(data json.RawMessage) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var val interface{}
+	if err := decoder.Decode(&val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+func writePairs(path string, records []pairRecord) error {
+	if records == nil {
+		records = []pairRecord{}
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+func readPairs(path string) ([]pairRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var records []pairRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("pairs %s: %w", path, err)
+	}
+	return records, nil
+}
+
+type reportFormat string
+
+const (
+	reportTable reportFormat = "table"
+	reportJSON  reportFormat = "json"
+	reportSARIF reportFormat = "sarif"
+	reportJUnit reportFormat = "junit"
+)
+
+var reportFormats = map[reportFormat]struct{}{reportTable: {}, reportJSON: {}, reportSARIF: {}, reportJUnit: {}}
+
+// write findings of scan, JSON is an array of records without original values
+func writeReport(w io.Writer, format reportFormat, r report.Report) error {
+	switch format {
+	case reportSARIF:
+		return r.WriteSARIF(w)
+	case reportJUnit:
+		return r.WriteJUnit(w)
+	}
+	records := make([]pairRecord, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		record, err := newPairRecord(f.File, f.Region.StartLine, f.Pair, false)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.File, err)
+		}
+		record.Column = f.Region.StartColumn
+		records = append(records, record)
+	}
+	switch format {
+	case reportJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case reportTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "FILE\tLINE\tPATH\tKEY\tMASKED")
+		for _, r := range records {
+			line, path, masked := "-", r.Path.String(), string(r.ValMasked)
+			if r.Line > 0 {
+				line = fmt.Sprint(r.Line)
+			}
+			if path == "" {
+				path = "-"
+			}
+			if r.Dropped {
+				masked = types.KVDropped{}.String()
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.File, line, path, r.Key, masked)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unknown output %q", format)
+}
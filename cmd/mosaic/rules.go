@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/senayuki/mosaic/pkg/rulefile"
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+)
+
+// load & compile rules file in YAML or JSON, see rulefile.Parse
+func loadRules(path string) (types.KVRules, processer.KVProcesser, error) {
+	if path == "" {
+		return types.KVRules{}, processer.KVProcesser{}, fmt.Errorf("-rules is required")
+	}
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	return rulefile.Load(os.DirFS(dir), name)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/senayuki/mosaic/http/server"
+)
+
+// env of admin token, not a flag to keep it out of process lists
+const adminTokenEnv = "MOSAIC_ADMIN_TOKEN"
+
+// serve HTTP API until interrupted, see package http/server
+func (c *cli) serve(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: mosaic serve [flags]\n\nadmin endpoints require bearer token of $%s,\nwithout token serving is refused unless address is loopback or -insecure-admin is set\n\nflags:\n", adminTokenEnv)
+		fs.PrintDefaults()
+	}
+	addr := fs.String("addr", "127.0.0.1:8080", "listen address")
+	storeDir := fs.String("store", "", "directory of rule sets, rule sets are kept in memory if empty")
+	rulesPath := fs.String("rules", "", "rules file in YAML or JSON served as default rule set")
+	maxBodySize := fs.Int64("max-body-size", server.DefaultMaxBodySize, "limit of request body in bytes")
+	dictDir := fs.String("dictionaries", "", "directory of dictionary files referenced by rule sets, default is directory of -rules")
+	insecureAdmin := fs.Bool("insecure-admin", false, "serve admin endpoints without token on non-loopback address")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+	config := server.Config{MaxBodySize: *maxBodySize, AdminToken: os.Getenv(adminTokenEnv)}
+	if config.AdminToken == "" {
+		// anyone reaching the port could replace or delete rule sets, and switch masking off
+		if !*insecureAdmin && !isLoopback(*addr) {
+			return fmt.Errorf("admin endpoints on %s require $%s, or -insecure-admin to serve them without token", *addr, adminTokenEnv)
+		}
+		fmt.Fprintf(c.stderr, "warning: admin endpoints are served without token, set $%s to protect them\n", adminTokenEnv)
+	}
+	if *dictDir == "" && *rulesPath != "" {
+		*dictDir = filepath.Dir(*rulesPath)
+	}
+	if *dictDir != "" {
+		config.Dictionaries = os.DirFS(*dictDir)
+	}
+	if *storeDir != "" {
+		store, err := server.NewFileStore(*storeDir)
+		if err != nil {
+			return err
+		}
+		config.Store = store
+	}
+	s := server.New(config)
+	if err := s.Load(ctx); err != nil {
+		return err
+	}
+	if *rulesPath != "" {
+		rules, _, err := loadRules(*rulesPath)
+		if err != nil {
+			return err
+		}
+		if err := s.SetRuleSet(ctx, server.DefaultRuleSet, rules); err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	httpServer := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+	fmt.Fprintf(c.stderr, "serving on %s\n", listener.Addr())
+	if err := httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// whether listen address only accepts local connections
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -2,4 +2,8 @@ module github.com/senayuki/mosaic
 
 go 1.18
 
-require github.com/valyala/fastjson v1.6.4
+require (
+	github.com/valyala/fastjson v1.6.4
+	golang.org/x/text v0.14.0
+	gopkg.in/yaml.v3 v3.0.1
+)
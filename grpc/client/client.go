@@ -0,0 +1,129 @@
+// client of mosaic.v1.Mosaic converting messages to types
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/senayuki/mosaic/grpc/mosaicpb"
+	"github.com/senayuki/mosaic/types"
+	"google.golang.org/grpc"
+)
+
+type Client struct {
+	api mosaicpb.MosaicClient
+}
+
+// create client on connection, connection is not closed by client
+func New(conn grpc.ClientConnInterface) *Client {
+	return &Client{api: mosaicpb.NewMosaicClient(conn)}
+}
+
+// detect by rule set, "" is the default rule set
+func (c *Client) Detect(ctx context.Context, ruleSet string, input []byte) ([]types.KVPair, error) {
+	resp, err := c.api.Detect(ctx, &mosaicpb.DetectRequest{RuleSet: ruleSet, Input: input})
+	if err != nil {
+		return nil, err
+	}
+	return mosaicpb.ToKVPairs(resp.GetPairs())
+}
+
+func (c *Client) Mask(ctx context.Context, ruleSet string, input []byte) ([]byte, []types.KVPair, error) {
+	resp, err := c.api.Mask(ctx, &mosaicpb.MaskRequest{RuleSet: ruleSet, Input: input})
+	if err != nil {
+		return nil, nil, err
+	}
+	pairs, err := mosaicpb.ToKVPairs(resp.GetPairs())
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.GetOutput(), pairs, nil
+}
+
+// restore masked JSON by pairs returned from Mask
+func (c *Client) Unmask(ctx context.Context, ruleSet string, input []byte, pairs []types.KVPair) ([]byte, error) {
+	msgs, err := mosaicpb.FromKVPairs(pairs)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.api.Unmask(ctx, &mosaicpb.UnmaskRequest{RuleSet: ruleSet, Input: input, Pairs: msgs})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetOutput(), nil
+}
+
+// returns error of invalid rules, or nil if rules is valid
+func (c *Client) ValidateRules(ctx context.Context, rules types.KVRules) error {
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	resp, err := c.api.ValidateRules(ctx, &mosaicpb.ValidateRulesRequest{Rules: data})
+	if err != nil {
+		return err
+	}
+	if !resp.GetValid() {
+		return errors.New(resp.GetError())
+	}
+	return nil
+}
+
+// rule sets by name
+func (c *Client) ListRules(ctx context.Context) (map[string]types.KVRules, error) {
+	resp, err := c.api.ListRules(ctx, &mosaicpb.ListRulesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	ruleSets := make(map[string]types.KVRules, len(resp.GetRuleSets()))
+	for _, set := range resp.GetRuleSets() {
+		var rules types.KVRules
+		if err := json.Unmarshal(set.GetRules(), &rules); err != nil {
+			return nil, err
+		}
+		ruleSets[set.GetName()] = rules
+	}
+	return ruleSets, nil
+}
+
+// masked NDJSON batch
+type MaskBatch struct {
+	Batch  []byte
+	Pairs  []types.KVPair
+	Errors []string // errors of lines, failed lines are empty in Batch
+}
+
+type MaskStream struct {
+	stream  mosaicpb.Mosaic_MaskStreamClient
+	ruleSet string
+}
+
+// open stream masking NDJSON batches by rule set
+func (c *Client) MaskStream(ctx context.Context, ruleSet string) (*MaskStream, error) {
+	stream, err := c.api.MaskStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &MaskStream{stream: stream, ruleSet: ruleSet}, nil
+}
+
+func (s *MaskStream) Send(batch []byte) error {
+	return s.stream.Send(&mosaicpb.MaskStreamRequest{RuleSet: s.ruleSet, Batch: batch})
+}
+
+func (s *MaskStream) Recv() (MaskBatch, error) {
+	resp, err := s.stream.Recv()
+	if err != nil {
+		return MaskBatch{}, err
+	}
+	pairs, err := mosaicpb.ToKVPairs(resp.GetPairs())
+	if err != nil {
+		return MaskBatch{}, err
+	}
+	return MaskBatch{Batch: resp.GetBatch(), Pairs: pairs, Errors: resp.GetErrors()}, nil
+}
+
+func (s *MaskStream) CloseSend() error {
+	return s.stream.CloseSend()
+}
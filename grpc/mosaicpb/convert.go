@@ -0,0 +1,123 @@
+package mosaicpb
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+// convert pair to message, values & path are encoded as JSON text
+func FromKVPair(pair types.KVPair) (*KVPair, error) {
+	val, err := json.Marshal(pair.Val)
+	if err != nil {
+		return nil, err
+	}
+	path, err := json.Marshal(pair.ValJSONPath)
+	if err != nil {
+		return nil, err
+	}
+	msg := &KVPair{Key: pair.Key, Val: string(val), ValJsonPath: string(path), ObjectKey: pair.ObjectKey, DetectRule: int32(pair.DetectRule)}
+	if _, dropped := pair.ValMasked.(types.KVDropped); dropped {
+		msg.Dropped = true
+	} else {
+		valMasked, err := json.Marshal(pair.ValMasked)
+		if err != nil {
+			return nil, err
+		}
+		msg.ValMasked = string(valMasked)
+	}
+	if pair.KVFieldRel != nil {
+		msg.KvFieldRel = &KVField{Key: pair.KVFieldRel.Key, Val: pair.KVFieldRel.Val, Vals: pair.KVFieldRel.Vals}
+	}
+	if rule := pair.Rule; rule != nil {
+		msg.Rule = &KVRuleMeta{
+			Id: rule.ID, Name: rule.Name, Description: rule.Description, Severity: string(rule.Severity),
+			Category: string(rule.Category), Tags: rule.Tags, Owner: rule.Owner,
+		}
+	}
+	if pos := pair.Pos; pos != nil {
+		msg.Pos = &KVPos{
+			KeyStart: int32(pos.KeyStart), KeyEnd: int32(pos.KeyEnd), KeyLine: int32(pos.KeyLine), KeyColumn: int32(pos.KeyColumn),
+			ValStart: int32(pos.ValStart), ValEnd: int32(pos.ValEnd), Line: int32(pos.Line), Column: int32(pos.Column),
+			EndLine: int32(pos.EndLine), EndColumn: int32(pos.EndColumn),
+		}
+	}
+	return msg, nil
+}
+
+// convert pairs to messages
+func FromKVPairs(pairs []types.KVPair) ([]*KVPair, error) {
+	msgs := make([]*KVPair, 0, len(pairs))
+	for _, pair := range pairs {
+		msg, err := FromKVPair(pair)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+// convert message to pair, numbers are decoded as json.Number
+func (x *KVPair) ToKVPair() (types.KVPair, error) {
+	pair := types.KVPair{Key: x.GetKey(), ObjectKey: x.GetObjectKey(), DetectRule: int(x.GetDetectRule())}
+	var err error
+	if pair.Val, err = decodeJSONValue(x.GetVal()); err != nil {
+		return pair, err
+	}
+	if x.GetDropped() {
+		pair.ValMasked = types.KVDropped{}
+	} else if pair.ValMasked, err = decodeJSONValue(x.GetValMasked()); err != nil {
+		return pair, err
+	}
+	if x.GetValJsonPath() == "" {
+		pair.ValJSONPath = types.NewJSONPath()
+	} else if err := json.Unmarshal([]byte(x.GetValJsonPath()), &pair.ValJSONPath); err != nil {
+		return pair, err
+	}
+	if rel := x.GetKvFieldRel(); rel != nil {
+		pair.KVFieldRel = &types.KVField{Key: rel.GetKey(), Val: rel.GetVal(), Vals: rel.GetVals()}
+	}
+	if rule := x.GetRule(); rule != nil {
+		pair.Rule = &types.KVRuleMeta{
+			ID: rule.GetId(), Name: rule.GetName(), Description: rule.GetDescription(), Severity: types.KVSeverity(rule.GetSeverity()),
+			Category: types.KVCategory(rule.GetCategory()), Tags: rule.GetTags(), Owner: rule.GetOwner(),
+		}
+	}
+	if pos := x.GetPos(); pos != nil {
+		pair.Pos = &types.KVPos{
+			KeyStart: int(pos.GetKeyStart()), KeyEnd: int(pos.GetKeyEnd()), KeyLine: int(pos.GetKeyLine()), KeyColumn: int(pos.GetKeyColumn()),
+			ValStart: int(pos.GetValStart()), ValEnd: int(pos.GetValEnd()), Line: int(pos.GetLine()), Column: int(pos.GetColumn()),
+			EndLine: int(pos.GetEndLine()), EndColumn: int(pos.GetEndColumn()),
+		}
+	}
+	return pair, nil
+}
+
+// convert messages to pairs
+func ToKVPairs(msgs []*KVPair) ([]types.KVPair, error) {
+	pairs := make([]types.KVPair, 0, len(msgs))
+	for _, msg := range msgs {
+		pair, err := msg.ToKVPair()
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, nil
+}
+
+// empty text is null
+func decodeJSONValue(text string) (interface{}, error) {
+	if text == "" {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(text)))
+	decoder.UseNumber()
+	var val interface{}
+	if err := decoder.Decode(&val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
@@ -0,0 +1,1536 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.32.0
+// 	protoc        v4.25.1
+// source: mosaic/v1/mosaic.proto
+
+package mosaicpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type KVField struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Val  string   `protobuf:"bytes,2,opt,name=val,proto3" json:"val,omitempty"`
+	Vals []string `protobuf:"bytes,3,rep,name=vals,proto3" json:"vals,omitempty"`
+}
+
+func (x *KVField) Reset() {
+	*x = KVField{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KVField) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KVField) ProtoMessage() {}
+
+func (x *KVField) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KVField.ProtoReflect.Descriptor instead.
+func (*KVField) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *KVField) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *KVField) GetVal() string {
+	if x != nil {
+		return x.Val
+	}
+	return ""
+}
+
+func (x *KVField) GetVals() []string {
+	if x != nil {
+		return x.Vals
+	}
+	return nil
+}
+
+type KVPair struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key         string      `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Val         string      `protobuf:"bytes,2,opt,name=val,proto3" json:"val,omitempty"`
+	ValJsonPath string      `protobuf:"bytes,3,opt,name=val_json_path,json=valJsonPath,proto3" json:"val_json_path,omitempty"`
+	ValMasked   string      `protobuf:"bytes,4,opt,name=val_masked,json=valMasked,proto3" json:"val_masked,omitempty"`
+	Dropped     bool        `protobuf:"varint,5,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	KvFieldRel  *KVField    `protobuf:"bytes,6,opt,name=kv_field_rel,json=kvFieldRel,proto3" json:"kv_field_rel,omitempty"`
+	ObjectKey   bool        `protobuf:"varint,7,opt,name=object_key,json=objectKey,proto3" json:"object_key,omitempty"`
+	DetectRule  int32       `protobuf:"varint,8,opt,name=detect_rule,json=detectRule,proto3" json:"detect_rule,omitempty"`
+	Pos         *KVPos      `protobuf:"bytes,9,opt,name=pos,proto3" json:"pos,omitempty"`
+	Rule        *KVRuleMeta `protobuf:"bytes,10,opt,name=rule,proto3" json:"rule,omitempty"`
+}
+
+func (x *KVPair) Reset() {
+	*x = KVPair{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KVPair) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KVPair) ProtoMessage() {}
+
+func (x *KVPair) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KVPair.ProtoReflect.Descriptor instead.
+func (*KVPair) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *KVPair) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *KVPair) GetVal() string {
+	if x != nil {
+		return x.Val
+	}
+	return ""
+}
+
+func (x *KVPair) GetValJsonPath() string {
+	if x != nil {
+		return x.ValJsonPath
+	}
+	return ""
+}
+
+func (x *KVPair) GetValMasked() string {
+	if x != nil {
+		return x.ValMasked
+	}
+	return ""
+}
+
+func (x *KVPair) GetDropped() bool {
+	if x != nil {
+		return x.Dropped
+	}
+	return false
+}
+
+func (x *KVPair) GetKvFieldRel() *KVField {
+	if x != nil {
+		return x.KvFieldRel
+	}
+	return nil
+}
+
+func (x *KVPair) GetObjectKey() bool {
+	if x != nil {
+		return x.ObjectKey
+	}
+	return false
+}
+
+func (x *KVPair) GetDetectRule() int32 {
+	if x != nil {
+		return x.DetectRule
+	}
+	return 0
+}
+
+func (x *KVPair) GetPos() *KVPos {
+	if x != nil {
+		return x.Pos
+	}
+	return nil
+}
+
+func (x *KVPair) GetRule() *KVRuleMeta {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+type KVRuleMeta struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id          string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description string   `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Severity    string   `protobuf:"bytes,4,opt,name=severity,proto3" json:"severity,omitempty"`
+	Category    string   `protobuf:"bytes,5,opt,name=category,proto3" json:"category,omitempty"`
+	Tags        []string `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
+	Owner       string   `protobuf:"bytes,7,opt,name=owner,proto3" json:"owner,omitempty"`
+}
+
+func (x *KVRuleMeta) Reset() {
+	*x = KVRuleMeta{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KVRuleMeta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KVRuleMeta) ProtoMessage() {}
+
+func (x *KVRuleMeta) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KVRuleMeta.ProtoReflect.Descriptor instead.
+func (*KVRuleMeta) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *KVRuleMeta) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *KVRuleMeta) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *KVRuleMeta) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *KVRuleMeta) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *KVRuleMeta) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *KVRuleMeta) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *KVRuleMeta) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+type KVPos struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	KeyStart  int32 `protobuf:"varint,1,opt,name=key_start,json=keyStart,proto3" json:"key_start,omitempty"`
+	KeyEnd    int32 `protobuf:"varint,2,opt,name=key_end,json=keyEnd,proto3" json:"key_end,omitempty"`
+	KeyLine   int32 `protobuf:"varint,3,opt,name=key_line,json=keyLine,proto3" json:"key_line,omitempty"`
+	KeyColumn int32 `protobuf:"varint,4,opt,name=key_column,json=keyColumn,proto3" json:"key_column,omitempty"`
+	ValStart  int32 `protobuf:"varint,5,opt,name=val_start,json=valStart,proto3" json:"val_start,omitempty"`
+	ValEnd    int32 `protobuf:"varint,6,opt,name=val_end,json=valEnd,proto3" json:"val_end,omitempty"`
+	Line      int32 `protobuf:"varint,7,opt,name=line,proto3" json:"line,omitempty"`
+	Column    int32 `protobuf:"varint,8,opt,name=column,proto3" json:"column,omitempty"`
+	EndLine   int32 `protobuf:"varint,9,opt,name=end_line,json=endLine,proto3" json:"end_line,omitempty"`
+	EndColumn int32 `protobuf:"varint,10,opt,name=end_column,json=endColumn,proto3" json:"end_column,omitempty"`
+}
+
+func (x *KVPos) Reset() {
+	*x = KVPos{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KVPos) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KVPos) ProtoMessage() {}
+
+func (x *KVPos) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KVPos.ProtoReflect.Descriptor instead.
+func (*KVPos) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *KVPos) GetKeyStart() int32 {
+	if x != nil {
+		return x.KeyStart
+	}
+	return 0
+}
+
+func (x *KVPos) GetKeyEnd() int32 {
+	if x != nil {
+		return x.KeyEnd
+	}
+	return 0
+}
+
+func (x *KVPos) GetKeyLine() int32 {
+	if x != nil {
+		return x.KeyLine
+	}
+	return 0
+}
+
+func (x *KVPos) GetKeyColumn() int32 {
+	if x != nil {
+		return x.KeyColumn
+	}
+	return 0
+}
+
+func (x *KVPos) GetValStart() int32 {
+	if x != nil {
+		return x.ValStart
+	}
+	return 0
+}
+
+func (x *KVPos) GetValEnd() int32 {
+	if x != nil {
+		return x.ValEnd
+	}
+	return 0
+}
+
+func (x *KVPos) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *KVPos) GetColumn() int32 {
+	if x != nil {
+		return x.Column
+	}
+	return 0
+}
+
+func (x *KVPos) GetEndLine() int32 {
+	if x != nil {
+		return x.EndLine
+	}
+	return 0
+}
+
+func (x *KVPos) GetEndColumn() int32 {
+	if x != nil {
+		return x.EndColumn
+	}
+	return 0
+}
+
+type DetectRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RuleSet string `protobuf:"bytes,1,opt,name=rule_set,json=ruleSet,proto3" json:"rule_set,omitempty"`
+	Input   []byte `protobuf:"bytes,2,opt,name=input,proto3" json:"input,omitempty"`
+}
+
+func (x *DetectRequest) Reset() {
+	*x = DetectRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DetectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectRequest) ProtoMessage() {}
+
+func (x *DetectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectRequest.ProtoReflect.Descriptor instead.
+func (*DetectRequest) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DetectRequest) GetRuleSet() string {
+	if x != nil {
+		return x.RuleSet
+	}
+	return ""
+}
+
+func (x *DetectRequest) GetInput() []byte {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+type DetectResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pairs []*KVPair `protobuf:"bytes,1,rep,name=pairs,proto3" json:"pairs,omitempty"`
+}
+
+func (x *DetectResponse) Reset() {
+	*x = DetectResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DetectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectResponse) ProtoMessage() {}
+
+func (x *DetectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectResponse.ProtoReflect.Descriptor instead.
+func (*DetectResponse) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DetectResponse) GetPairs() []*KVPair {
+	if x != nil {
+		return x.Pairs
+	}
+	return nil
+}
+
+type MaskRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RuleSet string `protobuf:"bytes,1,opt,name=rule_set,json=ruleSet,proto3" json:"rule_set,omitempty"`
+	Input   []byte `protobuf:"bytes,2,opt,name=input,proto3" json:"input,omitempty"`
+}
+
+func (x *MaskRequest) Reset() {
+	*x = MaskRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaskRequest) ProtoMessage() {}
+
+func (x *MaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaskRequest.ProtoReflect.Descriptor instead.
+func (*MaskRequest) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MaskRequest) GetRuleSet() string {
+	if x != nil {
+		return x.RuleSet
+	}
+	return ""
+}
+
+func (x *MaskRequest) GetInput() []byte {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+type MaskResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Output []byte    `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	Pairs  []*KVPair `protobuf:"bytes,2,rep,name=pairs,proto3" json:"pairs,omitempty"`
+}
+
+func (x *MaskResponse) Reset() {
+	*x = MaskResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaskResponse) ProtoMessage() {}
+
+func (x *MaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaskResponse.ProtoReflect.Descriptor instead.
+func (*MaskResponse) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MaskResponse) GetOutput() []byte {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *MaskResponse) GetPairs() []*KVPair {
+	if x != nil {
+		return x.Pairs
+	}
+	return nil
+}
+
+type UnmaskRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RuleSet string    `protobuf:"bytes,1,opt,name=rule_set,json=ruleSet,proto3" json:"rule_set,omitempty"`
+	Input   []byte    `protobuf:"bytes,2,opt,name=input,proto3" json:"input,omitempty"`
+	Pairs   []*KVPair `protobuf:"bytes,3,rep,name=pairs,proto3" json:"pairs,omitempty"`
+}
+
+func (x *UnmaskRequest) Reset() {
+	*x = UnmaskRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnmaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnmaskRequest) ProtoMessage() {}
+
+func (x *UnmaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnmaskRequest.ProtoReflect.Descriptor instead.
+func (*UnmaskRequest) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UnmaskRequest) GetRuleSet() string {
+	if x != nil {
+		return x.RuleSet
+	}
+	return ""
+}
+
+func (x *UnmaskRequest) GetInput() []byte {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+func (x *UnmaskRequest) GetPairs() []*KVPair {
+	if x != nil {
+		return x.Pairs
+	}
+	return nil
+}
+
+type UnmaskResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Output []byte `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+}
+
+func (x *UnmaskResponse) Reset() {
+	*x = UnmaskResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnmaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnmaskResponse) ProtoMessage() {}
+
+func (x *UnmaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnmaskResponse.ProtoReflect.Descriptor instead.
+func (*UnmaskResponse) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UnmaskResponse) GetOutput() []byte {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+type ValidateRulesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rules []byte `protobuf:"bytes,1,opt,name=rules,proto3" json:"rules,omitempty"`
+}
+
+func (x *ValidateRulesRequest) Reset() {
+	*x = ValidateRulesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateRulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateRulesRequest) ProtoMessage() {}
+
+func (x *ValidateRulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateRulesRequest.ProtoReflect.Descriptor instead.
+func (*ValidateRulesRequest) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ValidateRulesRequest) GetRules() []byte {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type ValidateRulesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Valid bool   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *ValidateRulesResponse) Reset() {
+	*x = ValidateRulesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateRulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateRulesResponse) ProtoMessage() {}
+
+func (x *ValidateRulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateRulesResponse.ProtoReflect.Descriptor instead.
+func (*ValidateRulesResponse) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ValidateRulesResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *ValidateRulesResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListRulesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListRulesRequest) Reset() {
+	*x = ListRulesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListRulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRulesRequest) ProtoMessage() {}
+
+func (x *ListRulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRulesRequest.ProtoReflect.Descriptor instead.
+func (*ListRulesRequest) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{12}
+}
+
+type RuleSet struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Rules []byte `protobuf:"bytes,2,opt,name=rules,proto3" json:"rules,omitempty"`
+}
+
+func (x *RuleSet) Reset() {
+	*x = RuleSet{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuleSet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleSet) ProtoMessage() {}
+
+func (x *RuleSet) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleSet.ProtoReflect.Descriptor instead.
+func (*RuleSet) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RuleSet) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RuleSet) GetRules() []byte {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type ListRulesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RuleSets []*RuleSet `protobuf:"bytes,1,rep,name=rule_sets,json=ruleSets,proto3" json:"rule_sets,omitempty"`
+}
+
+func (x *ListRulesResponse) Reset() {
+	*x = ListRulesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListRulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRulesResponse) ProtoMessage() {}
+
+func (x *ListRulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRulesResponse.ProtoReflect.Descriptor instead.
+func (*ListRulesResponse) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListRulesResponse) GetRuleSets() []*RuleSet {
+	if x != nil {
+		return x.RuleSets
+	}
+	return nil
+}
+
+type MaskStreamRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RuleSet string `protobuf:"bytes,1,opt,name=rule_set,json=ruleSet,proto3" json:"rule_set,omitempty"`
+	Batch   []byte `protobuf:"bytes,2,opt,name=batch,proto3" json:"batch,omitempty"`
+}
+
+func (x *MaskStreamRequest) Reset() {
+	*x = MaskStreamRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MaskStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaskStreamRequest) ProtoMessage() {}
+
+func (x *MaskStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaskStreamRequest.ProtoReflect.Descriptor instead.
+func (*MaskStreamRequest) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *MaskStreamRequest) GetRuleSet() string {
+	if x != nil {
+		return x.RuleSet
+	}
+	return ""
+}
+
+func (x *MaskStreamRequest) GetBatch() []byte {
+	if x != nil {
+		return x.Batch
+	}
+	return nil
+}
+
+type MaskStreamResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Batch  []byte    `protobuf:"bytes,1,opt,name=batch,proto3" json:"batch,omitempty"`
+	Pairs  []*KVPair `protobuf:"bytes,2,rep,name=pairs,proto3" json:"pairs,omitempty"`
+	Errors []string  `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
+}
+
+func (x *MaskStreamResponse) Reset() {
+	*x = MaskStreamResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mosaic_v1_mosaic_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MaskStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaskStreamResponse) ProtoMessage() {}
+
+func (x *MaskStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mosaic_v1_mosaic_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaskStreamResponse.ProtoReflect.Descriptor instead.
+func (*MaskStreamResponse) Descriptor() ([]byte, []int) {
+	return file_mosaic_v1_mosaic_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *MaskStreamResponse) GetBatch() []byte {
+	if x != nil {
+		return x.Batch
+	}
+	return nil
+}
+
+func (x *MaskStreamResponse) GetPairs() []*KVPair {
+	if x != nil {
+		return x.Pairs
+	}
+	return nil
+}
+
+func (x *MaskStreamResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+var File_mosaic_v1_mosaic_proto protoreflect.FileDescriptor
+
+var file_mosaic_v1_mosaic_proto_rawDesc = []byte{
+	0x0a, 0x16, 0x6d, 0x6f, 0x73, 0x61, 0x69, 0x63, 0x2f, 0x76, 0x31, 0x2f, 0x6d, 0x6f, 0x73, 0x61,
+	0x69, 0x63, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x6d, 0x6f, 0x73, 0x61, 0x69, 0x63,
+	0x2e, 0x76, 0x31, 0x22, 0x41, 0x0a, 0x07, 0x4b, 0x56, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x76, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x76,
+	0x61, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x76, 0x61, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x04, 0x76, 0x61, 0x6c, 0x73, 0x22, 0xce, 0x02, 0x0a, 0x06, 0x4b, 0x56, 0x50, 0x61, 0x69,
+	0x72, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x76, 0x61, 0x6c, 0x12, 0x22, 0x0a, 0x0d, 0x76, 0x61, 0x6c, 0x5f, 0x6a, 0x73, 0x6f,
+	0x6e, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x76, 0x61,
+	0x6c, 0x4a, 0x73, 0x6f, 0x6e, 0x50, 0x61, 0x74, 0x68, 0x12, 0x1d, 0x0a, 0x0a, 0x76, 0x61, 0x6c,
+	0x5f, 0x6d, 0x61, 0x73, 0x6b, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x76,
+	0x61, 0x6c, 0x4d, 0x61, 0x73, 0x6b, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x72, 0x6f, 0x70,
+	0x70, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70,
+	0x65, 0x64, 0x12, 0x34, 0x0a, 0x0c, 0x6b, 0x76, 0x5f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x5f, 0x72,
+	0x65, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6d, 0x6f, 0x73, 0x61, 0x69,
+	0x63, 0x2e, 0x76, 0x31, 0x2e, 0x4b, 0x56, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x52, 0x0a, 0x6b, 0x76,
+	0x46, 0x69, 0x65, 0x6c, 0x64, 0x52, 0x65, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x6f, 0x62, 0x6a, 0x65,
+	0x63, 0x74, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x6f, 0x62,
+	0x6a, 0x65, 0x63, 0x74, 0x4b, 0x65, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x65, 0x74, 0x65, 0x63,
+	0x74, 0x5f, 0x72, 0x75, 0x6c, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x64, 0x65,
+	0x74, 0x65, 0x63, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x22, 0x0a, 0x03, 0x70, 0x6f, 0x73, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6d, 0x6f, 0x73, 0x61, 0x69, 0x63, 0x2e, 0x76,
+	0x31, 0x2e, 0x4b, 0x56, 0x50, 0x6f, 0x73, 0x52, 0x03, 0x70, 0x6f, 0x73, 0x12, 0x29, 0x0a, 0x04,
+	0x72, 0x75, 0x6c, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x6d, 0x6f, 0x73,
+	0x61, 0x69, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x4b, 0x56, 0x52, 0x75, 0x6c, 0x65, 0x4d, 0x65, 0x74,
+	0x61, 0x52, 0x04, 0x72, 0x75, 0x6c, 0x65, 0x22, 0xb4, 0x01, 0x0a, 0x0a, 0x4b, 0x56, 0x52, 0x75,
+	0x6c, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65,
+	0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08,
+	0x73, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x73, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x61, 0x74, 0x65,
+	0x67, 0x6f, 0x72, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x61, 0x74, 0x65,
+	0x67, 0x6f, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x06, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65,
+	0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x22, 0x93,
+	0x02, 0x0a, 0x05, 0x4b, 0x56, 0x50, 0x6f, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x6b, 0x65, 0x79, 0x5f,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6b, 0x65, 0x79,
+	0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x6b, 0x65, 0x79, 0x5f, 0x65, 0x6e, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6b, 0x65, 0x79, 0x45, 0x6e, 0x64, 0x12, 0x19,
+	0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x07, 0x6b, 0x65, 0x79, 0x4c, 0x69, 0x6e, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6b, 0x65, 0x79,
+	0x5f, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6b,
+	0x65, 0x79, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x76, 0x61, 0x6c, 0x5f,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x76, 0x61, 0x6c,
+	0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x76, 0x61, 0x6c, 0x5f, 0x65, 0x6e, 0x64,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x45, 0x6e, 0x64, 0x12, 0x12,
+	0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x6c, 0x69,
+	0x6e, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x19, 0x0a, 0x08, 0x65, 0x6e,
+	0x64, 0x5f, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x65, 0x6e,
+	0x64, 0x4c, 0x69, 0x6e, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x6e, 0x64, 0x5f, 0x63, 0x6f, 0x6c,
+	0x75, 0x6d, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x65, 0x6e, 0x64, 0x43, 0x6f,
+	0x6c, 0x75, 0x6d, 0x6e, 0x22, 0x40, 0x0a, 0x0d, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x72, 0x75, 0x6c, 0x65, 0x5f, 0x73, 0x65,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x72, 0x75, 0x6c, 0x65, 0x53, 0x65, 0x74,
+	0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x22, 0x39, 0x0a, 0x0e, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x27, 0x0a, 0x05, 0x70, 0x61, 0x69, 0x72,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x6d, 0x6f, 0x73, 0x61, 0x69, 0x63,
+	0x2e, 0x76, 0x31, 0x2e, 0x4b, 0x56, 0x50, 0x61, 0x69, 0x72, 0x52, 0x05, 0x70, 0x61, 0x69, 0x72,
+	0x73, 0x22, 0x3e, 0x0a, 0x0b, 0x4d, 0x61, 0x73, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x19, 0x0a, 0x08, 0x72, 0x75, 0x6c, 0x65, 0x5f, 0x73, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x72, 0x75, 0x6c, 0x65, 0x53, 0x65, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x69,
+	0x6e, 0x70, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x69, 0x6e, 0x70, 0x75,
+	0x74, 0x22, 0x4f, 0x0a, 0x0c, 0x4d, 0x61, 0x73, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x27, 0x0a, 0x05, 0x70, 0x61, 0x69,
+	0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x6d, 0x6f, 0x73, 0x61, 0x69,
+	0x63, 0x2e, 0x76, 0x31, 0x2e, 0x4b, 0x56, 0x50, 0x61, 0x69, 0x72, 0x52, 0x05, 0x70, 0x61, 0x69,
+	0x72, 0x73, 0x22, 0x69, 0x0a, 0x0d, 0x55, 0x6e, 0x6d, 0x61, 0x73, 0x6b, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x72, 0x75, 0x6c, 0x65, 0x5f, 0x73, 0x65, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x72, 0x75, 0x6c, 0x65, 0x53, 0x65, 0x74, 0x12, 0x14,
+	0x0a, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x69,
+	0x6e, 0x70, 0x75, 0x74, 0x12, 0x27, 0x0a, 0x05, 0x70, 0x61, 0x69, 0x72, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x6d, 0x6f, 0x73, 0x61, 0x69, 0x63, 0x2e, 0x76, 0x31, 0x2e,
+	0x4b, 0x56, 0x50, 0x61, 0x69, 0x72, 0x52, 0x05, 0x70, 0x61, 0x69, 0x72, 0x73, 0x22, 0x28, 0x0a,
+	0x0e, 0x55, 0x6e, 0x6d, 0x61, 0x73, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x22, 0x2c, 0x0a, 0x14, 0x56, 0x61, 0x6c, 0x69, 0x64,
+	0x61, 0x74, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05,
+	0x72, 0x75, 0x6c, 0x65, 0x73, 0x22, 0x43, 0x0a, 0x15, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74,
+	0x65, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x12, 0x0a, 0x10, 0x4c, 0x69,
+	0x73, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x33,
+	0x0a, 0x07, 0x52, 0x75, 0x6c, 0x65, 0x53, 0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x72, 0x75,
+	0x6c, 0x65, 0x73, 0x22, 0x44, 0x0a, 0x11, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a, 0x09, 0x72, 0x75, 0x6c, 0x65,
+	0x5f, 0x73, 0x65, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6d, 0x6f,
+	0x73, 0x61, 0x69, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x53, 0x65, 0x74, 0x52,
+	0x08, 0x72, 0x75, 0x6c, 0x65, 0x53, 0x65, 0x74, 0x73, 0x22, 0x44, 0x0a, 0x11, 0x4d, 0x61, 0x73,
+	0x6b, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19,
+	0x0a, 0x08, 0x72, 0x75, 0x6c, 0x65, 0x5f, 0x73, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x72, 0x75, 0x6c, 0x65, 0x53, 0x65, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x61, 0x74,
+	0x63, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x62, 0x61, 0x74, 0x63, 0x68, 0x22,
+	0x6b, 0x0a, 0x12, 0x4d, 0x61, 0x73, 0x6b, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x61, 0x74, 0x63, 0x68, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x62, 0x61, 0x74, 0x63, 0x68, 0x12, 0x27, 0x0a, 0x05, 0x70,
+	0x61, 0x69, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x6d, 0x6f, 0x73,
+	0x61, 0x69, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x4b, 0x56, 0x50, 0x61, 0x69, 0x72, 0x52, 0x05, 0x70,
+	0x61, 0x69, 0x72, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x32, 0xaa, 0x03, 0x0a,
+	0x06, 0x4d, 0x6f, 0x73, 0x61, 0x69, 0x63, 0x12, 0x3d, 0x0a, 0x06, 0x44, 0x65, 0x74, 0x65, 0x63,
+	0x74, 0x12, 0x18, 0x2e, 0x6d, 0x6f, 0x73, 0x61, 0x69, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65,
+	0x74, 0x65, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x6d, 0x6f,
+	0x73, 0x61, 0x69, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x04, 0x4d, 0x61, 0x73, 0x6b, 0x12, 0x16,
+	0x2e, 0x6d, 0x6f, 0x73, 0x61, 0x69, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x61, 0x73, 0x6b, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x6d, 0x6f, 0x73, 0x61, 0x69, 0x63, 0x2e,
+	0x76, 0x31, 0x2e, 0x4d, 0x61, 0x73, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x3d, 0x0a, 0x06, 0x55, 0x6e, 0x6d, 0x61, 0x73, 0x6b, 0x12, 0x18, 0x2e, 0x6d, 0x6f, 0x73, 0x61,
+	0x69, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x6e, 0x6d, 0x61, 0x73, 0x6b, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x6d, 0x6f, 0x73, 0x61, 0x69, 0x63, 0x2e, 0x76, 0x31, 0x2e,
+	0x55, 0x6e, 0x6d, 0x61, 0x73, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x52,
+	0x0a, 0x0d, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x12,
+	0x1f, 0x2e, 0x6d, 0x6f, 0x73, 0x61, 0x69, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x69,
+	0x64, 0x61, 0x74, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x20, 0x2e, 0x6d, 0x6f, 0x73, 0x61, 0x69, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c,
+	0x69, 0x64, 0x61, 0x74, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x46, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x12,
+	0x1b, 0x2e, 0x6d, 0x6f, 0x73, 0x61, 0x69, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x52, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x6d,
+	0x6f, 0x73, 0x61, 0x69, 0x63, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x75, 0x6c,
+	0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4d, 0x0a, 0x0a, 0x4d, 0x61,
+	0x73, 0x6b, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x1c, 0x2e, 0x6d, 0x6f, 0x73, 0x61, 0x69,
+	0x63, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x61, 0x73, 0x6b, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x6d, 0x6f, 0x73, 0x61, 0x69, 0x63, 0x2e,
+	0x76, 0x31, 0x2e, 0x4d, 0x61, 0x73, 0x6b, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0x33, 0x5a, 0x31, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x65, 0x6e, 0x61, 0x79, 0x75, 0x6b, 0x69,
+	0x2f, 0x6d, 0x6f, 0x73, 0x61, 0x69, 0x63, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x6d, 0x6f, 0x73,
+	0x61, 0x69, 0x63, 0x70, 0x62, 0x3b, 0x6d, 0x6f, 0x73, 0x61, 0x69, 0x63, 0x70, 0x62, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_mosaic_v1_mosaic_proto_rawDescOnce sync.Once
+	file_mosaic_v1_mosaic_proto_rawDescData = file_mosaic_v1_mosaic_proto_rawDesc
+)
+
+func file_mosaic_v1_mosaic_proto_rawDescGZIP() []byte {
+	file_mosaic_v1_mosaic_proto_rawDescOnce.Do(func() {
+		file_mosaic_v1_mosaic_proto_rawDescData = protoimpl.X.CompressGZIP(file_mosaic_v1_mosaic_proto_rawDescData)
+	})
+	return file_mosaic_v1_mosaic_proto_rawDescData
+}
+
+var file_mosaic_v1_mosaic_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_mosaic_v1_mosaic_proto_goTypes = []interface{}{
+	(*KVField)(nil),               // 0: mosaic.v1.KVField
+	(*KVPair)(nil),                // 1: mosaic.v1.KVPair
+	(*KVRuleMeta)(nil),            // 2: mosaic.v1.KVRuleMeta
+	(*KVPos)(nil),                 // 3: mosaic.v1.KVPos
+	(*DetectRequest)(nil),         // 4: mosaic.v1.DetectRequest
+	(*DetectResponse)(nil),        // 5: mosaic.v1.DetectResponse
+	(*MaskRequest)(nil),           // 6: mosaic.v1.MaskRequest
+	(*MaskResponse)(nil),          // 7: mosaic.v1.MaskResponse
+	(*UnmaskRequest)(nil),         // 8: mosaic.v1.UnmaskRequest
+	(*UnmaskResponse)(nil),        // 9: mosaic.v1.UnmaskResponse
+	(*ValidateRulesRequest)(nil),  // 10: mosaic.v1.ValidateRulesRequest
+	(*ValidateRulesResponse)(nil), // 11: mosaic.v1.ValidateRulesResponse
+	(*ListRulesRequest)(nil),      // 12: mosaic.v1.ListRulesRequest
+	(*RuleSet)(nil),               // 13: mosaic.v1.RuleSet
+	(*ListRulesResponse)(nil),     // 14: mosaic.v1.ListRulesResponse
+	(*MaskStreamRequest)(nil),     // 15: mosaic.v1.MaskStreamRequest
+	(*MaskStreamResponse)(nil),    // 16: mosaic.v1.MaskStreamResponse
+}
+var file_mosaic_v1_mosaic_proto_depIdxs = []int32{
+	0,  // 0: mosaic.v1.KVPair.kv_field_rel:type_name -> mosaic.v1.KVField
+	3,  // 1: mosaic.v1.KVPair.pos:type_name -> mosaic.v1.KVPos
+	2,  // 2: mosaic.v1.KVPair.rule:type_name -> mosaic.v1.KVRuleMeta
+	1,  // 3: mosaic.v1.DetectResponse.pairs:type_name -> mosaic.v1.KVPair
+	1,  // 4: mosaic.v1.MaskResponse.pairs:type_name -> mosaic.v1.KVPair
+	1,  // 5: mosaic.v1.UnmaskRequest.pairs:type_name -> mosaic.v1.KVPair
+	13, // 6: mosaic.v1.ListRulesResponse.rule_sets:type_name -> mosaic.v1.RuleSet
+	1,  // 7: mosaic.v1.MaskStreamResponse.pairs:type_name -> mosaic.v1.KVPair
+	4,  // 8: mosaic.v1.Mosaic.Detect:input_type -> mosaic.v1.DetectRequest
+	6,  // 9: mosaic.v1.Mosaic.Mask:input_type -> mosaic.v1.MaskRequest
+	8,  // 10: mosaic.v1.Mosaic.Unmask:input_type -> mosaic.v1.UnmaskRequest
+	10, // 11: mosaic.v1.Mosaic.ValidateRules:input_type -> mosaic.v1.ValidateRulesRequest
+	12, // 12: mosaic.v1.Mosaic.ListRules:input_type -> mosaic.v1.ListRulesRequest
+	15, // 13: mosaic.v1.Mosaic.MaskStream:input_type -> mosaic.v1.MaskStreamRequest
+	5,  // 14: mosaic.v1.Mosaic.Detect:output_type -> mosaic.v1.DetectResponse
+	7,  // 15: mosaic.v1.Mosaic.Mask:output_type -> mosaic.v1.MaskResponse
+	9,  // 16: mosaic.v1.Mosaic.Unmask:output_type -> mosaic.v1.UnmaskResponse
+	11, // 17: mosaic.v1.Mosaic.ValidateRules:output_type -> mosaic.v1.ValidateRulesResponse
+	14, // 18: mosaic.v1.Mosaic.ListRules:output_type -> mosaic.v1.ListRulesResponse
+	16, // 19: mosaic.v1.Mosaic.MaskStream:output_type -> mosaic.v1.MaskStreamResponse
+	14, // [14:20] is the sub-list for method output_type
+	8,  // [8:14] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_mosaic_v1_mosaic_proto_init() }
+func file_mosaic_v1_mosaic_proto_init() {
+	if File_mosaic_v1_mosaic_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_mosaic_v1_mosaic_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KVField); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mosaic_v1_mosaic_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KVPair); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mosaic_v1_mosaic_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KVRuleMeta); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mosaic_v1_mosaic_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KVPos); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mosaic_v1_mosaic_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DetectRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mosaic_v1_mosaic_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DetectResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mosaic_v1_mosaic_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MaskRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mosaic_v1_mosaic_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MaskResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mosaic_v1_mosaic_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UnmaskRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mosaic_v1_mosaic_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UnmaskResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mosaic_v1_mosaic_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateRulesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mosaic_v1_mosaic_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateRulesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mosaic_v1_mosaic_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListRulesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mosaic_v1_mosaic_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleSet); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mosaic_v1_mosaic_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListRulesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mosaic_v1_mosaic_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MaskStreamRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mosaic_v1_mosaic_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MaskStreamResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_mosaic_v1_mosaic_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_mosaic_v1_mosaic_proto_goTypes,
+		DependencyIndexes: file_mosaic_v1_mosaic_proto_depIdxs,
+		MessageInfos:      file_mosaic_v1_mosaic_proto_msgTypes,
+	}.Build()
+	File_mosaic_v1_mosaic_proto = out.File
+	file_mosaic_v1_mosaic_proto_rawDesc = nil
+	file_mosaic_v1_mosaic_proto_goTypes = nil
+	file_mosaic_v1_mosaic_proto_depIdxs = nil
+}
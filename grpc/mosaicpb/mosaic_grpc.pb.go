@@ -0,0 +1,327 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.1
+// source: mosaic/v1/mosaic.proto
+
+package mosaicpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Mosaic_Detect_FullMethodName        = "/mosaic.v1.Mosaic/Detect"
+	Mosaic_Mask_FullMethodName          = "/mosaic.v1.Mosaic/Mask"
+	Mosaic_Unmask_FullMethodName        = "/mosaic.v1.Mosaic/Unmask"
+	Mosaic_ValidateRules_FullMethodName = "/mosaic.v1.Mosaic/ValidateRules"
+	Mosaic_ListRules_FullMethodName     = "/mosaic.v1.Mosaic/ListRules"
+	Mosaic_MaskStream_FullMethodName    = "/mosaic.v1.Mosaic/MaskStream"
+)
+
+// MosaicClient is the client API for Mosaic service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MosaicClient interface {
+	Detect(ctx context.Context, in *DetectRequest, opts ...grpc.CallOption) (*DetectResponse, error)
+	Mask(ctx context.Context, in *MaskRequest, opts ...grpc.CallOption) (*MaskResponse, error)
+	Unmask(ctx context.Context, in *UnmaskRequest, opts ...grpc.CallOption) (*UnmaskResponse, error)
+	ValidateRules(ctx context.Context, in *ValidateRulesRequest, opts ...grpc.CallOption) (*ValidateRulesResponse, error)
+	ListRules(ctx context.Context, in *ListRulesRequest, opts ...grpc.CallOption) (*ListRulesResponse, error)
+	MaskStream(ctx context.Context, opts ...grpc.CallOption) (Mosaic_MaskStreamClient, error)
+}
+
+type mosaicClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMosaicClient(cc grpc.ClientConnInterface) MosaicClient {
+	return &mosaicClient{cc}
+}
+
+func (c *mosaicClient) Detect(ctx context.Context, in *DetectRequest, opts ...grpc.CallOption) (*DetectResponse, error) {
+	out := new(DetectResponse)
+	err := c.cc.Invoke(ctx, Mosaic_Detect_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mosaicClient) Mask(ctx context.Context, in *MaskRequest, opts ...grpc.CallOption) (*MaskResponse, error) {
+	out := new(MaskResponse)
+	err := c.cc.Invoke(ctx, Mosaic_Mask_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mosaicClient) Unmask(ctx context.Context, in *UnmaskRequest, opts ...grpc.CallOption) (*UnmaskResponse, error) {
+	out := new(UnmaskResponse)
+	err := c.cc.Invoke(ctx, Mosaic_Unmask_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mosaicClient) ValidateRules(ctx context.Context, in *ValidateRulesRequest, opts ...grpc.CallOption) (*ValidateRulesResponse, error) {
+	out := new(ValidateRulesResponse)
+	err := c.cc.Invoke(ctx, Mosaic_ValidateRules_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mosaicClient) ListRules(ctx context.Context, in *ListRulesRequest, opts ...grpc.CallOption) (*ListRulesResponse, error) {
+	out := new(ListRulesResponse)
+	err := c.cc.Invoke(ctx, Mosaic_ListRules_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mosaicClient) MaskStream(ctx context.Context, opts ...grpc.CallOption) (Mosaic_MaskStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Mosaic_ServiceDesc.Streams[0], Mosaic_MaskStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &mosaicMaskStreamClient{stream}
+	return x, nil
+}
+
+type Mosaic_MaskStreamClient interface {
+	Send(*MaskStreamRequest) error
+	Recv() (*MaskStreamResponse, error)
+	grpc.ClientStream
+}
+
+type mosaicMaskStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *mosaicMaskStreamClient) Send(m *MaskStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *mosaicMaskStreamClient) Recv() (*MaskStreamResponse, error) {
+	m := new(MaskStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MosaicServer is the server API for Mosaic service.
+// All implementations must embed UnimplementedMosaicServer
+// for forward compatibility
+type MosaicServer interface {
+	Detect(context.Context, *DetectRequest) (*DetectResponse, error)
+	Mask(context.Context, *MaskRequest) (*MaskResponse, error)
+	Unmask(context.Context, *UnmaskRequest) (*UnmaskResponse, error)
+	ValidateRules(context.Context, *ValidateRulesRequest) (*ValidateRulesResponse, error)
+	ListRules(context.Context, *ListRulesRequest) (*ListRulesResponse, error)
+	MaskStream(Mosaic_MaskStreamServer) error
+	mustEmbedUnimplementedMosaicServer()
+}
+
+// UnimplementedMosaicServer must be embedded to have forward compatible implementations.
+type UnimplementedMosaicServer struct {
+}
+
+func (UnimplementedMosaicServer) Detect(context.Context, *DetectRequest) (*DetectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Detect not implemented")
+}
+func (UnimplementedMosaicServer) Mask(context.Context, *MaskRequest) (*MaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Mask not implemented")
+}
+func (UnimplementedMosaicServer) Unmask(context.Context, *UnmaskRequest) (*UnmaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unmask not implemented")
+}
+func (UnimplementedMosaicServer) ValidateRules(context.Context, *ValidateRulesRequest) (*ValidateRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateRules not implemented")
+}
+func (UnimplementedMosaicServer) ListRules(context.Context, *ListRulesRequest) (*ListRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRules not implemented")
+}
+func (UnimplementedMosaicServer) MaskStream(Mosaic_MaskStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method MaskStream not implemented")
+}
+func (UnimplementedMosaicServer) mustEmbedUnimplementedMosaicServer() {}
+
+// UnsafeMosaicServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MosaicServer will
+// result in compilation errors.
+type UnsafeMosaicServer interface {
+	mustEmbedUnimplementedMosaicServer()
+}
+
+func RegisterMosaicServer(s grpc.ServiceRegistrar, srv MosaicServer) {
+	s.RegisterService(&Mosaic_ServiceDesc, srv)
+}
+
+func _Mosaic_Detect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DetectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MosaicServer).Detect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Mosaic_Detect_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MosaicServer).Detect(ctx, req.(*DetectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Mosaic_Mask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MosaicServer).Mask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Mosaic_Mask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MosaicServer).Mask(ctx, req.(*MaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Mosaic_Unmask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnmaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MosaicServer).Unmask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Mosaic_Unmask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MosaicServer).Unmask(ctx, req.(*UnmaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Mosaic_ValidateRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MosaicServer).ValidateRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Mosaic_ValidateRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MosaicServer).ValidateRules(ctx, req.(*ValidateRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Mosaic_ListRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MosaicServer).ListRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Mosaic_ListRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MosaicServer).ListRules(ctx, req.(*ListRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Mosaic_MaskStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MosaicServer).MaskStream(&mosaicMaskStreamServer{stream})
+}
+
+type Mosaic_MaskStreamServer interface {
+	Send(*MaskStreamResponse) error
+	Recv() (*MaskStreamRequest, error)
+	grpc.ServerStream
+}
+
+type mosaicMaskStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *mosaicMaskStreamServer) Send(m *MaskStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *mosaicMaskStreamServer) Recv() (*MaskStreamRequest, error) {
+	m := new(MaskStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Mosaic_ServiceDesc is the grpc.ServiceDesc for Mosaic service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Mosaic_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mosaic.v1.Mosaic",
+	HandlerType: (*MosaicServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Detect",
+			Handler:    _Mosaic_Detect_Handler,
+		},
+		{
+			MethodName: "Mask",
+			Handler:    _Mosaic_Mask_Handler,
+		},
+		{
+			MethodName: "Unmask",
+			Handler:    _Mosaic_Unmask_Handler,
+		},
+		{
+			MethodName: "ValidateRules",
+			Handler:    _Mosaic_ValidateRules_Handler,
+		},
+		{
+			MethodName: "ListRules",
+			Handler:    _Mosaic_ListRules_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "MaskStream",
+			Handler:       _Mosaic_MaskStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "mosaic/v1/mosaic.proto",
+}
@@ -0,0 +1,204 @@
+// gRPC server of mosaic.v1.Mosaic backed by KVProcesser
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/senayuki/mosaic/grpc/mosaicpb"
+	"github.com/senayuki/mosaic/pkg/dict"
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ruleSet struct {
+	rules types.KVRules
+	m     processer.KVProcesser
+}
+
+// rule sets are selected by name of request, "" is the default rule set
+type Server struct {
+	mosaicpb.UnimplementedMosaicServer
+	mu       sync.RWMutex
+	ruleSets map[string]ruleSet
+}
+
+func New() *Server {
+	return &Server{ruleSets: map[string]ruleSet{}}
+}
+
+// compile and register rule set, replaces rule set with same name
+func (s *Server) SetRuleSet(name string, rules types.KVRules) error {
+	m, err := processer.CompileKVProcesser(rules)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ruleSets[name] = ruleSet{rules: rules, m: m}
+	return nil
+}
+
+func (s *Server) DeleteRuleSet(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.ruleSets, name)
+}
+
+func (s *Server) processer(name string) (processer.KVProcesser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	set, ok := s.ruleSets[name]
+	if !ok {
+		return processer.KVProcesser{}, status.Errorf(codes.NotFound, "rule set %q not found", name)
+	}
+	return set.m, nil
+}
+
+func (s *Server) Detect(ctx context.Context, req *mosaicpb.DetectRequest) (*mosaicpb.DetectResponse, error) {
+	m, err := s.processer(req.GetRuleSet())
+	if err != nil {
+		return nil, err
+	}
+	pairs, err := m.Detect(req.GetInput())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	msgs, err := mosaicpb.FromKVPairs(pairs)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &mosaicpb.DetectResponse{Pairs: msgs}, nil
+}
+
+func (s *Server) Mask(ctx context.Context, req *mosaicpb.MaskRequest) (*mosaicpb.MaskResponse, error) {
+	m, err := s.processer(req.GetRuleSet())
+	if err != nil {
+		return nil, err
+	}
+	output, msgs, err := maskDocument(ctx, m, req.GetInput())
+	if err != nil {
+		return nil, err
+	}
+	return &mosaicpb.MaskResponse{Output: output, Pairs: msgs}, nil
+}
+
+func (s *Server) Unmask(ctx context.Context, req *mosaicpb.UnmaskRequest) (*mosaicpb.UnmaskResponse, error) {
+	m, err := s.processer(req.GetRuleSet())
+	if err != nil {
+		return nil, err
+	}
+	pairs, err := mosaicpb.ToKVPairs(req.GetPairs())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "pairs: %v", err)
+	}
+	output, err := m.Unmask(ctx, req.GetInput(), pairs)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &mosaicpb.UnmaskResponse{Output: output}, nil
+}
+
+func (s *Server) ValidateRules(ctx context.Context, req *mosaicpb.ValidateRulesRequest) (*mosaicpb.ValidateRulesResponse, error) {
+	var rules types.KVRules
+	if err := json.Unmarshal(req.GetRules(), &rules); err != nil {
+		return &mosaicpb.ValidateRulesResponse{Error: err.Error()}, nil
+	}
+	// dictionaries are checked without words, files named by request are never opened
+	dicts := make(map[string]*dict.Dictionary, len(rules.Dictionaries))
+	for name, config := range rules.Dictionaries {
+		d, err := dict.New(nil, config)
+		if err != nil {
+			return &mosaicpb.ValidateRulesResponse{Error: fmt.Sprintf("Dictionaries: dictionary %q: %v", name, err)}, nil
+		}
+		dicts[name] = d
+	}
+	if _, err := processer.CompileKVProcesserWith(rules, dicts); err != nil {
+		return &mosaicpb.ValidateRulesResponse{Error: err.Error()}, nil
+	}
+	return &mosaicpb.ValidateRulesResponse{Valid: true}, nil
+}
+
+func (s *Server) ListRules(ctx context.Context, req *mosaicpb.ListRulesRequest) (*mosaicpb.ListRulesResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	resp := &mosaicpb.ListRulesResponse{RuleSets: make([]*mosaicpb.RuleSet, 0, len(s.ruleSets))}
+	for name, set := range s.ruleSets {
+		rules, err := json.Marshal(set.rules)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		resp.RuleSets = append(resp.RuleSets, &mosaicpb.RuleSet{Name: name, Rules: rules})
+	}
+	sort.Slice(resp.RuleSets, func(i, j int) bool {
+		return resp.RuleSets[i].Name < resp.RuleSets[j].Name
+	})
+	return resp, nil
+}
+
+// mask NDJSON batches, a failed line is left empty and reported in errors
+func (s *Server) MaskStream(stream mosaicpb.Mosaic_MaskStreamServer) error {
+	var ruleSetName string
+	var first = true
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if first || req.GetRuleSet() != "" {
+			ruleSetName = req.GetRuleSet()
+			first = false
+		}
+		m, err := s.processer(ruleSetName)
+		if err != nil {
+			return err
+		}
+		resp := &mosaicpb.MaskStreamResponse{}
+		if len(req.GetBatch()) == 0 {
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+			continue
+		}
+		var out bytes.Buffer
+		for lineNo, line := range bytes.Split(bytes.TrimSuffix(req.GetBatch(), []byte("\n")), []byte("\n")) {
+			line = bytes.TrimSuffix(line, []byte("\r"))
+			if len(bytes.TrimSpace(line)) > 0 {
+				output, msgs, err := maskDocument(stream.Context(), m, line)
+				if err != nil {
+					resp.Errors = append(resp.Errors, fmt.Sprintf("line %d: %v", lineNo+1, status.Convert(err).Message()))
+				} else {
+					out.Write(output)
+					resp.Pairs = append(resp.Pairs, msgs...)
+				}
+			}
+			out.WriteByte('\n')
+		}
+		resp.Batch = out.Bytes()
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func maskDocument(ctx context.Context, m processer.KVProcesser, input []byte) ([]byte, []*mosaicpb.KVPair, error) {
+	output, pairs, err := m.Mask(ctx, input)
+	if err != nil {
+		return nil, nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	msgs, err := mosaicpb.FromKVPairs(pairs)
+	if err != nil {
+		return nil, nil, status.Error(codes.Internal, err.Error())
+	}
+	return output, msgs, nil
+}
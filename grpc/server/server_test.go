@@ -0,0 +1,208 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/senayuki/mosaic/grpc/client"
+	"github.com/senayuki/mosaic/grpc/mosaicpb"
+	"github.com/senayuki/mosaic/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var passwordRule = types.KVRules{
+	DetectRules: []types.KVDetectConfig{
+		{KeyEqs: []string{"password"}},
+	},
+}
+
+var phoneRule = types.KVRules{
+	DetectRules: []types.KVDetectConfig{
+		{KeyEqs: []string{"phone"}, MaskRef: "drop"},
+	},
+	MaskRules: []types.KVMaskConfig{
+		{RuleName: "drop", MaskType: types.MaskTypeDrop},
+	},
+}
+
+func newTestClient(t *testing.T) *client.Client {
+	s := New()
+	if err := s.SetRuleSet("", passwordRule); err != nil {
+		t.Fatalf("SetRuleSet() error = %v", err)
+	}
+	if err := s.SetRuleSet("phone", phoneRule); err != nil {
+		t.Fatalf("SetRuleSet() error = %v", err)
+	}
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	mosaicpb.RegisterMosaicServer(grpcServer, s)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("DialContext() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return client.New(conn)
+}
+
+func TestServer_MaskUnmask(t *testing.T) {
+	tests := []struct {
+		name       string
+		ruleSet    string
+		input      string
+		wantOutput string
+		wantPaths  []string
+		// dropped members are restored at the end of object
+		wantRestored string
+		wantCode     codes.Code
+	}{
+		{
+			name:         "default rule set",
+			input:        `{"password":"abc","user":{"password":12345}}`,
+			wantOutput:   `{"password":"***","user":{"password":"*****"}}`,
+			wantPaths:    []string{"password", "user->password"},
+			wantRestored: `{"password":"abc","user":{"password":12345}}`,
+		},
+		{
+			name:         "named rule set",
+			ruleSet:      "phone",
+			input:        `{"phone":"123","password":"abc"}`,
+			wantOutput:   `{"password":"abc"}`,
+			wantPaths:    []string{"phone"},
+			wantRestored: `{"password":"abc","phone":"123"}`,
+		},
+		{
+			name:     "unknown rule set",
+			ruleSet:  "unknown",
+			input:    `{}`,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "invalid input",
+			input:    `{`,
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	c := newTestClient(t)
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, pairs, err := c.Mask(ctx, tt.ruleSet, []byte(tt.input))
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Mask() error = %v, want code %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			detected, err := c.Detect(ctx, tt.ruleSet, []byte(tt.input))
+			if err != nil {
+				t.Fatalf("Detect() error = %v", err)
+			}
+			if len(detected) != len(tt.wantPaths) || len(pairs) != len(tt.wantPaths) {
+				t.Fatalf("Detect() got %d pairs, Mask() got %d pairs, want %d", len(detected), len(pairs), len(tt.wantPaths))
+			}
+			for idx, pair := range pairs {
+				if pair.ValJSONPath.String() != tt.wantPaths[idx] {
+					t.Errorf("Mask() pair %d path = %v, want %v", idx, pair.ValJSONPath, tt.wantPaths[idx])
+				}
+			}
+			if string(output) != tt.wantOutput {
+				t.Errorf("Mask() output = %v, want %v", string(output), tt.wantOutput)
+			}
+			restored, err := c.Unmask(ctx, tt.ruleSet, output, pairs)
+			if err != nil {
+				t.Fatalf("Unmask() error = %v", err)
+			}
+			if string(restored) != tt.wantRestored {
+				t.Errorf("Unmask() = %v, want %v", string(restored), tt.wantRestored)
+			}
+		})
+	}
+}
+
+func TestServer_Rules(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+	ruleSets, err := c.ListRules(ctx)
+	if err != nil {
+		t.Fatalf("ListRules() error = %v", err)
+	}
+	if len(ruleSets) != 2 || len(ruleSets["phone"].MaskRules) != 1 || ruleSets[""].DetectRules[0].KeyEqs[0] != "password" {
+		t.Errorf("ListRules() = %+v", ruleSets)
+	}
+	if err := c.ValidateRules(ctx, phoneRule); err != nil {
+		t.Errorf("ValidateRules() error = %v", err)
+	}
+	invalid := types.KVRules{DetectRules: []types.KVDetectConfig{{MaskRef: "unknown"}}}
+	if err := c.ValidateRules(ctx, invalid); err == nil {
+		t.Errorf("ValidateRules() error = nil, want error")
+	}
+	// files of dictionaries are not opened
+	dictRule := types.KVRules{
+		DetectRules:  []types.KVDetectConfig{{ValDictionary: "names"}},
+		Dictionaries: map[string]types.KVDictionary{"names": {File: "/nonexistent/names.txt"}},
+	}
+	if err := c.ValidateRules(ctx, dictRule); err != nil {
+		t.Errorf("ValidateRules() dictionary error = %v", err)
+	}
+	dictRule.Dictionaries["names"] = types.KVDictionary{File: "/nonexistent/names.txt", Match: "prefix"}
+	if err := c.ValidateRules(ctx, dictRule); err == nil {
+		t.Errorf("ValidateRules() unknown match error = nil, want error")
+	}
+}
+
+func TestServer_MaskStream(t *testing.T) {
+	c := newTestClient(t)
+	stream, err := c.MaskStream(context.Background(), "")
+	if err != nil {
+		t.Fatalf("MaskStream() error = %v", err)
+	}
+	batches := []struct {
+		batch      string
+		wantBatch  string
+		wantPairs  int
+		wantErrors int
+	}{
+		{
+			batch:     "{\"password\":\"abc\"}\n{\"password\":\"de\"}\n",
+			wantBatch: "{\"password\":\"***\"}\n{\"password\":\"**\"}\n",
+			wantPairs: 2,
+		},
+		{
+			batch:      "{\"password\":\"abc\"}\n{\n\n{\"name\":\"a\"}",
+			wantBatch:  "{\"password\":\"***\"}\n\n\n{\"name\":\"a\"}\n",
+			wantPairs:  1,
+			wantErrors: 1,
+		},
+	}
+	for idx, b := range batches {
+		if err := stream.Send([]byte(b.batch)); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		got, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		if string(got.Batch) != b.wantBatch || len(got.Pairs) != b.wantPairs || len(got.Errors) != b.wantErrors {
+			t.Errorf("batch %d = %q, %d pairs, errors %v, want %q, %d pairs, %d errors", idx, got.Batch, len(got.Pairs), got.Errors, b.wantBatch, b.wantPairs, b.wantErrors)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("CloseSend() error = %v", err)
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("Recv() error = %v, want EOF", err)
+	}
+}
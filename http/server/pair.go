@@ -0,0 +1,114 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+// pair in JSON, values are JSON values
+type Pair struct {
+	Key        string          `json:"key"`
+	Path       types.JSONPath  `json:"path"`
+	Val        json.RawMessage `json:"val,omitempty"`
+	ValMasked  json.RawMessage `json:"valMasked,omitempty"`
+	Dropped    bool            `json:"dropped,omitempty"`
+	ObjectKey  bool            `json:"objectKey,omitempty"`
+	KVFieldRel *types.KVField  `json:"kvFieldRel,omitempty"`
+	DetectRule int             `json:"detectRule"`
+	Pos        *Pos            `json:"pos,omitempty"`
+	Rule       *RuleMeta       `json:"rule,omitempty"`
+}
+
+// metadata of matched rule, see types.KVRuleMeta
+type RuleMeta struct {
+	ID          string   `json:"id,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Severity    string   `json:"severity,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Owner       string   `json:"owner,omitempty"`
+}
+
+// position of pair in input, see types.KVPos
+type Pos struct {
+	KeyStart  int `json:"keyStart"`
+	KeyEnd    int `json:"keyEnd"`
+	KeyLine   int `json:"keyLine,omitempty"`
+	KeyColumn int `json:"keyColumn,omitempty"`
+	ValStart  int `json:"valStart"`
+	ValEnd    int `json:"valEnd"`
+	Line      int `json:"line"`
+	Column    int `json:"column"`
+	EndLine   int `json:"endLine"`
+	EndColumn int `json:"endColumn"`
+}
+
+func fromKVPairs(pairs []types.KVPair) ([]Pair, error) {
+	out := make([]Pair, 0, len(pairs))
+	for _, pair := range pairs {
+		p := Pair{Key: pair.Key, Path: pair.ValJSONPath, ObjectKey: pair.ObjectKey, KVFieldRel: pair.KVFieldRel, DetectRule: pair.DetectRule}
+		var err error
+		if p.Val, err = json.Marshal(pair.Val); err != nil {
+			return nil, err
+		}
+		if _, dropped := pair.ValMasked.(types.KVDropped); dropped {
+			p.Dropped = true
+		} else if p.ValMasked, err = json.Marshal(pair.ValMasked); err != nil {
+			return nil, err
+		}
+		if rule := pair.Rule; rule != nil {
+			p.Rule = &RuleMeta{
+				ID: rule.ID, Name: rule.Name, Description: rule.Description, Severity: string(rule.Severity),
+				Category: string(rule.Category), Tags: rule.Tags, Owner: rule.Owner,
+			}
+		}
+		if pos := pair.Pos; pos != nil {
+			p.Pos = &Pos{
+				KeyStart: pos.KeyStart, KeyEnd: pos.KeyEnd, KeyLine: pos.KeyLine, KeyColumn: pos.KeyColumn,
+				ValStart: pos.ValStart, ValEnd: pos.ValEnd, Line: pos.Line, Column: pos.Column,
+				EndLine: pos.EndLine, EndColumn: pos.EndColumn,
+			}
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// pairs to unmask, positions & rules are not needed
+func toKVPairs(pairs []Pair) ([]types.KVPair, error) {
+	out := make([]types.KVPair, 0, len(pairs))
+	for _, p := range pairs {
+		pair := types.KVPair{Key: p.Key, ValJSONPath: p.Path, ObjectKey: p.ObjectKey, KVFieldRel: p.KVFieldRel, DetectRule: p.DetectRule}
+		if pair.ValJSONPath.Elements() == nil {
+			pair.ValJSONPath = types.NewJSONPath()
+		}
+		var err error
+		if pair.Val, err = decodeJSONValue(p.Val); err != nil {
+			return nil, err
+		}
+		if p.Dropped {
+			pair.ValMasked = types.KVDropped{}
+		} else if pair.ValMasked, err = decodeJSONValue(p.ValMasked); err != nil {
+			return nil, err
+		}
+		out = append(out, pair)
+	}
+	return out, nil
+}
+
+// numbers are decoded as json.Number, empty value is null
+func decodeJSONValue(data json.RawMessage) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var val interface{}
+	if err := decoder.Decode(&val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
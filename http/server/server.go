@@ -0,0 +1,495 @@
+/*
+HTTP/JSON server of detect, mask & unmask backed by KVProcesser, alongside the gRPC server
+
+	POST /v1/detect  {"ruleSet": "...", "input": {...}}              -> {"pairs": [...]}
+	POST /v1/mask    {"ruleSet": "...", "input": {...}}              -> {"output": {...}, "pairs": [...]}
+	POST /v1/unmask  {"ruleSet": "...", "input": {...}, "pairs": [...]} -> {"output": {...}}
+
+rule set is DefaultRuleSet if omitted, rule sets are managed by admin endpoints:
+
+	GET    /v1/admin/rulesets         list rule sets in use
+	GET    /v1/admin/rulesets/{name}  rules of rule set
+	PUT    /v1/admin/rulesets/{name}  create or update rule set, JSON or YAML by Content-Type
+	DELETE /v1/admin/rulesets/{name}  delete rule set
+	POST   /v1/admin/validate         validate rules without saving them
+	POST   /v1/admin/reload           reload rule sets from store
+
+GET /healthz reports the process is serving, GET /readyz reports rule sets are loaded
+errors are responded as {"error": "..."}
+*/
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/senayuki/mosaic/pkg/dict"
+	"github.com/senayuki/mosaic/pkg/rulefile"
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+)
+
+const (
+	DefaultRuleSet     = "default"
+	DefaultMaxBodySize = 1 << 20
+)
+
+type Config struct {
+	// rule sets loaded by Load and written by admin endpoints, nil keeps rule sets in memory only
+	Store       Store
+	MaxBodySize int64 // limit of request body in bytes, default DefaultMaxBodySize
+	/*bearer token of admin endpoints
+	empty token leaves admin endpoints open, e.g. if they are protected by network or a proxy
+	*/
+	AdminToken string
+	/*files of dictionaries referenced by rule sets, e.g. os.DirFS of a directory,
+	KVDictionary.File is a path in it, so rule sets from network can not read other files;
+	rule sets with dictionaries are refused if nil
+	*/
+	Dictionaries fs.FS
+}
+
+type ruleSet struct {
+	rules types.KVRules
+	m     processer.KVProcesser
+}
+
+/*
+rule sets are swapped as a whole: requests in flight keep the processer they started with,
+and requests after update or reload use the new one
+*/
+type Server struct {
+	config   Config
+	mux      *http.ServeMux
+	mu       sync.RWMutex
+	ruleSets map[string]ruleSet
+	ready    int32 // 1 if rule sets of Store are loaded, atomic
+}
+
+// create server, rule sets of Store are not loaded until Load, server without Store is ready
+func New(config Config) *Server {
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = DefaultMaxBodySize
+	}
+	s := &Server{config: config, ruleSets: map[string]ruleSet{}}
+	if config.Store == nil {
+		s.ready = 1
+	}
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("/healthz", s.healthz)
+	s.mux.HandleFunc("/readyz", s.readyz)
+	s.mux.HandleFunc("/v1/detect", s.handle(s.post(s.detect)))
+	s.mux.HandleFunc("/v1/mask", s.handle(s.post(s.mask)))
+	s.mux.HandleFunc("/v1/unmask", s.handle(s.post(s.unmask)))
+	s.mux.HandleFunc("/v1/admin/rulesets", s.admin(s.listRuleSets))
+	s.mux.HandleFunc("/v1/admin/rulesets/", s.admin(s.ruleSet))
+	s.mux.HandleFunc("/v1/admin/validate", s.admin(s.post(s.validate)))
+	s.mux.HandleFunc("/v1/admin/reload", s.admin(s.post(s.reload)))
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+/*
+load all rule sets of store and replace rule sets in use, server is ready after the first load
+rule sets are replaced only if all of them are valid
+*/
+func (s *Server) Load(ctx context.Context) error {
+	if s.config.Store == nil {
+		return nil
+	}
+	names, err := s.config.Store.List(ctx)
+	if err != nil {
+		return err
+	}
+	ruleSets := make(map[string]ruleSet, len(names))
+	for _, name := range names {
+		rules, err := s.config.Store.Get(ctx, name)
+		if err != nil {
+			return fmt.Errorf("rule set %q: %w", name, err)
+		}
+		m, err := s.compile(rules)
+		if err != nil {
+			return fmt.Errorf("rule set %q: %w", name, err)
+		}
+		ruleSets[name] = ruleSet{rules: rules, m: m}
+	}
+	s.mu.Lock()
+	s.ruleSets = ruleSets
+	s.mu.Unlock()
+	atomic.StoreInt32(&s.ready, 1)
+	return nil
+}
+
+// compile, save to store & swap rule set, rule set in use is kept if any step failed
+func (s *Server) SetRuleSet(ctx context.Context, name string, rules types.KVRules) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+	m, err := s.compile(rules)
+	if err != nil {
+		return err
+	}
+	return s.setRuleSet(ctx, name, rules, m)
+}
+
+// compile rules with dictionaries of Config.Dictionaries only
+func (s *Server) compile(rules types.KVRules) (processer.KVProcesser, error) {
+	dicts, err := dict.OpenAll(s.config.Dictionaries, rules.Dictionaries)
+	if err != nil {
+		return processer.KVProcesser{}, fmt.Errorf("Dictionaries: %w", err)
+	}
+	return processer.CompileKVProcesserWith(rules, dicts)
+}
+
+func (s *Server) setRuleSet(ctx context.Context, name string, rules types.KVRules, m processer.KVProcesser) error {
+	if s.config.Store != nil {
+		if err := s.config.Store.Put(ctx, name, rules); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ruleSets[name] = ruleSet{rules: rules, m: m}
+	return nil
+}
+
+func (s *Server) DeleteRuleSet(ctx context.Context, name string) error {
+	if s.config.Store != nil {
+		if err := s.config.Store.Delete(ctx, name); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.ruleSets, name)
+	return nil
+}
+
+func (s *Server) processer(name string) (processer.KVProcesser, error) {
+	if name == "" {
+		name = DefaultRuleSet
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	set, ok := s.ruleSets[name]
+	if !ok {
+		return processer.KVProcesser{}, httpError{http.StatusNotFound, fmt.Errorf("rule set %q not found", name)}
+	}
+	return set.m, nil
+}
+
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	if atomic.LoadInt32(&s.ready) == 0 {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "loading"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+type detectRequest struct {
+	RuleSet string          `json:"ruleSet"`
+	Input   json.RawMessage `json:"input"`
+}
+
+type detectResponse struct {
+	Pairs []Pair `json:"pairs"`
+}
+
+func (s *Server) detect(w http.ResponseWriter, r *http.Request) error {
+	var req detectRequest
+	if err := s.decode(w, r, &req); err != nil {
+		return err
+	}
+	m, err := s.processer(req.RuleSet)
+	if err != nil {
+		return err
+	}
+	pairs, err := m.Detect(req.Input)
+	if err != nil {
+		return httpError{http.StatusBadRequest, err}
+	}
+	resp := detectResponse{}
+	if resp.Pairs, err = fromKVPairs(pairs); err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, resp)
+	return nil
+}
+
+type maskResponse struct {
+	Output json.RawMessage `json:"output"`
+	Pairs  []Pair          `json:"pairs"`
+}
+
+func (s *Server) mask(w http.ResponseWriter, r *http.Request) error {
+	var req detectRequest
+	if err := s.decode(w, r, &req); err != nil {
+		return err
+	}
+	m, err := s.processer(req.RuleSet)
+	if err != nil {
+		return err
+	}
+	output, pairs, err := m.Mask(r.Context(), req.Input)
+	if err != nil {
+		return httpError{http.StatusBadRequest, err}
+	}
+	resp := maskResponse{Output: output}
+	if resp.Pairs, err = fromKVPairs(pairs); err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, resp)
+	return nil
+}
+
+type unmaskRequest struct {
+	RuleSet string          `json:"ruleSet"`
+	Input   json.RawMessage `json:"input"`
+	Pairs   []Pair          `json:"pairs"`
+}
+
+type unmaskResponse struct {
+	Output json.RawMessage `json:"output"`
+}
+
+func (s *Server) unmask(w http.ResponseWriter, r *http.Request) error {
+	var req unmaskRequest
+	if err := s.decode(w, r, &req); err != nil {
+		return err
+	}
+	m, err := s.processer(req.RuleSet)
+	if err != nil {
+		return err
+	}
+	pairs, err := toKVPairs(req.Pairs)
+	if err != nil {
+		return httpError{http.StatusBadRequest, fmt.Errorf("pairs: %w", err)}
+	}
+	output, err := m.Unmask(r.Context(), req.Input, pairs)
+	if err != nil {
+		return httpError{http.StatusBadRequest, err}
+	}
+	writeJSON(w, http.StatusOK, unmaskResponse{Output: output})
+	return nil
+}
+
+type ruleSetResponse struct {
+	Name  string        `json:"name"`
+	Rules types.KVRules `json:"rules"`
+}
+
+type listResponse struct {
+	RuleSets []ruleSetResponse `json:"ruleSets"`
+}
+
+func (s *Server) listRuleSets(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodGet {
+		return methodNotAllowed(w, http.MethodGet)
+	}
+	s.writeRuleSets(w)
+	return nil
+}
+
+func (s *Server) writeRuleSets(w http.ResponseWriter) {
+	s.mu.RLock()
+	resp := listResponse{RuleSets: make([]ruleSetResponse, 0, len(s.ruleSets))}
+	for name, set := range s.ruleSets {
+		resp.RuleSets = append(resp.RuleSets, ruleSetResponse{Name: name, Rules: set.rules})
+	}
+	s.mu.RUnlock()
+	sort.Slice(resp.RuleSets, func(i, j int) bool {
+		return resp.RuleSets[i].Name < resp.RuleSets[j].Name
+	})
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// get, put or delete rule set by name in path
+func (s *Server) ruleSet(w http.ResponseWriter, r *http.Request) error {
+	name := strings.TrimPrefix(r.URL.Path, "/v1/admin/rulesets/")
+	if err := validName(name); err != nil {
+		return httpError{http.StatusNotFound, err}
+	}
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.RLock()
+		set, ok := s.ruleSets[name]
+		s.mu.RUnlock()
+		if !ok {
+			return httpError{http.StatusNotFound, fmt.Errorf("rule set %q not found", name)}
+		}
+		writeJSON(w, http.StatusOK, ruleSetResponse{Name: name, Rules: set.rules})
+	case http.MethodPut:
+		rules, err := s.decodeRules(w, r)
+		if err != nil {
+			return err
+		}
+		m, err := s.compile(rules)
+		if err != nil {
+			return httpError{http.StatusUnprocessableEntity, err}
+		}
+		if err := s.setRuleSet(r.Context(), name, rules, m); err != nil {
+			return err
+		}
+		writeJSON(w, http.StatusOK, ruleSetResponse{Name: name, Rules: rules})
+	case http.MethodDelete:
+		if err := s.DeleteRuleSet(r.Context(), name); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		return methodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
+	}
+	return nil
+}
+
+type validateResponse struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// invalid rules are reported in response, not by status
+func (s *Server) validate(w http.ResponseWriter, r *http.Request) error {
+	rules, err := s.decodeRules(w, r)
+	if err == nil {
+		_, err = s.compile(rules)
+	}
+	var he httpError
+	switch {
+	case errors.As(err, &he) && he.status == http.StatusRequestEntityTooLarge:
+		return err
+	case err != nil:
+		writeJSON(w, http.StatusOK, validateResponse{Error: err.Error()})
+	default:
+		writeJSON(w, http.StatusOK, validateResponse{Valid: true})
+	}
+	return nil
+}
+
+func (s *Server) reload(w http.ResponseWriter, r *http.Request) error {
+	if s.config.Store == nil {
+		return httpError{http.StatusNotImplemented, errors.New("no store to reload from")}
+	}
+	if err := s.Load(r.Context()); err != nil {
+		return httpError{http.StatusUnprocessableEntity, err}
+	}
+	s.writeRuleSets(w)
+	return nil
+}
+
+// read body within MaxBodySize
+func (s *Server) body(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, s.config.MaxBodySize+1))
+	if int64(len(data)) > s.config.MaxBodySize {
+		return nil, httpError{http.StatusRequestEntityTooLarge, fmt.Errorf("body larger than %d bytes", s.config.MaxBodySize)}
+	}
+	return data, err
+}
+
+func (s *Server) decode(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	data, err := s.body(w, r)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return httpError{http.StatusBadRequest, err}
+	}
+	return nil
+}
+
+// rules in JSON, or YAML if Content-Type is YAML
+func (s *Server) decodeRules(w http.ResponseWriter, r *http.Request) (types.KVRules, error) {
+	data, err := s.body(w, r)
+	if err != nil {
+		return types.KVRules{}, err
+	}
+	name := "rules.json"
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); strings.Contains(mediaType, "yaml") {
+		name = "rules.yaml"
+	}
+	rules, err := rulefile.Parse(name, data)
+	if err != nil {
+		return rules, httpError{http.StatusBadRequest, err}
+	}
+	return rules, nil
+}
+
+type handlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+func (s *Server) post(h handlerFunc) handlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method != http.MethodPost {
+			return methodNotAllowed(w, http.MethodPost)
+		}
+		return h(w, r)
+	}
+}
+
+// check bearer token, errors of handler are responded as JSON
+func (s *Server) admin(h handlerFunc) http.HandlerFunc {
+	return s.handle(func(w http.ResponseWriter, r *http.Request) error {
+		if token := s.config.AdminToken; token != "" {
+			auth := r.Header.Get("Authorization")
+			if subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				return httpError{http.StatusUnauthorized, errors.New("invalid admin token")}
+			}
+		}
+		return h(w, r)
+	})
+}
+
+// error of handler as JSON, errors without status are 500
+func (s *Server) handle(h handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := h(w, r); err != nil {
+			status := http.StatusInternalServerError
+			var he httpError
+			if errors.As(err, &he) {
+				status = he.status
+			}
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+		}
+	}
+}
+
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e httpError) Error() string {
+	return e.err.Error()
+}
+
+func (e httpError) Unwrap() error {
+	return e.err
+}
+
+func methodNotAllowed(w http.ResponseWriter, methods ...string) error {
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	return httpError{http.StatusMethodNotAllowed, errors.New("method not allowed")}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
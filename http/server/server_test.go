@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type testClient struct {
+	t *testing.T
+	h http.Handler
+}
+
+// do request, returns status & body
+func (c testClient) do(method, path, contentType, body string, header ...string) (int, string) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for idx := 0; idx+1 < len(header); idx += 2 {
+		req.Header.Set(header[idx], header[idx+1])
+	}
+	rec := httptest.NewRecorder()
+	c.h.ServeHTTP(rec, req)
+	data, _ := io.ReadAll(rec.Body)
+	return rec.Code, strings.TrimSpace(string(data))
+}
+
+func TestServer_MaskUnmask(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "default.yaml"), []byte("detectRules:\n  - keyEqs: [password]\n"), 0o644)
+	store, _ := NewFileStore(dir)
+	s := New(Config{Store: store, MaxBodySize: 1024})
+	c := testClient{t: t, h: s}
+	if status, _ := c.do("GET", "/readyz", "", ""); status != http.StatusServiceUnavailable {
+		t.Errorf("readyz before Load status = %d, want 503", status)
+	}
+	if err := s.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if status, _ := c.do("GET", "/readyz", "", ""); status != http.StatusOK {
+		t.Errorf("readyz after Load status = %d, want 200", status)
+	}
+
+	status, body := c.do("POST", "/v1/mask", "application/json", `{"input":{"user":"a","password":"abc"}}`)
+	if status != http.StatusOK {
+		t.Fatalf("mask status = %d, body %s", status, body)
+	}
+	var masked maskResponse
+	if err := json.Unmarshal([]byte(body), &masked); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if string(masked.Output) != `{"user":"a","password":"***"}` || len(masked.Pairs) != 1 || masked.Pairs[0].Pos == nil {
+		t.Errorf("mask = %s", body)
+	}
+	unmaskReq, _ := json.Marshal(unmaskRequest{Input: masked.Output, Pairs: masked.Pairs})
+	status, body = c.do("POST", "/v1/unmask", "application/json", string(unmaskReq))
+	if want := `{"output":{"user":"a","password":"abc"}}`; status != http.StatusOK || body != want {
+		t.Errorf("unmask = %d %s, want %s", status, body, want)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "detect",
+			method:     "POST",
+			path:       "/v1/detect",
+			body:       `{"ruleSet":"default","input":[{"password":1}]}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"pairs":[{"key":"password","path":[0,"password"],"val":1,"valMasked":1,"detectRule":0,"pos":{"keyStart":2,"keyEnd":12,"keyLine":1,"keyColumn":3,"valStart":13,"valEnd":14,"line":1,"column":14,"endLine":1,"endColumn":15}}]}`,
+		},
+		{
+			name:       "unknown rule set",
+			method:     "POST",
+			path:       "/v1/mask",
+			body:       `{"ruleSet":"pii","input":{}}`,
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"rule set \"pii\" not found"}`,
+		},
+		{
+			name:       "invalid input",
+			method:     "POST",
+			path:       "/v1/mask",
+			body:       `{"input":"abc"`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "too large",
+			method:     "POST",
+			path:       "/v1/mask",
+			body:       `{"input":"` + strings.Repeat("a", 1024) + `"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantBody:   `{"error":"body larger than 1024 bytes"}`,
+		},
+		{
+			name:       "method",
+			method:     "GET",
+			path:       "/v1/mask",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   `{"error":"method not allowed"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := c.do(tt.method, tt.path, "application/json", tt.body)
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body %s", status, tt.wantStatus, body)
+			}
+			if tt.wantBody != "" && body != tt.wantBody {
+				t.Errorf("body = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_Admin(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewFileStore(dir)
+	s := New(Config{Store: store, AdminToken: "secret"})
+	if err := s.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	c := testClient{t: t, h: s}
+	auth := []string{"Authorization", "Bearer secret"}
+
+	if status, _ := c.do("PUT", "/v1/admin/rulesets/pii", "application/json", `{}`); status != http.StatusUnauthorized {
+		t.Errorf("put without token status = %d, want 401", status)
+	}
+	if status, body := c.do("PUT", "/v1/admin/rulesets/pii", "application/json", `{"DetectRules":[{"KeyRegex":["("]}]}`, auth...); status != http.StatusUnprocessableEntity {
+		t.Errorf("put invalid rules = %d %s, want 422", status, body)
+	}
+	if status, body := c.do("PUT", "/v1/admin/rulesets/pii", "application/yaml", "detectRules:\n  - keyEqs: [phone]\n", auth...); status != http.StatusOK {
+		t.Fatalf("put = %d %s", status, body)
+	}
+	// rule set is used by the next request and saved in store
+	if status, body := c.do("POST", "/v1/mask", "application/json", `{"ruleSet":"pii","input":{"phone":"123"}}`); status != http.StatusOK || !strings.HasPrefix(body, `{"output":{"phone":"***"}`) {
+		t.Errorf("mask after put = %d %s", status, body)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pii.json")); err != nil {
+		t.Errorf("put did not save rule set: %v", err)
+	}
+
+	status, body := c.do("POST", "/v1/admin/validate", "application/yaml", "detectRules:\n  - valRegex: ['(']\n", auth...)
+	if status != http.StatusOK || !strings.HasPrefix(body, `{"valid":false,"error":`) {
+		t.Errorf("validate = %d %s", status, body)
+	}
+	status, body = c.do("POST", "/v1/admin/validate", "application/json", `{"DetectRules":[{"KeyEqs":["a"]}]}`, auth...)
+	if status != http.StatusOK || body != `{"valid":true}` {
+		t.Errorf("validate = %d %s", status, body)
+	}
+
+	// files changed in store are swapped by reload
+	os.WriteFile(filepath.Join(dir, "pii.json"), []byte(`{"DetectRules":[{"KeyEqs":["email"]}]}`), 0o644)
+	if status, body := c.do("POST", "/v1/admin/reload", "", "", auth...); status != http.StatusOK || !strings.Contains(body, `"email"`) {
+		t.Errorf("reload = %d %s", status, body)
+	}
+	if _, body := c.do("POST", "/v1/mask", "application/json", `{"ruleSet":"pii","input":{"phone":"123"}}`); body != `{"output":{"phone":"123"},"pairs":[]}` {
+		t.Errorf("mask after reload = %s", body)
+	}
+	// invalid store keeps rule sets in use
+	os.WriteFile(filepath.Join(dir, "pii.json"), []byte(`{"DetectRules":[{"KeyRegex":["("]}]}`), 0o644)
+	if status, _ := c.do("POST", "/v1/admin/reload", "", "", auth...); status != http.StatusUnprocessableEntity {
+		t.Errorf("reload invalid status = %d, want 422", status)
+	}
+	if status, body := c.do("GET", "/v1/admin/rulesets/pii", "", "", auth...); status != http.StatusOK || !strings.Contains(body, `"email"`) {
+		t.Errorf("get after invalid reload = %d %s", status, body)
+	}
+
+	if status, _ := c.do("DELETE", "/v1/admin/rulesets/pii", "", "", auth...); status != http.StatusNoContent {
+		t.Errorf("delete status = %d, want 204", status)
+	}
+	if status, body := c.do("GET", "/v1/admin/rulesets", "", "", auth...); status != http.StatusOK || body != `{"ruleSets":[]}` {
+		t.Errorf("list = %d %s", status, body)
+	}
+}
+
+func TestServer_Dictionaries(t *testing.T) {
+	dictionaries := fstest.MapFS{"dict/employees.txt": {Data: []byte("Alice Zhang\n")}}
+	rules := func(file string) string {
+		return `{"dictionaries":{"employees":{"file":"` + file + `"}},"detectRules":[{"valDictionary":"employees"}]}`
+	}
+	c := testClient{t: t, h: New(Config{Dictionaries: dictionaries})}
+	for _, file := range []string{"/etc/passwd", "../dict/employees.txt", "dict/../dict/employees.txt", "missing.txt"} {
+		if status, body := c.do("PUT", "/v1/admin/rulesets/hr", "application/json", rules(file)); status != http.StatusUnprocessableEntity {
+			t.Errorf("PUT file %q = %d %s, want 422", file, status, body)
+		}
+	}
+	if status, body := c.do("PUT", "/v1/admin/rulesets/hr", "application/json", rules("dict/employees.txt")); status != http.StatusOK {
+		t.Fatalf("PUT = %d %s", status, body)
+	}
+	status, body := c.do("POST", "/v1/mask", "application/json", `{"ruleSet":"hr","input":{"owner":"Alice Zhang"}}`)
+	if status != http.StatusOK || !strings.Contains(body, `"output":{"owner":"***********"}`) {
+		t.Errorf("mask = %d %s", status, body)
+	}
+
+	// dictionaries are refused without Config.Dictionaries
+	c = testClient{t: t, h: New(Config{})}
+	if status, body := c.do("POST", "/v1/admin/validate", "application/json", rules("dict/employees.txt")); status != http.StatusOK || !strings.Contains(body, "not enabled") {
+		t.Errorf("validate = %d %s", status, body)
+	}
+}
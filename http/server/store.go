@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/senayuki/mosaic/pkg/rulefile"
+	"github.com/senayuki/mosaic/types"
+)
+
+var ErrNotFound = errors.New("rule set not found")
+
+// names of rule sets, also file names of FileStore
+var nameExp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,127}$`)
+
+func validName(name string) error {
+	if !nameExp.MatchString(name) {
+		return fmt.Errorf("invalid rule set name %q", name)
+	}
+	return nil
+}
+
+// persisted rule sets, e.g. files, a database or a config service
+type Store interface {
+	List(ctx context.Context) ([]string, error)
+	// returns ErrNotFound if rule set is not stored
+	Get(ctx context.Context, name string) (types.KVRules, error)
+	Put(ctx context.Context, name string, rules types.KVRules) error
+	// deleting rule set not stored is not an error
+	Delete(ctx context.Context, name string) error
+}
+
+// extensions of rules files, files written by FileStore are JSON
+var fileExts = []string{".json", ".yaml", ".yml"}
+
+/*
+rule sets in a directory, one file per rule set named <name>.json, .yaml or .yml
+files are written atomically by rename, so files can be edited by hand or synced by deployments
+and reloaded by Server.Load
+*/
+type FileStore struct {
+	Dir string
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{Dir: dir}, nil
+}
+
+func (s *FileStore) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]struct{}{}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		name := strings.TrimSuffix(entry.Name(), ext)
+		if !isRulesExt(ext) || validName(name) != nil {
+			continue
+		}
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// file of rule set, the first of fileExts if several exist
+func (s *FileStore) Get(ctx context.Context, name string) (types.KVRules, error) {
+	if err := validName(name); err != nil {
+		return types.KVRules{}, err
+	}
+	for _, ext := range fileExts {
+		data, err := os.ReadFile(filepath.Join(s.Dir, name+ext))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return types.KVRules{}, err
+		}
+		rules, err := rulefile.Parse(name+ext, data)
+		if err != nil {
+			return types.KVRules{}, fmt.Errorf("%s%s: %w", name, ext, err)
+		}
+		return rules, nil
+	}
+	return types.KVRules{}, ErrNotFound
+}
+
+// write <name>.json, files of rule set in other formats are removed
+func (s *FileStore) Put(ctx context.Context, name string, rules types.KVRules) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.Dir, "."+name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.Dir, name+".json")); err != nil {
+		return err
+	}
+	return s.remove(name, ".json")
+}
+
+func (s *FileStore) Delete(ctx context.Context, name string) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+	return s.remove(name, "")
+}
+
+// remove files of rule set except extension keep
+func (s *FileStore) remove(name, keep string) error {
+	for _, ext := range fileExts {
+		if ext == keep {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.Dir, name+ext)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func isRulesExt(ext string) bool {
+	for _, e := range fileExts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	// hand-written YAML files are read, other files are ignored
+	os.WriteFile(filepath.Join(dir, "pii.yaml"), []byte("detectRules:\n  - keyEqs: [phone]\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("# rules"), 0o644)
+	rules := types.KVRules{DetectRules: []types.KVDetectConfig{{KeyEqs: []string{"password"}}}}
+	if err := s.Put(ctx, "default", rules); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	names, err := s.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if want := []string{"default", "pii"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("List() = %v, want %v", names, want)
+	}
+	got, err := s.Get(ctx, "default")
+	if err != nil || !reflect.DeepEqual(got, rules) {
+		t.Errorf("Get() = %+v, %v, want %+v", got, err, rules)
+	}
+	if got, err := s.Get(ctx, "pii"); err != nil || got.DetectRules[0].KeyEqs[0] != "phone" {
+		t.Errorf("Get() YAML = %+v, %v", got, err)
+	}
+
+	// put replaces YAML file by JSON
+	if err := s.Put(ctx, "pii", rules); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pii.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Put() kept pii.yaml, error = %v", err)
+	}
+	if err := s.Delete(ctx, "pii"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Get(ctx, "pii"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() deleted error = %v, want ErrNotFound", err)
+	}
+	if err := s.Put(ctx, "../escape", rules); err == nil {
+		t.Errorf("Put() invalid name error = nil")
+	}
+}
@@ -0,0 +1,112 @@
+// masking of structured log fields, shared by log integrations
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+)
+
+/*
+mask value of log field keyed by key at path (path of groups & key), returns masked value & masked pairs,
+value is returned as is if nothing is masked, types.KVDropped if field is dropped
+
+scalars are masked as JSON values without reflection, masked number is kept if still a number, or logged as string;
+error & fmt.Stringer are masked as text, other values (structs, maps, slices...) are masked copies by KVProcesser.MaskStruct
+*/
+func MaskValue(ctx context.Context, m processer.KVProcesser, path types.JSONPath, key string, val interface{}) (interface{}, []types.KVPair, error) {
+	var jsonVal interface{}
+	switch v := val.(type) {
+	case nil:
+		jsonVal = nil
+	case string:
+		jsonVal = v
+	case bool:
+		jsonVal = v
+	case int:
+		jsonVal = json.Number(strconv.FormatInt(int64(v), 10))
+	case int8:
+		jsonVal = json.Number(strconv.FormatInt(int64(v), 10))
+	case int16:
+		jsonVal = json.Number(strconv.FormatInt(int64(v), 10))
+	case int32:
+		jsonVal = json.Number(strconv.FormatInt(int64(v), 10))
+	case int64:
+		jsonVal = json.Number(strconv.FormatInt(v, 10))
+	case uint:
+		jsonVal = json.Number(strconv.FormatUint(uint64(v), 10))
+	case uint8:
+		jsonVal = json.Number(strconv.FormatUint(uint64(v), 10))
+	case uint16:
+		jsonVal = json.Number(strconv.FormatUint(uint64(v), 10))
+	case uint32:
+		jsonVal = json.Number(strconv.FormatUint(uint64(v), 10))
+	case uint64:
+		jsonVal = json.Number(strconv.FormatUint(v, 10))
+	case float32:
+		jsonVal = floatNumber(float64(v), 32)
+	case float64:
+		jsonVal = floatNumber(v, 64)
+	case json.Number:
+		jsonVal = v
+	case error:
+		jsonVal = v.Error()
+	case fmt.Stringer:
+		jsonVal = v.String()
+	default:
+		return maskStruct(ctx, m, path, val)
+	}
+	pair, matched, err := m.MaskPair(ctx, types.KVPair{Key: key, Val: jsonVal, ValJSONPath: path})
+	if err != nil || !matched {
+		return val, nil, err
+	}
+	return logValue(pair.ValMasked), []types.KVPair{pair}, nil
+}
+
+// JSON number of float, NaN & Inf are strings
+func floatNumber(f float64, bits int) interface{} {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, bits)
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, bits))
+}
+
+// masked JSON value to be logged, number is int64 or float64 if possible
+func logValue(valMasked interface{}) interface{} {
+	switch v := valMasked.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case json.RawMessage:
+		return processer.MaskedText(v)
+	default:
+		return v
+	}
+}
+
+// mask value nested in maps of path, so paths & keys of pairs are full paths
+func maskStruct(ctx context.Context, m processer.KVProcesser, path types.JSONPath, val interface{}) (interface{}, []types.KVPair, error) {
+	keys := path.ToStrings()
+	wrapped := val
+	for idx := len(keys) - 1; idx >= 0; idx-- {
+		wrapped = map[string]interface{}{keys[idx]: wrapped}
+	}
+	masked, pairs, err := m.MaskStruct(ctx, wrapped)
+	if err != nil || len(pairs) == 0 {
+		return val, nil, err
+	}
+	for _, key := range keys {
+		masked = masked.(map[string]interface{})[key]
+	}
+	return masked, pairs, nil
+}
@@ -0,0 +1,17 @@
+module github.com/senayuki/mosaic/logging/logrusmask
+
+go 1.18
+
+replace github.com/senayuki/mosaic => ../..
+
+require (
+	github.com/senayuki/mosaic v0.0.0-00010101000000-000000000000
+	github.com/sirupsen/logrus v1.9.3
+)
+
+require (
+	github.com/stretchr/testify v1.8.1 // indirect
+	github.com/valyala/fastjson v1.6.4 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+)
@@ -0,0 +1,68 @@
+// logrus hook masking fields & messages by processer
+package logrusmask
+
+import (
+	"context"
+
+	"github.com/senayuki/mosaic/logging"
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+	"github.com/sirupsen/logrus"
+)
+
+type Options struct {
+	SkipMessage bool // do not scan message by text scanner
+	// called with masked pairs of each entry, e.g. for metrics
+	OnMask func(ctx context.Context, pairs []types.KVPair)
+}
+
+// hook masking fields keyed by field key, fired on all levels
+type Hook struct {
+	m    processer.KVProcesser
+	opts Options
+}
+
+func NewHook(m processer.KVProcesser, opts Options) *Hook {
+	return &Hook{m: m, opts: opts}
+}
+
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// mask entry in place, data of entry fired is a copy owned by entry
+// dropped fields & fields failed to mask are deleted
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var pairs []types.KVPair
+	if !h.opts.SkipMessage {
+		message, messagePairs, err := h.m.MaskText(ctx, entry.Message)
+		if err != nil {
+			return err
+		}
+		entry.Message, pairs = message, messagePairs
+	}
+	for key, val := range entry.Data {
+		masked, fieldPairs, err := logging.MaskValue(ctx, h.m, types.NewJSONPath().Append(key), key, val)
+		if err != nil {
+			delete(entry.Data, key)
+			continue
+		}
+		if len(fieldPairs) == 0 {
+			continue
+		}
+		pairs = append(pairs, fieldPairs...)
+		if _, dropped := masked.(types.KVDropped); dropped {
+			delete(entry.Data, key)
+		} else {
+			entry.Data[key] = masked
+		}
+	}
+	if len(pairs) > 0 && h.opts.OnMask != nil {
+		h.opts.OnMask(ctx, pairs)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package logrusmask
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+	"github.com/sirupsen/logrus"
+)
+
+func TestHook(t *testing.T) {
+	m := processer.NewKVProcesser(types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{KeyEqs: []string{"password", "phone"}},
+			{KeyEqs: []string{"token"}, MaskRef: "drop"},
+		},
+		MaskRules: []types.KVMaskConfig{
+			{RuleName: "drop", MaskType: types.MaskTypeDrop},
+		},
+	})
+	tests := []struct {
+		name string
+		log  func(l *logrus.Logger)
+		want string
+	}{
+		{
+			name: "fields & message",
+			log: func(l *logrus.Logger) {
+				l.WithFields(logrus.Fields{"password": "123456", "token": "t", "count": 1}).Info("login password=abc")
+			},
+			want: `level=info msg="login password=***" count=1 password="******"`,
+		},
+		{
+			name: "maps",
+			log: func(l *logrus.Logger) {
+				l.WithField("user", map[string]interface{}{"name": "a", "phone": 123}).Info("login")
+			},
+			want: `level=info msg=login user="map[name:a phone:***]"`,
+		},
+		{
+			name: "nothing matched",
+			log: func(l *logrus.Logger) {
+				l.WithField("name", "a").Info("hello")
+			},
+			want: `level=info msg=hello name=a`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := logrus.New()
+			l.SetOutput(&buf)
+			l.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+			l.AddHook(NewHook(m, Options{}))
+			tt.log(l)
+			if got := strings.TrimSpace(buf.String()); got != tt.want {
+				t.Errorf("Fire() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/senayuki/mosaic/logging/slogmask
+
+go 1.21
+
+replace github.com/senayuki/mosaic => ../..
+
+require github.com/senayuki/mosaic v0.0.0-00010101000000-000000000000
+
+require (
+	github.com/valyala/fastjson v1.6.4 // indirect
+	golang.org/x/text v0.14.0 // indirect
+)
@@ -0,0 +1,133 @@
+// log/slog handler masking attributes & messages by processer
+package slogmask
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/senayuki/mosaic/logging"
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+)
+
+type Options struct {
+	SkipMessage bool // do not scan message by text scanner
+	// called with masked pairs of each record, e.g. for metrics, attributes of WithAttrs are reported once
+	OnMask func(ctx context.Context, pairs []types.KVPair)
+}
+
+// handler masking attributes keyed by attribute key, paths are groups & key
+type Handler struct {
+	next   slog.Handler
+	m      processer.KVProcesser
+	opts   Options
+	groups types.JSONPath
+}
+
+func NewHandler(next slog.Handler, m processer.KVProcesser, opts Options) *Handler {
+	return &Handler{next: next, m: m, opts: opts, groups: types.NewJSONPath()}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// record is passed as is if nothing is masked
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	var pairs []types.KVPair
+	message := r.Message
+	if !h.opts.SkipMessage {
+		masked, messagePairs, err := h.m.MaskText(ctx, r.Message)
+		if err != nil {
+			return err
+		}
+		message, pairs = masked, messagePairs
+	}
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	var err error
+	r.Attrs(func(a slog.Attr) bool {
+		var masked slog.Attr
+		var attrPairs []types.KVPair
+		masked, attrPairs, err = h.maskAttr(ctx, h.groups, a)
+		if err != nil {
+			return false
+		}
+		pairs = append(pairs, attrPairs...)
+		attrs = append(attrs, masked)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if len(pairs) == 0 {
+		return h.next.Handle(ctx, r)
+	}
+	if h.opts.OnMask != nil {
+		h.opts.OnMask(ctx, pairs)
+	}
+	masked := slog.NewRecord(r.Time, r.Level, message, r.PC)
+	masked.AddAttrs(attrs...)
+	return h.next.Handle(ctx, masked)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	masked := make([]slog.Attr, 0, len(attrs))
+	var pairs []types.KVPair
+	for _, a := range attrs {
+		maskedAttr, attrPairs, err := h.maskAttr(context.Background(), h.groups, a)
+		if err != nil {
+			// attribute failed to mask is not logged
+			continue
+		}
+		pairs = append(pairs, attrPairs...)
+		masked = append(masked, maskedAttr)
+	}
+	if len(pairs) > 0 && h.opts.OnMask != nil {
+		h.opts.OnMask(context.Background(), pairs)
+	}
+	return &Handler{next: h.next.WithAttrs(masked), m: h.m, opts: h.opts, groups: h.groups}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &Handler{next: h.next.WithGroup(name), m: h.m, opts: h.opts, groups: h.groups.Append(name)}
+}
+
+// mask attribute, dropped attribute is empty and ignored by handlers
+func (h *Handler) maskAttr(ctx context.Context, groups types.JSONPath, a slog.Attr) (slog.Attr, []types.KVPair, error) {
+	value := a.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		path := groups
+		if a.Key != "" {
+			// attributes of group with empty key are inlined
+			path = groups.Append(a.Key)
+		}
+		var pairs []types.KVPair
+		group := value.Group()
+		attrs := make([]slog.Attr, 0, len(group))
+		for _, member := range group {
+			masked, memberPairs, err := h.maskAttr(ctx, path, member)
+			if err != nil {
+				return a, nil, err
+			}
+			pairs = append(pairs, memberPairs...)
+			attrs = append(attrs, masked)
+		}
+		if len(pairs) == 0 {
+			return a, nil, nil
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}, pairs, nil
+	default:
+		masked, pairs, err := logging.MaskValue(ctx, h.m, groups.Append(a.Key), a.Key, value.Any())
+		if err != nil || len(pairs) == 0 {
+			return a, nil, err
+		}
+		if _, dropped := masked.(types.KVDropped); dropped {
+			return slog.Attr{}, pairs, nil
+		}
+		return slog.Any(a.Key, masked), pairs, nil
+	}
+}
@@ -0,0 +1,92 @@
+package slogmask
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+)
+
+type user struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone"`
+}
+
+func TestHandler(t *testing.T) {
+	m := processer.NewKVProcesser(types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{KeyEqs: []string{"password", "phone"}},
+			{KeyEqs: []string{"token"}, MaskRef: "drop"},
+		},
+		MaskRules: []types.KVMaskConfig{
+			{RuleName: "drop", MaskType: types.MaskTypeDrop},
+		},
+	})
+	tests := []struct {
+		name string
+		log  func(l *slog.Logger)
+		want string
+	}{
+		{
+			name: "attributes & message",
+			log: func(l *slog.Logger) {
+				l.Info("login password=abc", "password", "123456", "token", "t", "count", 1)
+			},
+			want: `level=INFO msg="login password=***" password=****** count=1`,
+		},
+		{
+			name: "groups & structs",
+			log: func(l *slog.Logger) {
+				l.WithGroup("req").With("password", "abc").Info("login", slog.Group("user", "phone", 123), "user", user{Name: "a", Phone: "123"})
+			},
+			want: `level=INFO msg=login req.password=*** req.user.phone=*** req.user="{Name:a Phone:***}"`,
+		},
+		{
+			name: "nothing matched",
+			log: func(l *slog.Logger) {
+				l.Info("hello", "name", "a")
+			},
+			want: `level=INFO msg=hello name=a`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			next := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					if a.Key == slog.TimeKey && len(groups) == 0 {
+						return slog.Attr{}
+					}
+					return a
+				},
+			})
+			tt.log(slog.New(NewHandler(next, m, Options{})))
+			if got := strings.TrimSpace(buf.String()); got != tt.want {
+				t.Errorf("Handle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_OnMask(t *testing.T) {
+	m := processer.NewKVProcesser(types.KVRules{
+		DetectRules: []types.KVDetectConfig{{KeyEqs: []string{"password"}}},
+	})
+	var paths []string
+	h := NewHandler(slog.NewTextHandler(&bytes.Buffer{}, nil), m, Options{
+		SkipMessage: true,
+		OnMask: func(ctx context.Context, pairs []types.KVPair) {
+			for _, pair := range pairs {
+				paths = append(paths, pair.ValJSONPath.String())
+			}
+		},
+	})
+	slog.New(h).WithGroup("a").Info("password=abc", "password", "abc")
+	if strings.Join(paths, ",") != "a->password" {
+		t.Errorf("OnMask() paths = %v, want a->password", paths)
+	}
+}
@@ -0,0 +1,16 @@
+module github.com/senayuki/mosaic/logging/zapmask
+
+go 1.19
+
+replace github.com/senayuki/mosaic => ../..
+
+require (
+	github.com/senayuki/mosaic v0.0.0-00010101000000-000000000000
+	go.uber.org/zap v1.26.0
+)
+
+require (
+	github.com/valyala/fastjson v1.6.4 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+)
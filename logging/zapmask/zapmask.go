@@ -0,0 +1,194 @@
+// zapcore.Core masking fields & messages by processer
+package zapmask
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/senayuki/mosaic/logging"
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type Options struct {
+	SkipMessage bool // do not scan message by text scanner
+	// called with masked pairs of each entry, e.g. for metrics, fields of With are reported once
+	OnMask func(pairs []types.KVPair)
+}
+
+// core masking fields keyed by field key, paths are namespaces & key
+type core struct {
+	zapcore.Core
+	m         processer.KVProcesser
+	opts      Options
+	namespace types.JSONPath
+}
+
+// wrap core, fields are masked before written to next core
+func NewCore(next zapcore.Core, m processer.KVProcesser, opts Options) zapcore.Core {
+	return &core{Core: next, m: m, opts: opts, namespace: types.NewJSONPath()}
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	masked, pairs, namespace := c.maskFields(fields)
+	if len(pairs) > 0 && c.opts.OnMask != nil {
+		c.opts.OnMask(pairs)
+	}
+	return &core{Core: c.Core.With(masked), m: c.m, opts: c.opts, namespace: namespace}
+}
+
+func (c *core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	var pairs []types.KVPair
+	if !c.opts.SkipMessage {
+		message, messagePairs, err := c.m.MaskText(context.Background(), ent.Message)
+		if err != nil {
+			return err
+		}
+		ent.Message, pairs = message, messagePairs
+	}
+	masked, fieldPairs, _ := c.maskFields(fields)
+	pairs = append(pairs, fieldPairs...)
+	if len(pairs) > 0 && c.opts.OnMask != nil {
+		c.opts.OnMask(pairs)
+	}
+	return c.Core.Write(ent, masked)
+}
+
+// mask fields, returns fields as is if nothing is masked, and namespace after fields
+// field failed to mask is skipped
+func (c *core) maskFields(fields []zapcore.Field) ([]zapcore.Field, []types.KVPair, types.JSONPath) {
+	namespace := c.namespace
+	var masked []zapcore.Field
+	var pairs []types.KVPair
+	for idx, f := range fields {
+		var out []zapcore.Field
+		var fieldPairs []types.KVPair
+		var changed bool
+		switch f.Type {
+		case zapcore.NamespaceType:
+			namespace = namespace.Append(f.Key)
+		case zapcore.InlineMarshalerType:
+			out, fieldPairs, changed = c.maskInline(f, namespace)
+		default:
+			out, fieldPairs, changed = c.maskField(f, namespace)
+		}
+		if !changed {
+			if masked != nil {
+				masked = append(masked, f)
+			}
+			continue
+		}
+		if masked == nil {
+			masked = append(make([]zapcore.Field, 0, len(fields)), fields[:idx]...)
+		}
+		masked = append(masked, out...)
+		pairs = append(pairs, fieldPairs...)
+	}
+	if masked == nil {
+		return fields, nil, namespace
+	}
+	return masked, pairs, namespace
+}
+
+// masked field, false if field is kept as is
+func (c *core) maskField(f zapcore.Field, namespace types.JSONPath) ([]zapcore.Field, []types.KVPair, bool) {
+	val, ok := fieldValue(f)
+	if !ok {
+		return nil, nil, false
+	}
+	maskedVal, pairs, err := logging.MaskValue(context.Background(), c.m, namespace.Append(f.Key), f.Key, val)
+	if err != nil {
+		return []zapcore.Field{zap.Skip()}, nil, true
+	}
+	if len(pairs) == 0 {
+		return nil, nil, false
+	}
+	return []zapcore.Field{toField(f.Key, maskedVal)}, pairs, true
+}
+
+// keys of inline marshaler are masked in namespace & written as separate fields, false if field is kept as is
+func (c *core) maskInline(f zapcore.Field, namespace types.JSONPath) ([]zapcore.Field, []types.KVPair, bool) {
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	keys := make([]string, 0, len(enc.Fields))
+	for key := range enc.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make([]zapcore.Field, 0, len(keys))
+	var pairs []types.KVPair
+	changed := false
+	for _, key := range keys {
+		maskedVal, keyPairs, err := logging.MaskValue(context.Background(), c.m, namespace.Append(key), key, enc.Fields[key])
+		if err != nil {
+			changed = true
+			continue
+		}
+		changed = changed || len(keyPairs) > 0
+		pairs = append(pairs, keyPairs...)
+		out = append(out, toField(key, maskedVal))
+	}
+	if !changed {
+		return nil, nil, false
+	}
+	return out, pairs, true
+}
+
+// value of field to be masked, false if field is not masked (e.g. time & binary)
+func fieldValue(f zapcore.Field) (interface{}, bool) {
+	switch f.Type {
+	case zapcore.StringType:
+		return f.String, true
+	case zapcore.ByteStringType:
+		return string(f.Interface.([]byte)), true
+	case zapcore.BoolType:
+		return f.Integer == 1, true
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type:
+		return f.Integer, true
+	case zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType:
+		return uint64(f.Integer), true
+	case zapcore.Float64Type:
+		return math.Float64frombits(uint64(f.Integer)), true
+	case zapcore.Float32Type:
+		return math.Float32frombits(uint32(f.Integer)), true
+	case zapcore.ErrorType, zapcore.StringerType, zapcore.ReflectType:
+		return f.Interface, true
+	case zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType:
+		// encode marshaler to maps & slices
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		return enc.Fields[f.Key], true
+	default:
+		return nil, false
+	}
+}
+
+func toField(key string, val interface{}) zapcore.Field {
+	switch v := val.(type) {
+	case types.KVDropped:
+		return zap.Skip()
+	case string:
+		return zap.String(key, v)
+	case int64:
+		return zap.Int64(key, v)
+	case float64:
+		return zap.Float64(key, v)
+	case bool:
+		return zap.Bool(key, v)
+	case fmt.Stringer:
+		return zap.Stringer(key, v)
+	default:
+		return zap.Any(key, v)
+	}
+}
@@ -0,0 +1,119 @@
+package zapmask
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type user struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone"`
+}
+
+func (u user) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", u.Name)
+	enc.AddString("phone", u.Phone)
+	return nil
+}
+
+func TestCore(t *testing.T) {
+	m := processer.NewKVProcesser(types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{KeyEqs: []string{"password", "phone", "pin"}},
+			{KeyEqs: []string{"token"}, MaskRef: "drop"},
+		},
+		MaskRules: []types.KVMaskConfig{
+			{RuleName: "drop", MaskType: types.MaskTypeDrop},
+		},
+	})
+	tests := []struct {
+		name        string
+		log         func(l *zap.Logger)
+		wantMessage string
+		wantFields  map[string]interface{}
+	}{
+		{
+			name: "fields & message",
+			log: func(l *zap.Logger) {
+				l.Info("login password=abc", zap.String("password", "123456"), zap.String("token", "t"), zap.Int("pin", 1234), zap.Int("count", 1))
+			},
+			wantMessage: "login password=***",
+			wantFields:  map[string]interface{}{"password": "******", "pin": "****", "count": int64(1)},
+		},
+		{
+			name: "namespaces & objects",
+			log: func(l *zap.Logger) {
+				l.With(zap.Namespace("req"), zap.String("password", "abc")).Info("login",
+					zap.Object("user", user{Name: "a", Phone: "123"}),
+					zap.Error(errors.New("bad")))
+			},
+			wantMessage: "login",
+			wantFields: map[string]interface{}{
+				"req": map[string]interface{}{"password": "***", "user": map[string]interface{}{"name": "a", "phone": "***"}, "error": "bad"},
+			},
+		},
+		{
+			name: "inline object",
+			log: func(l *zap.Logger) {
+				l.Info("login", zap.Inline(user{Name: "a", Phone: "123"}))
+			},
+			wantMessage: "login",
+			wantFields:  map[string]interface{}{"name": "a", "phone": "***"},
+		},
+		{
+			name: "nothing matched",
+			log: func(l *zap.Logger) {
+				l.Info("hello", zap.String("name", "a"))
+			},
+			wantMessage: "hello",
+			wantFields:  map[string]interface{}{"name": "a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, logs := observer.New(zapcore.DebugLevel)
+			tt.log(zap.New(NewCore(next, m, Options{})))
+			entries := logs.AllUntimed()
+			if len(entries) != 1 {
+				t.Fatalf("logged %d entries, want 1", len(entries))
+			}
+			if entries[0].Message != tt.wantMessage {
+				t.Errorf("Write() message = %v, want %v", entries[0].Message, tt.wantMessage)
+			}
+			if got := entries[0].ContextMap(); !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("Write() fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestCore_Paths(t *testing.T) {
+	m := processer.NewKVProcesser(types.KVRules{
+		DetectRules: []types.KVDetectConfig{{KeyEqs: []string{"password"}}},
+	})
+	var paths []string
+	next, _ := observer.New(zapcore.DebugLevel)
+	l := zap.New(NewCore(next, m, Options{
+		SkipMessage: true,
+		OnMask: func(pairs []types.KVPair) {
+			for _, pair := range pairs {
+				paths = append(paths, pair.ValJSONPath.String())
+			}
+		},
+	}))
+	inline := zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		enc.AddString("password", "abc")
+		return nil
+	})
+	l.With(zap.Namespace("a")).Info("password=abc", zap.Any("b", map[string]string{"password": "abc"}), zap.Inline(inline))
+	if len(paths) != 2 || paths[0] != "a->b->password" || paths[1] != "a->password" {
+		t.Errorf("OnMask() paths = %v, want [a->b->password a->password]", paths)
+	}
+}
@@ -0,0 +1,47 @@
+package mask
+
+import (
+	"context"
+	"unicode"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+// keep BIN and last 4 digits in PCI style, separators are kept, 4111 11** **** 1111
+type MarkCardProcesser struct {
+	CoverChar rune
+	BINLength int
+}
+
+func (m *MarkCardProcesser) Init(maskRule *types.KVMaskConfig) {
+	param := maskRule.CardParam
+	m.CoverChar = coverChar(param.Char)
+	m.BINLength = defaultKeep(param.BINLength, 6)
+}
+
+func (m MarkCardProcesser) Mask(ctx context.Context, in string) (out string, err error) {
+	digits := 0
+	for _, r := range in {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+	if digits == 0 {
+		return coverAll(in, m.CoverChar), nil
+	}
+	keepFirst, keepLast := m.BINLength, 4
+	if digits < 13 || digits-keepFirst-keepLast <= 0 {
+		keepFirst = 0
+	}
+	// short or truncated value would be left almost as is
+	if digits <= keepLast {
+		keepLast = 0
+	}
+	cover := MarkCoverProcesser{
+		CoverChar:  m.CoverChar,
+		Offset:     keepFirst,
+		Padding:    keepLast,
+		DigitsOnly: true,
+	}
+	return cover.Mask(ctx, in)
+}
@@ -7,11 +7,12 @@ import (
 )
 
 type MarkCoverProcesser struct {
-	CoverChar rune
-	Offset    int
-	Padding   int
-	Length    int
-	Reverse   bool
+	CoverChar  rune
+	Offset     int
+	Padding    int
+	Length     int
+	Reverse    bool
+	DigitsOnly bool
 }
 
 func (m MarkCoverProcesser) DefaultCoverChar() rune {
@@ -28,10 +29,14 @@ func (m *MarkCoverProcesser) Init(maskRule *types.KVMaskConfig) {
 	m.Padding = maskRule.CoverParam.Padding
 	m.Length = maskRule.CoverParam.Length
 	m.Reverse = maskRule.CoverParam.Reverse
+	m.DigitsOnly = maskRule.CoverParam.DigitsOnly
 }
 
 // TODO input should be []byte
 func (m MarkCoverProcesser) Mask(ctx context.Context, in string) (out string, err error) {
+	if m.DigitsOnly {
+		return m.maskDigits(ctx, in)
+	}
 	inRune := []rune(in)
 	inLen := len(inRune)
 	if inLen == 0 {
@@ -72,3 +77,30 @@ func (m MarkCoverProcesser) Mask(ctx context.Context, in string) (out string, er
 	}
 	return string(outRune), nil
 }
+
+// cover digits only, other chars are kept in place
+func (m MarkCoverProcesser) maskDigits(ctx context.Context, in string) (out string, err error) {
+	inRune := []rune(in)
+	digits := make([]rune, 0, len(inRune))
+	for _, r := range inRune {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, r)
+		}
+	}
+	// cover with the full length, keep positions of other chars
+	m.DigitsOnly = false
+	m.Length = 0
+	masked, err := m.Mask(ctx, string(digits))
+	if err != nil {
+		return "", err
+	}
+	maskedRune := []rune(masked)
+	idx := 0
+	for i, r := range inRune {
+		if r >= '0' && r <= '9' {
+			inRune[i] = maskedRune[idx]
+			idx++
+		}
+	}
+	return string(inRune), nil
+}
@@ -209,3 +209,63 @@ func BenchmarkMarkCoverProcesser_Mask(b *testing.B) {
 		m.Mask(ctx, "我能吞下玻璃而不伤身体")
 	}
 }
+
+func TestMarkCoverProcesser_MaskDigits(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  types.KVMaskConfig
+		in      string
+		wantOut string
+	}{
+		{
+			name: "keep decimal point",
+			fields: types.KVMaskConfig{
+				CoverParam: types.MaskRuleCoverParam{
+					Char:       "0",
+					Offset:     1,
+					DigitsOnly: true,
+				},
+			},
+			in:      "-3.14159",
+			wantOut: "-3.00000",
+		},
+		{
+			name: "length ignored",
+			fields: types.KVMaskConfig{
+				CoverParam: types.MaskRuleCoverParam{
+					Char:       "#",
+					Offset:     3,
+					Padding:    4,
+					Length:     1,
+					DigitsOnly: true,
+				},
+			},
+			in:      "+86 138-0013-8000",
+			wantOut: "+86 1##-####-8000",
+		},
+		{
+			name: "no digits",
+			fields: types.KVMaskConfig{
+				CoverParam: types.MaskRuleCoverParam{
+					DigitsOnly: true,
+				},
+			},
+			in:      "abc",
+			wantOut: "abc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := MarkCoverProcesser{}
+			m.Init(&tt.fields)
+			gotOut, err := m.Mask(context.Background(), tt.in)
+			if err != nil {
+				t.Errorf("MarkCoverProcesser.Mask() error = %v", err)
+				return
+			}
+			if gotOut != tt.wantOut {
+				t.Errorf("MarkCoverProcesser.Mask() = %v, want %v", gotOut, tt.wantOut)
+			}
+		})
+	}
+}
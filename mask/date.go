@@ -0,0 +1,176 @@
+package mask
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+var commonDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"2006/01/02",
+	"20060102",
+	"2006-01",
+}
+
+// truncate date to month or year, 1990-05-17 -> 1990-05-01
+type MarkDateProcesser struct {
+	Truncate string
+	Layouts  []string
+}
+
+func (m *MarkDateProcesser) Init(maskRule *types.KVMaskConfig) {
+	m.initChecked(maskRule)
+}
+
+func (m *MarkDateProcesser) initChecked(maskRule *types.KVMaskConfig) error {
+	param := maskRule.DateParam
+	m.Truncate = param.Truncate
+	if m.Truncate == "" {
+		m.Truncate = "month"
+	}
+	m.Layouts = append(append([]string{}, param.Layouts...), commonDateLayouts...)
+	switch m.Truncate {
+	case "year", "month", "day":
+		return nil
+	}
+	return fmt.Errorf("DateParam.Truncate: unknown truncation %q", param.Truncate)
+}
+
+// value in unknown format is covered
+func (m MarkDateProcesser) Mask(ctx context.Context, in string) (out string, err error) {
+	t, layout, err := parseDate(in, m.Layouts)
+	if err != nil {
+		return coverAll(in, MarkCoverProcesser{}.DefaultCoverChar()), nil
+	}
+	return m.truncate(t).Format(layout), nil
+}
+
+func (m MarkDateProcesser) MaskValue(ctx context.Context, kv *types.KVPair) (out interface{}, err error) {
+	num, ok := kv.Val.(json.Number)
+	if !ok {
+		return m.Mask(ctx, kv.GetValString())
+	}
+	t, millis, err := parseTimestamp(num)
+	if err != nil {
+		return m.Mask(ctx, kv.GetValString())
+	}
+	t = m.truncate(t)
+	if millis {
+		return json.Number(strconv.FormatInt(t.UnixMilli(), 10)), nil
+	}
+	return json.Number(strconv.FormatInt(t.Unix(), 10)), nil
+}
+
+func (m MarkDateProcesser) truncate(t time.Time) time.Time {
+	switch m.Truncate {
+	case "year":
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+	case "day":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// parse date by layouts in order, returns the matched layout
+func parseDate(in string, layouts []string) (time.Time, string, error) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, in); err == nil {
+			return t, layout, nil
+		}
+	}
+	return time.Time{}, "", fmt.Errorf("unknown date format %q", in)
+}
+
+// unix timestamp in seconds, or in milliseconds if bigger than 1e11
+func parseTimestamp(num json.Number) (t time.Time, millis bool, err error) {
+	ts, err := num.Int64()
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid timestamp %q", num)
+	}
+	if ts > 1e11 || ts < -1e11 {
+		return time.UnixMilli(ts).UTC(), true, nil
+	}
+	return time.Unix(ts, 0).UTC(), false, nil
+}
+
+// age band of birthdate, "30-39", age in number is masked to lower bound of band
+type MarkAgeBandProcesser struct {
+	Width     int
+	Reference time.Time
+	Layouts   []string
+}
+
+func (m *MarkAgeBandProcesser) Init(maskRule *types.KVMaskConfig) {
+	m.initChecked(maskRule)
+}
+
+func (m *MarkAgeBandProcesser) initChecked(maskRule *types.KVMaskConfig) error {
+	param := maskRule.AgeParam
+	m.Width = param.Width
+	if m.Width <= 0 {
+		m.Width = 10
+	}
+	m.Layouts = append(append([]string{}, param.Layouts...), commonDateLayouts...)
+	m.Reference = time.Time{}
+	if param.Reference != "" {
+		var err error
+		if m.Reference, err = time.Parse("2006-01-02", param.Reference); err != nil {
+			return fmt.Errorf("AgeParam.Reference: %w", err)
+		}
+	}
+	return nil
+}
+
+// value in unknown format is covered
+func (m MarkAgeBandProcesser) Mask(ctx context.Context, in string) (out string, err error) {
+	birth, _, err := parseDate(in, m.Layouts)
+	if err != nil {
+		return coverAll(in, MarkCoverProcesser{}.DefaultCoverChar()), nil
+	}
+	lower := m.lowerBound(age(birth, m.reference()))
+	return fmt.Sprintf("%d-%d", lower, lower+m.Width-1), nil
+}
+
+func (m MarkAgeBandProcesser) MaskValue(ctx context.Context, kv *types.KVPair) (out interface{}, err error) {
+	num, ok := kv.Val.(json.Number)
+	if !ok {
+		return m.Mask(ctx, kv.GetValString())
+	}
+	years, err := num.Float64()
+	if err != nil {
+		return m.Mask(ctx, kv.GetValString())
+	}
+	return json.Number(strconv.Itoa(m.lowerBound(int(years)))), nil
+}
+
+func (m MarkAgeBandProcesser) reference() time.Time {
+	if m.Reference.IsZero() {
+		return time.Now()
+	}
+	return m.Reference
+}
+
+func (m MarkAgeBandProcesser) lowerBound(years int) int {
+	if years < 0 {
+		years = 0
+	}
+	return years / m.Width * m.Width
+}
+
+// full years from birth to ref
+func age(birth, ref time.Time) int {
+	years := ref.Year() - birth.Year()
+	if ref.Month() < birth.Month() || (ref.Month() == birth.Month() && ref.Day() < birth.Day()) {
+		years--
+	}
+	return years
+}
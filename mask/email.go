@@ -0,0 +1,84 @@
+package mask
+
+import (
+	"context"
+	"strings"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+// keep head of local part and domain, a****@example.com
+type MarkEmailProcesser struct {
+	CoverChar   rune
+	KeepLocal   int
+	Length      int
+	CoverDomain bool
+}
+
+func (m *MarkEmailProcesser) Init(maskRule *types.KVMaskConfig) {
+	param := maskRule.EmailParam
+	m.CoverChar = coverChar(param.Char)
+	m.KeepLocal = defaultKeep(param.KeepLocal, 1)
+	m.Length = param.Length
+	m.CoverDomain = param.CoverDomain
+}
+
+func (m MarkEmailProcesser) Mask(ctx context.Context, in string) (out string, err error) {
+	at := strings.LastIndex(in, "@")
+	if at <= 0 || at == len(in)-1 {
+		// not an email, cover whole value
+		return coverAll(in, m.CoverChar), nil
+	}
+	local, domain := []rune(in[:at]), in[at+1:]
+	keep := m.KeepLocal
+	if keep >= len(local) {
+		// at least one char covered
+		keep = len(local) - 1
+	}
+	length := len(local) - keep
+	if m.Length > 0 {
+		length = m.Length
+	}
+	var buf strings.Builder
+	buf.WriteString(string(local[:keep]))
+	buf.WriteString(strings.Repeat(string(m.CoverChar), length))
+	buf.WriteByte('@')
+	if m.CoverDomain {
+		// keep top-level domain only
+		if dot := strings.LastIndex(domain, "."); dot > 0 {
+			buf.WriteString(coverAll(domain[:dot], m.CoverChar))
+			buf.WriteString(domain[dot:])
+		} else {
+			buf.WriteString(coverAll(domain, m.CoverChar))
+		}
+	} else {
+		buf.WriteString(domain)
+	}
+	return buf.String(), nil
+}
+
+func coverChar(char string) rune {
+	if len(char) > 0 {
+		return []rune(char)[0]
+	}
+	return MarkCoverProcesser{}.DefaultCoverChar()
+}
+
+func coverAll(in string, char rune) string {
+	runes := []rune(in)
+	for idx := range runes {
+		runes[idx] = char
+	}
+	return string(runes)
+}
+
+// default value if 0, none if negative
+func defaultKeep(keep, defaultVal int) int {
+	switch {
+	case keep == 0:
+		return defaultVal
+	case keep < 0:
+		return 0
+	}
+	return keep
+}
@@ -0,0 +1,245 @@
+package mask
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"embed"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/senayuki/mosaic/pkg/checksum"
+	"github.com/senayuki/mosaic/types"
+)
+
+//go:embed fakedata/*.txt
+var fakeData embed.FS
+
+// offline dictionaries of fake values, one entry per line
+var (
+	fakeFirstNames     = loadFakeData("first_names.txt")
+	fakeLastNames      = loadFakeData("last_names.txt")
+	fakeCNSurnames     = loadFakeData("cn_surnames.txt")
+	fakeCNGiven        = loadFakeData("cn_given.txt")
+	fakeStreets        = loadFakeData("streets.txt")
+	fakeStreetSuffixes = loadFakeData("street_suffixes.txt")
+	fakeCities         = loadFakeData("cities.txt")
+	fakeCNCities       = loadFakeData("cn_cities.txt")
+	fakeCNDistricts    = loadFakeData("cn_districts.txt")
+	fakeCNRoads        = loadFakeData("cn_roads.txt")
+	fakeEmailDomains   = loadFakeData("email_domains.txt")
+)
+
+func loadFakeData(name string) []string {
+	data, err := fakeData.ReadFile("fakedata/" + name)
+	if err != nil {
+		panic(err)
+	}
+	return strings.Fields(string(data))
+}
+
+// replace value with realistic fake value of the same kind, deterministic with seed
+type MarkFakeProcesser struct {
+	Kind string
+	Seed []byte
+}
+
+// source of random seeds, replaced in tests
+var randRead = rand.Read
+
+func (m *MarkFakeProcesser) Init(maskRule *types.KVMaskConfig) {
+	m.initChecked(maskRule)
+}
+
+func (m *MarkFakeProcesser) initChecked(maskRule *types.KVMaskConfig) error {
+	param := maskRule.FakeParam
+	switch param.Kind {
+	case "", "auto", "name", "email", "phone", "address", "card":
+	default:
+		return fmt.Errorf("FakeParam.Kind: unknown kind %q", param.Kind)
+	}
+	m.Kind = param.Kind
+	if m.Kind == "" {
+		m.Kind = "auto"
+	}
+	m.Seed = []byte(param.Seed)
+	if len(m.Seed) == 0 {
+		m.Seed = make([]byte, 32)
+		if _, err := randRead(m.Seed); err != nil {
+			return fmt.Errorf("FakeParam.Seed: generate random seed: %w", err)
+		}
+	}
+	return nil
+}
+
+func (m MarkFakeProcesser) Mask(ctx context.Context, in string) (out string, err error) {
+	kind := m.Kind
+	if kind == "auto" {
+		kind = guessKind(in)
+	}
+	r := newFakeRand(m.Seed, kind, in)
+	switch kind {
+	case "email":
+		return r.email(), nil
+	case "phone":
+		return r.phone(in), nil
+	case "card":
+		return r.card(in), nil
+	case "address":
+		return r.address(in), nil
+	default:
+		return r.name(in), nil
+	}
+}
+
+func (m MarkFakeProcesser) MaskValue(ctx context.Context, kv *types.KVPair) (out interface{}, err error) {
+	masked, err := m.Mask(ctx, kv.GetValString())
+	if err != nil {
+		return nil, err
+	}
+	return KeepType(kv, masked), nil
+}
+
+// guess kind by value, address is never guessed
+func guessKind(in string) string {
+	if strings.Contains(in, "@") {
+		return "email"
+	}
+	digits := make([]byte, 0, len(in))
+	for _, r := range in {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, byte(r))
+		}
+	}
+	switch {
+	case len(digits) >= 13 && len(digits) <= 19 && checksum.LuhnValid(digits):
+		return "card"
+	case len(digits) >= 7:
+		return "phone"
+	}
+	return "name"
+}
+
+// deterministic random stream of HMAC-SHA256(seed, kind + input + counter)
+type fakeRand struct {
+	key     []byte
+	msg     []byte
+	counter uint32
+	buf     []byte
+}
+
+func newFakeRand(seed []byte, kind, in string) *fakeRand {
+	return &fakeRand{key: seed, msg: []byte(kind + "\x00" + in)}
+}
+
+func (r *fakeRand) uint64() uint64 {
+	if len(r.buf) < 8 {
+		h := hmac.New(sha256.New, r.key)
+		h.Write(r.msg)
+		binary.Write(h, binary.BigEndian, r.counter)
+		r.counter++
+		r.buf = h.Sum(nil)
+	}
+	v := binary.BigEndian.Uint64(r.buf)
+	r.buf = r.buf[8:]
+	return v
+}
+
+func (r *fakeRand) intn(n int) int {
+	return int(r.uint64() % uint64(n))
+}
+
+func (r *fakeRand) pick(dict []string) string {
+	return dict[r.intn(len(dict))]
+}
+
+func (r *fakeRand) digit() byte {
+	return byte('0' + r.intn(10))
+}
+
+// chinese name of the same length, or latin name with the same count of words
+func (r *fakeRand) name(in string) string {
+	runes := []rune(strings.TrimSpace(in))
+	if len(runes) > 0 && unicode.Is(unicode.Han, runes[0]) {
+		given := len(runes) - 1
+		if given < 1 || given > 2 {
+			given = 1 + r.intn(2)
+		}
+		var buf strings.Builder
+		buf.WriteString(r.pick(fakeCNSurnames))
+		for i := 0; i < given; i++ {
+			buf.WriteString(r.pick(fakeCNGiven))
+		}
+		return buf.String()
+	}
+	if len(strings.Fields(in)) == 1 {
+		return r.pick(fakeFirstNames)
+	}
+	return r.pick(fakeFirstNames) + " " + r.pick(fakeLastNames)
+}
+
+// email in reserved example domains
+func (r *fakeRand) email() string {
+	local := strings.ToLower(r.pick(fakeFirstNames) + "." + r.pick(fakeLastNames))
+	return local + string([]byte{r.digit(), r.digit()}) + "@" + r.pick(fakeEmailDomains)
+}
+
+// same format & country code, first digit of national number is kept
+func (r *fakeRand) phone(in string) string {
+	keep := 0
+	if trimmed := strings.TrimLeft(in, " "); strings.HasPrefix(trimmed, "+") {
+		keep = callingCodeLength(trimmed[1:])
+	}
+	keep++
+	out := []byte(in)
+	seen := 0
+	for idx, c := range out {
+		if c < '0' || c > '9' {
+			continue
+		}
+		if seen >= keep {
+			out[idx] = r.digit()
+		}
+		seen++
+	}
+	return string(out)
+}
+
+// same format & first digit (card network), valid check digit by Luhn
+func (r *fakeRand) card(in string) string {
+	out := []byte(in)
+	var positions []int
+	for idx, c := range out {
+		if c >= '0' && c <= '9' {
+			positions = append(positions, idx)
+		}
+	}
+	if len(positions) < 2 {
+		return r.phone(in)
+	}
+	digits := make([]byte, len(positions))
+	digits[0] = out[positions[0]]
+	for i := 1; i < len(digits)-1; i++ {
+		digits[i] = r.digit()
+	}
+	digits[len(digits)-1] = checksum.LuhnCheckDigit(digits[:len(digits)-1])
+	for i, pos := range positions {
+		out[pos] = digits[i]
+	}
+	return string(out)
+}
+
+// chinese address if value is in chinese
+func (r *fakeRand) address(in string) string {
+	for _, c := range in {
+		if unicode.Is(unicode.Han, c) {
+			return r.pick(fakeCNCities) + "市" + r.pick(fakeCNDistricts) + "区" + r.pick(fakeCNRoads) + "路" +
+				strconv.Itoa(1+r.intn(300)) + "号"
+		}
+	}
+	return strconv.Itoa(1+r.intn(9999)) + " " + r.pick(fakeStreets) + " " + r.pick(fakeStreetSuffixes) + ", " + r.pick(fakeCities)
+}
@@ -0,0 +1,127 @@
+package mask
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/senayuki/mosaic/pkg/checksum"
+	"github.com/senayuki/mosaic/types"
+)
+
+func TestMarkFakeProcesser_Mask(t *testing.T) {
+	tests := []struct {
+		name      string
+		kind      string
+		in        string
+		wantMatch string
+	}{
+		{
+			name:      "chinese name",
+			kind:      "name",
+			in:        "张三",
+			wantMatch: `^\p{Han}{2}$`,
+		},
+		{
+			name:      "latin name",
+			in:        "John Smith",
+			wantMatch: `^[A-Z][a-z]+ [A-Z][a-z]+$`,
+		},
+		{
+			name:      "email",
+			in:        "alice@corp.com",
+			wantMatch: `^[a-z]+\.[a-z]+[0-9]{2}@example\.(com|org|net)$`,
+		},
+		{
+			name:      "phone keeps country code & format",
+			in:        "+86 138-0013-8000",
+			wantMatch: `^\+86 1[0-9]{2}-[0-9]{4}-[0-9]{4}$`,
+		},
+		{
+			name:      "card keeps network & format",
+			in:        "4111 1111 1111 1111",
+			wantMatch: `^4[0-9]{3} [0-9]{4} [0-9]{4} [0-9]{4}$`,
+		},
+		{
+			name:      "address",
+			kind:      "address",
+			in:        "1600 Amphitheatre Pkwy, Mountain View",
+			wantMatch: `^[0-9]+ [A-Za-z]+ [A-Za-z]+, [A-Za-z]+$`,
+		},
+		{
+			name:      "chinese address",
+			kind:      "address",
+			in:        "北京市海淀区中关村大街1号",
+			wantMatch: `^\p{Han}+市\p{Han}+区\p{Han}+路[0-9]+号$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := types.KVMaskConfig{
+				MaskType:  types.MaskTypeFake,
+				FakeParam: types.MaskRuleFakeParam{Kind: tt.kind, Seed: "seed"},
+			}
+			m, _ := New(&rule)
+			got, err := m.Mask(context.Background(), tt.in)
+			if err != nil {
+				t.Fatalf("Mask() error = %v", err)
+			}
+			if !regexp.MustCompile(tt.wantMatch).MatchString(got) {
+				t.Errorf("Mask() = %v, want match %v", got, tt.wantMatch)
+			}
+			if got == tt.in {
+				t.Errorf("Mask() = %v, want value changed", got)
+			}
+			// same seed & input, same fake value
+			other, _ := New(&rule)
+			if again, _ := other.Mask(context.Background(), tt.in); again != got {
+				t.Errorf("Mask() = %v again, want %v", again, got)
+			}
+		})
+	}
+}
+
+func TestMarkFakeProcesser_Card(t *testing.T) {
+	m, _ := New(&types.KVMaskConfig{MaskType: types.MaskTypeFake})
+	for _, in := range []string{"4111111111111111", "5500000000000004", "378282246310005"} {
+		got, _ := m.Mask(context.Background(), in)
+		if !checksum.LuhnValid([]byte(got)) || utf8.RuneCountInString(got) != len(in) {
+			t.Errorf("Mask(%v) = %v, want card number valid by Luhn", in, got)
+		}
+	}
+}
+
+func TestMarkFakeProcesser_Seed(t *testing.T) {
+	ctx := context.Background()
+	a, _ := New(&types.KVMaskConfig{MaskType: types.MaskTypeFake, FakeParam: types.MaskRuleFakeParam{Seed: "a"}})
+	b, _ := New(&types.KVMaskConfig{MaskType: types.MaskTypeFake, FakeParam: types.MaskRuleFakeParam{Seed: "b"}})
+	same := 0
+	for _, in := range []string{"Alice Smith", "Bob Jones", "Carol White", "Dave Brown"} {
+		gotA, _ := a.Mask(ctx, in)
+		gotB, _ := b.Mask(ctx, in)
+		if gotA == gotB {
+			same++
+		}
+	}
+	if same == 4 {
+		t.Errorf("Mask() with different seeds got the same values")
+	}
+	got, _ := a.(ValueMasker).MaskValue(ctx, &types.KVPair{Val: json.Number("13800138000")})
+	if _, ok := got.(json.Number); !ok {
+		t.Errorf("MaskValue() = %#v, want json.Number", got)
+	}
+}
+
+func TestMarkFakeProcesser_RandomSeed(t *testing.T) {
+	defer func(read func([]byte) (int, error)) { randRead = read }(randRead)
+	randRead = func([]byte) (int, error) { return 0, errors.New("entropy unavailable") }
+	if _, err := New(&types.KVMaskConfig{MaskType: types.MaskTypeFake}); err == nil {
+		t.Errorf("New() without seed error = nil, want error")
+	}
+	if _, err := New(&types.KVMaskConfig{MaskType: types.MaskTypeFake, FakeParam: types.MaskRuleFakeParam{Seed: "a"}}); err != nil {
+		t.Errorf("New() with seed error = %v", err)
+	}
+}
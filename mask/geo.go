@@ -0,0 +1,91 @@
+package mask
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/senayuki/mosaic/pkg/decimal"
+	"github.com/senayuki/mosaic/types"
+)
+
+// reduce precision of coordinates, 31.230416 -> 31.23, "31.230416,121.473701" -> "31.23,121.47"
+type MarkGeoProcesser struct {
+	Decimals int
+	Geohash  int
+	Axis     string
+}
+
+func (m *MarkGeoProcesser) Init(maskRule *types.KVMaskConfig) {
+	m.initChecked(maskRule)
+}
+
+func (m *MarkGeoProcesser) initChecked(maskRule *types.KVMaskConfig) error {
+	param := maskRule.GeoParam
+	switch param.Axis {
+	case "", "lat", "lon":
+	default:
+		return fmt.Errorf("GeoParam.Axis: unknown axis %q", param.Axis)
+	}
+	m.Decimals = defaultKeep(param.Decimals, 2)
+	m.Geohash = param.Geohash
+	if m.Geohash > 12 {
+		m.Geohash = 12
+	}
+	m.Axis = param.Axis
+	return nil
+}
+
+// value not a coordinate is covered
+func (m MarkGeoProcesser) Mask(ctx context.Context, in string) (out string, err error) {
+	parts := strings.Split(in, ",")
+	if len(parts) > 2 {
+		return coverAll(in, MarkCoverProcesser{}.DefaultCoverChar()), nil
+	}
+	coords := make([]string, len(parts))
+	for idx, part := range parts {
+		var ok bool
+		if coords[idx], ok = m.reduce(strings.TrimSpace(part), idx == 1 || (len(parts) == 1 && m.Axis == "lon")); !ok {
+			return coverAll(in, MarkCoverProcesser{}.DefaultCoverChar()), nil
+		}
+	}
+	return strings.Join(coords, ","), nil
+}
+
+func (m MarkGeoProcesser) MaskValue(ctx context.Context, kv *types.KVPair) (out interface{}, err error) {
+	out, err = m.Mask(ctx, kv.GetValString())
+	if err != nil {
+		return nil, err
+	}
+	return KeepType(kv, out.(string)), nil
+}
+
+/*
+center of geohash cell, or decimal text truncated to decimals toward zero,
+truncated exactly as decimal, 1.15 is kept as 1.15 instead of 1.14 by float arithmetic
+*/
+func (m MarkGeoProcesser) reduce(text string, isLon bool) (string, bool) {
+	num, ok := decimal.ParseRat(text)
+	if !ok {
+		return "", false
+	}
+	if m.Geohash > 0 {
+		coord, _ := num.Float64()
+		// geohash interleaves bits starting with longitude
+		bits := m.Geohash * 5
+		axisBits, lo, hi := bits/2, -90.0, 90.0
+		if isLon {
+			axisBits, lo, hi = (bits+1)/2, -180.0, 180.0
+		}
+		size := (hi - lo) / math.Pow(2, float64(axisBits))
+		cell := math.Floor((coord - lo) / size)
+		return strconv.FormatFloat(lo+cell*size+size/2, 'f', -1, 64), true
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(m.Decimals)), nil)
+	truncated := new(big.Int).Mul(num.Num(), scale)
+	truncated.Quo(truncated, num.Denom())
+	return new(big.Rat).SetFrac(truncated, scale).FloatString(m.Decimals), true
+}
@@ -0,0 +1,38 @@
+package mask
+
+import (
+	"context"
+	"net"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+// keep network prefix of IP, host bits are set to 0, 192.168.1.0, 2001:db8:1::
+type MarkIPProcesser struct {
+	IPv4Prefix int
+	IPv6Prefix int
+}
+
+func (m *MarkIPProcesser) Init(maskRule *types.KVMaskConfig) {
+	param := maskRule.IPParam
+	m.IPv4Prefix = defaultKeep(param.IPv4Prefix, 24)
+	m.IPv6Prefix = defaultKeep(param.IPv6Prefix, 48)
+}
+
+func (m MarkIPProcesser) Mask(ctx context.Context, in string) (out string, err error) {
+	ip := net.ParseIP(in)
+	if ip == nil {
+		return coverAll(in, MarkCoverProcesser{}.DefaultCoverChar()), nil
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(minInt(m.IPv4Prefix, 32), 32)).String(), nil
+	}
+	return ip.Mask(net.CIDRMask(minInt(m.IPv6Prefix, 128), 128)).String(), nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
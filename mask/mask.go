@@ -1 +1,81 @@
 package mask
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+// mask value in string form, numbers are masked by raw text
+type Masker interface {
+	Init(maskRule *types.KVMaskConfig)
+	Mask(ctx context.Context, in string) (out string, err error)
+}
+
+// create masker by MaskType of rule
+func New(maskRule *types.KVMaskConfig) (Masker, error) {
+	var m Masker
+	switch maskRule.MaskType {
+	case types.MaskTypeCover:
+		m = &MarkCoverProcesser{}
+	case types.MaskTypeDrop:
+		m = &MarkDropProcesser{}
+	case types.MaskTypeNull:
+		m = &MarkNullProcesser{}
+	case types.MaskTypeConst:
+		m = &MarkConstProcesser{}
+	case types.MaskTypeDefault:
+		m = &MarkDefaultProcesser{}
+	case types.MaskTypeEmail:
+		m = &MarkEmailProcesser{}
+	case types.MaskTypePhone:
+		m = &MarkPhoneProcesser{}
+	case types.MaskTypeCard:
+		m = &MarkCardProcesser{}
+	case types.MaskTypeIP:
+		m = &MarkIPProcesser{}
+	case types.MaskTypeName:
+		m = &MarkNameProcesser{}
+	case types.MaskTypeDate:
+		m = &MarkDateProcesser{}
+	case types.MaskTypeAgeBand:
+		m = &MarkAgeBandProcesser{}
+	case types.MaskTypeRound:
+		m = &MarkRoundProcesser{}
+	case types.MaskTypeGeo:
+		m = &MarkGeoProcesser{}
+	case types.MaskTypePostcode:
+		m = &MarkPostcodeProcesser{}
+	case types.MaskTypeFake:
+		m = &MarkFakeProcesser{}
+	default:
+		return nil, fmt.Errorf("unknown mask type %q", maskRule.MaskType)
+	}
+	if c, ok := m.(checkedMasker); ok {
+		if err := c.initChecked(maskRule); err != nil {
+			return nil, fmt.Errorf("%s: %w", maskRule.MaskType, err)
+		}
+		return m, nil
+	}
+	m.Init(maskRule)
+	return m, nil
+}
+
+// masker with params that can be invalid, initialized by initChecked instead of Init in New
+type checkedMasker interface {
+	Masker
+	initChecked(maskRule *types.KVMaskConfig) error
+}
+
+var jsonNumberExp = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// masked text in number if value is number and masked text is still a number, in string otherwise
+func KeepType(kv *types.KVPair, out string) interface{} {
+	if _, isNum := kv.Val.(json.Number); isNum && jsonNumberExp.MatchString(out) {
+		return json.Number(out)
+	}
+	return out
+}
@@ -0,0 +1,375 @@
+package mask
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+func TestNew_Mask(t *testing.T) {
+	tests := []struct {
+		name     string
+		maskRule types.KVMaskConfig
+		in       string
+		wantOut  string
+		wantErr  bool
+	}{
+		{
+			name:     "email",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeEmail},
+			in:       "alice@example.com",
+			wantOut:  "a****@example.com",
+		},
+		{
+			name: "email fixed length & cover domain",
+			maskRule: types.KVMaskConfig{
+				MaskType:   types.MaskTypeEmail,
+				EmailParam: types.MaskRuleEmailParam{KeepLocal: 2, Length: 3, CoverDomain: true},
+			},
+			in:      "alice@mail.example.com",
+			wantOut: "al***@************.com",
+		},
+		{
+			name:     "email single char local part",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeEmail},
+			in:       "a@example.com",
+			wantOut:  "*@example.com",
+		},
+		{
+			name:     "not an email",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeEmail},
+			in:       "alice",
+			wantOut:  "*****",
+		},
+		{
+			name:     "phone with country code",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypePhone},
+			in:       "+86 138-0013-8000",
+			wantOut:  "+86 ***-****-8000",
+		},
+		{
+			name:     "phone with 3-digit country code",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypePhone},
+			in:       "+85291234567",
+			wantOut:  "+852****4567",
+		},
+		{
+			name:     "phone with 1-digit country code",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypePhone},
+			in:       "+14155552671",
+			wantOut:  "+1******2671",
+		},
+		{
+			name: "phone cover country code",
+			maskRule: types.KVMaskConfig{
+				MaskType:   types.MaskTypePhone,
+				PhoneParam: types.MaskRulePhoneParam{KeepLast: 2, CoverCountryCode: true},
+			},
+			in:      "+8613800138000",
+			wantOut: "+***********00",
+		},
+		{
+			name:     "phone without country code",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypePhone},
+			in:       "13800138000",
+			wantOut:  "*******8000",
+		},
+		{
+			name:     "card",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeCard},
+			in:       "4111 1111 1111 1111",
+			wantOut:  "4111 11** **** 1111",
+		},
+		{
+			name: "card 8-digit BIN",
+			maskRule: types.KVMaskConfig{
+				MaskType:  types.MaskTypeCard,
+				CardParam: types.MaskRuleCardParam{BINLength: 8, Char: "X"},
+			},
+			in:      "5500000000000004",
+			wantOut: "55000000XXXX0004",
+		},
+		{
+			name:     "card too short",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeCard},
+			in:       "123456789",
+			wantOut:  "*****6789",
+		},
+		{
+			name:     "card of 4 digits or less",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeCard},
+			in:       "12-3",
+			wantOut:  "**-*",
+		},
+		{
+			name:     "IPv4",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeIP},
+			in:       "192.168.1.123",
+			wantOut:  "192.168.1.0",
+		},
+		{
+			name:     "IPv6",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeIP},
+			in:       "2001:db8:1234:5678::1",
+			wantOut:  "2001:db8:1234::",
+		},
+		{
+			name: "IPv4 custom prefix",
+			maskRule: types.KVMaskConfig{
+				MaskType: types.MaskTypeIP,
+				IPParam:  types.MaskRuleIPParam{IPv4Prefix: 16},
+			},
+			in:      "10.20.30.40",
+			wantOut: "10.20.0.0",
+		},
+		{
+			name:     "chinese name",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeName},
+			in:       "张三丰",
+			wantOut:  "张**",
+		},
+		{
+			name:     "chinese compound surname",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeName},
+			in:       "欧阳娜娜",
+			wantOut:  "欧阳**",
+		},
+		{
+			name:     "latin name",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeName},
+			in:       "John Smith",
+			wantOut:  "J*** S****",
+		},
+		{
+			name:     "unknown mask type",
+			maskRule: types.KVMaskConfig{MaskType: "unknown"},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid age reference",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeAgeBand, AgeParam: types.MaskRuleAgeParam{Reference: "2024/01/01"}},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown date truncation",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeDate, DateParam: types.MaskRuleDateParam{Truncate: "week"}},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown round mode",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeRound, RoundParam: types.MaskRuleRoundParam{Mode: "trunc"}},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown geo axis",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeGeo, GeoParam: types.MaskRuleGeoParam{Axis: "longitude"}},
+			wantErr:  true,
+		},
+		{
+			name:     "const value not marshalled",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeConst, ConstParam: types.MaskRuleConstParam{Value: make(chan int)}},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown fake kind",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeFake, FakeParam: types.MaskRuleFakeParam{Kind: "ssn"}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := New(&tt.maskRule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			gotOut, err := m.Mask(context.Background(), tt.in)
+			if err != nil {
+				t.Errorf("Mask() error = %v", err)
+				return
+			}
+			if gotOut != tt.wantOut {
+				t.Errorf("Mask() = %v, want %v", gotOut, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestNew_MaskValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		maskRule types.KVMaskConfig
+		val      interface{}
+		wantOut  interface{}
+	}{
+		{
+			name:     "date to month",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeDate},
+			val:      "1990-05-17",
+			wantOut:  "1990-05-01",
+		},
+		{
+			name: "datetime to year",
+			maskRule: types.KVMaskConfig{
+				MaskType:  types.MaskTypeDate,
+				DateParam: types.MaskRuleDateParam{Truncate: "year"},
+			},
+			val:     "2023-08-09T10:11:12+08:00",
+			wantOut: "2023-01-01T00:00:00+08:00",
+		},
+		{
+			name: "date in custom layout",
+			maskRule: types.KVMaskConfig{
+				MaskType:  types.MaskTypeDate,
+				DateParam: types.MaskRuleDateParam{Layouts: []string{"02.01.2006"}},
+			},
+			val:     "17.05.1990",
+			wantOut: "01.05.1990",
+		},
+		{
+			name:     "timestamp in milliseconds",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeDate},
+			val:      json.Number("1691575872000"),
+			wantOut:  json.Number("1690848000000"),
+		},
+		{
+			name:     "unknown date format",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeDate},
+			val:      "yesterday",
+			wantOut:  "*********",
+		},
+		{
+			name: "birthdate to age band",
+			maskRule: types.KVMaskConfig{
+				MaskType: types.MaskTypeAgeBand,
+				AgeParam: types.MaskRuleAgeParam{Reference: "2024-05-16"},
+			},
+			val:     "1990-05-17",
+			wantOut: "30-39",
+		},
+		{
+			name: "age to lower bound",
+			maskRule: types.KVMaskConfig{
+				MaskType: types.MaskTypeAgeBand,
+				AgeParam: types.MaskRuleAgeParam{Width: 5},
+			},
+			val:     json.Number("34"),
+			wantOut: json.Number("30"),
+		},
+		{
+			name: "round salary",
+			maskRule: types.KVMaskConfig{
+				MaskType:   types.MaskTypeRound,
+				RoundParam: types.MaskRuleRoundParam{Step: 1000},
+			},
+			val:     json.Number("12545.67"),
+			wantOut: json.Number("13000"),
+		},
+		{
+			name: "floor decimals in string",
+			maskRule: types.KVMaskConfig{
+				MaskType:   types.MaskTypeRound,
+				RoundParam: types.MaskRuleRoundParam{Step: 0.05, Mode: "floor"},
+			},
+			val:     "-3.14159",
+			wantOut: "-3.15",
+		},
+		{
+			name: "bucket",
+			maskRule: types.KVMaskConfig{
+				MaskType:   types.MaskTypeRound,
+				RoundParam: types.MaskRuleRoundParam{Buckets: []float64{0, 5000, 10000, 50000}},
+			},
+			val:     json.Number("12345"),
+			wantOut: json.Number("10000"),
+		},
+		{
+			name:     "round huge exponent",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeRound},
+			val:      json.Number("1e999999"),
+			wantOut:  "********",
+		},
+		{
+			name:     "geo huge exponent",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeGeo},
+			val:      "1e999999,1",
+			wantOut:  "**********",
+		},
+		{
+			name:     "geo decimals",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeGeo},
+			val:      json.Number("121.473701"),
+			wantOut:  json.Number("121.47"),
+		},
+		{
+			name:     "geo decimals truncated exactly",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeGeo},
+			val:      json.Number("1.15"),
+			wantOut:  json.Number("1.15"),
+		},
+		{
+			name:     "geo negative decimals truncated toward zero",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeGeo},
+			val:      "-0.29,1.159",
+			wantOut:  "-0.29,1.15",
+		},
+		{
+			name:     "geo pair in string",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypeGeo},
+			val:      "31.230416, 121.473701",
+			wantOut:  "31.23,121.47",
+		},
+		{
+			name: "geohash cell center",
+			maskRule: types.KVMaskConfig{
+				MaskType: types.MaskTypeGeo,
+				GeoParam: types.MaskRuleGeoParam{Geohash: 1},
+			},
+			val:     "31.230416,121.473701",
+			wantOut: "22.5,112.5",
+		},
+		{
+			name:     "postcode",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypePostcode},
+			val:      json.Number("100080"),
+			wantOut:  json.Number("100"),
+		},
+		{
+			name: "postcode covered",
+			maskRule: types.KVMaskConfig{
+				MaskType:  types.MaskTypePostcode,
+				PostParam: types.MaskRulePostcodeParam{Char: "*"},
+			},
+			val:     "SW1A 1AA",
+			wantOut: "SW1* ***",
+		},
+		{
+			name:     "postcode with leading zero",
+			maskRule: types.KVMaskConfig{MaskType: types.MaskTypePostcode},
+			val:      json.Number("2100"),
+			wantOut:  json.Number("210"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := New(&tt.maskRule)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			gotOut, err := m.(ValueMasker).MaskValue(context.Background(), &types.KVPair{Val: tt.val})
+			if err != nil {
+				t.Errorf("MaskValue() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(gotOut, tt.wantOut) {
+				t.Errorf("MaskValue() = %#v, want %#v", gotOut, tt.wantOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package mask
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+// keep surname of chinese names, or head of each word in latin script, 张*, J*** S****
+type MarkNameProcesser struct {
+	CoverChar rune
+	KeepFirst int
+}
+
+// compound surnames, longest match is used
+var compoundSurnames = map[string]struct{}{
+	"欧阳": {}, "太史": {}, "端木": {}, "上官": {}, "司马": {}, "东方": {}, "独孤": {}, "南宫": {},
+	"万俟": {}, "闻人": {}, "夏侯": {}, "诸葛": {}, "尉迟": {}, "公羊": {}, "赫连": {}, "澹台": {},
+	"皇甫": {}, "宗政": {}, "濮阳": {}, "公冶": {}, "太叔": {}, "申屠": {}, "公孙": {}, "慕容": {},
+	"仲孙": {}, "钟离": {}, "长孙": {}, "宇文": {}, "司徒": {}, "鲜于": {}, "司空": {}, "闾丘": {},
+	"子车": {}, "亓官": {}, "司寇": {}, "巫马": {}, "公西": {}, "颛孙": {}, "壤驷": {}, "公良": {},
+	"漆雕": {}, "乐正": {}, "宰父": {}, "谷梁": {}, "拓跋": {}, "夹谷": {}, "轩辕": {}, "令狐": {},
+	"段干": {}, "百里": {}, "呼延": {}, "东郭": {}, "南门": {}, "羊舌": {}, "微生": {}, "公户": {},
+	"梁丘": {}, "左丘": {}, "东门": {}, "西门": {}, "第五": {},
+}
+
+func (m *MarkNameProcesser) Init(maskRule *types.KVMaskConfig) {
+	param := maskRule.NameParam
+	m.CoverChar = coverChar(param.Char)
+	m.KeepFirst = defaultKeep(param.KeepFirst, 1)
+}
+
+func (m MarkNameProcesser) Mask(ctx context.Context, in string) (out string, err error) {
+	runes := []rune(strings.TrimSpace(in))
+	if len(runes) == 0 {
+		return in, nil
+	}
+	if unicode.Is(unicode.Han, runes[0]) {
+		return m.maskHan(runes), nil
+	}
+	words := strings.Fields(in)
+	for idx, word := range words {
+		wordRunes := []rune(word)
+		keep := m.KeepFirst
+		if keep >= len(wordRunes) {
+			keep = len(wordRunes) - 1
+		}
+		for i := keep; i < len(wordRunes); i++ {
+			wordRunes[i] = m.CoverChar
+		}
+		words[idx] = string(wordRunes)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// surname is kept, given name is covered, at least one char covered
+func (m MarkNameProcesser) maskHan(runes []rune) string {
+	keep := 1
+	if len(runes) > 2 {
+		if _, ok := compoundSurnames[string(runes[:2])]; ok {
+			keep = 2
+		}
+	}
+	if keep >= len(runes) {
+		keep = len(runes) - 1
+	}
+	out := make([]rune, len(runes))
+	copy(out, runes[:keep])
+	for i := keep; i < len(runes); i++ {
+		out[i] = m.CoverChar
+	}
+	return string(out)
+}
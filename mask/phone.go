@@ -0,0 +1,84 @@
+package mask
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+// keep country code and last digits, separators are kept, +86 138****8000
+type MarkPhoneProcesser struct {
+	CoverChar        rune
+	KeepLast         int
+	CoverCountryCode bool
+}
+
+// 2-digit country calling codes, 1 & 7 are 1-digit, others are 3-digit
+// calling codes are prefix-free so the length can be decided by leading digits
+var twoDigitCallingCodes = map[string]struct{}{
+	"20": {}, "27": {}, "30": {}, "31": {}, "32": {}, "33": {}, "34": {}, "36": {}, "39": {},
+	"40": {}, "41": {}, "43": {}, "44": {}, "45": {}, "46": {}, "47": {}, "48": {}, "49": {},
+	"51": {}, "52": {}, "53": {}, "54": {}, "55": {}, "56": {}, "57": {}, "58": {},
+	"60": {}, "61": {}, "62": {}, "63": {}, "64": {}, "65": {}, "66": {},
+	"81": {}, "82": {}, "84": {}, "86": {},
+	"90": {}, "91": {}, "92": {}, "93": {}, "94": {}, "95": {}, "98": {},
+}
+
+func (m *MarkPhoneProcesser) Init(maskRule *types.KVMaskConfig) {
+	param := maskRule.PhoneParam
+	m.CoverChar = coverChar(param.Char)
+	m.KeepLast = defaultKeep(param.KeepLast, 4)
+	m.CoverCountryCode = param.CoverCountryCode
+}
+
+func (m MarkPhoneProcesser) Mask(ctx context.Context, in string) (out string, err error) {
+	digits := 0
+	for _, r := range in {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+	if digits == 0 {
+		return coverAll(in, m.CoverChar), nil
+	}
+	keepFirst := 0
+	if trimmed := strings.TrimLeft(in, " "); strings.HasPrefix(trimmed, "+") && !m.CoverCountryCode {
+		keepFirst = callingCodeLength(trimmed[1:])
+	}
+	cover := MarkCoverProcesser{
+		CoverChar:  m.CoverChar,
+		Offset:     keepFirst,
+		Padding:    m.KeepLast,
+		DigitsOnly: true,
+	}
+	return cover.Mask(ctx, in)
+}
+
+// length of country calling code at the head of digits,
+// separator right after leading digits ends the code, e.g. "852 1234 5678"
+func callingCodeLength(in string) int {
+	lead := 0
+	for lead < len(in) && in[lead] >= '0' && in[lead] <= '9' {
+		lead++
+	}
+	if lead == 0 {
+		return 0
+	}
+	if lead < len(in) && lead <= 3 {
+		return lead
+	}
+	switch {
+	case in[0] == '1' || in[0] == '7':
+		return 1
+	case lead >= 2:
+		if _, ok := twoDigitCallingCodes[in[:2]]; ok {
+			return 2
+		}
+	}
+	if lead < 3 {
+		return lead
+	}
+	return 3
+}
@@ -0,0 +1,47 @@
+package mask
+
+import (
+	"context"
+	"strings"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+// keep prefix of postal code, 100080 -> 100, SW1A 1AA -> SW1
+type MarkPostcodeProcesser struct {
+	Keep      int
+	CoverChar rune
+}
+
+func (m *MarkPostcodeProcesser) Init(maskRule *types.KVMaskConfig) {
+	param := maskRule.PostParam
+	m.Keep = defaultKeep(param.Keep, 3)
+	m.CoverChar = 0
+	if len(param.Char) > 0 {
+		m.CoverChar = []rune(param.Char)[0]
+	}
+}
+
+func (m MarkPostcodeProcesser) Mask(ctx context.Context, in string) (out string, err error) {
+	runes := []rune(strings.TrimSpace(in))
+	if len(runes) <= m.Keep {
+		return string(runes), nil
+	}
+	if m.CoverChar == 0 {
+		return strings.TrimSpace(string(runes[:m.Keep])), nil
+	}
+	for idx := m.Keep; idx < len(runes); idx++ {
+		if runes[idx] != ' ' && runes[idx] != '-' {
+			runes[idx] = m.CoverChar
+		}
+	}
+	return string(runes), nil
+}
+
+func (m MarkPostcodeProcesser) MaskValue(ctx context.Context, kv *types.KVPair) (out interface{}, err error) {
+	masked, err := m.Mask(ctx, kv.GetValString())
+	if err != nil {
+		return nil, err
+	}
+	return KeepType(kv, masked), nil
+}
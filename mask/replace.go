@@ -0,0 +1,114 @@
+package mask
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+// mask value of pair into any JSON value instead of string form
+// result is string, json.Number, json.RawMessage, bool, nil or types.KVDropped
+type ValueMasker interface {
+	MaskValue(ctx context.Context, kv *types.KVPair) (out interface{}, err error)
+}
+
+const DefaultConstValue = "[REDACTED]"
+
+// remove field or array element
+type MarkDropProcesser struct {
+	KeepArrayIndex bool
+}
+
+func (m *MarkDropProcesser) Init(maskRule *types.KVMaskConfig) {
+	m.KeepArrayIndex = maskRule.DropParam.KeepArrayIndex
+}
+
+func (m MarkDropProcesser) Mask(ctx context.Context, in string) (out string, err error) {
+	return "", nil
+}
+
+func (m MarkDropProcesser) MaskValue(ctx context.Context, kv *types.KVPair) (out interface{}, err error) {
+	if m.KeepArrayIndex && !kv.ObjectKey {
+		path := kv.ValJSONPath.Elements()
+		if len(path) > 0 {
+			if _, isIndex := path[len(path)-1].(int); isIndex {
+				return nil, nil
+			}
+		}
+	}
+	return types.KVDropped{}, nil
+}
+
+// set to null
+type MarkNullProcesser struct{}
+
+func (m *MarkNullProcesser) Init(maskRule *types.KVMaskConfig) {}
+
+func (m MarkNullProcesser) Mask(ctx context.Context, in string) (out string, err error) {
+	return "null", nil
+}
+
+func (m MarkNullProcesser) MaskValue(ctx context.Context, kv *types.KVPair) (out interface{}, err error) {
+	return nil, nil
+}
+
+// replace with constant value
+type MarkConstProcesser struct {
+	Value interface{} // string or json.RawMessage
+}
+
+// value not marshalled to JSON is replaced by DefaultConstValue
+func (m *MarkConstProcesser) Init(maskRule *types.KVMaskConfig) {
+	m.initChecked(maskRule)
+}
+
+func (m *MarkConstProcesser) initChecked(maskRule *types.KVMaskConfig) error {
+	switch val := maskRule.ConstParam.Value.(type) {
+	case nil:
+		m.Value = DefaultConstValue
+	case string:
+		m.Value = val
+	default:
+		raw, err := json.Marshal(val)
+		if err != nil {
+			m.Value = DefaultConstValue
+			return fmt.Errorf("ConstParam.Value: %w", err)
+		}
+		m.Value = json.RawMessage(raw)
+	}
+	return nil
+}
+
+func (m MarkConstProcesser) Mask(ctx context.Context, in string) (out string, err error) {
+	if val, ok := m.Value.(string); ok {
+		return val, nil
+	}
+	return string(m.Value.(json.RawMessage)), nil
+}
+
+func (m MarkConstProcesser) MaskValue(ctx context.Context, kv *types.KVPair) (out interface{}, err error) {
+	return m.Value, nil
+}
+
+// replace with default value of the same type
+type MarkDefaultProcesser struct{}
+
+func (m *MarkDefaultProcesser) Init(maskRule *types.KVMaskConfig) {}
+
+func (m MarkDefaultProcesser) Mask(ctx context.Context, in string) (out string, err error) {
+	return "", nil
+}
+
+func (m MarkDefaultProcesser) MaskValue(ctx context.Context, kv *types.KVPair) (out interface{}, err error) {
+	switch kv.GetValType() {
+	case types.KVValTypeString:
+		return "", nil
+	case types.KVValTypeNumber:
+		return json.Number("0"), nil
+	case types.KVValTypeBool:
+		return false, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,84 @@
+package mask
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+func TestValueMasker_MaskValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  types.KVMaskConfig
+		kv      types.KVPair
+		wantOut interface{}
+	}{
+		{
+			name:    "drop",
+			fields:  types.KVMaskConfig{MaskType: types.MaskTypeDrop},
+			kv:      types.KVPair{Val: "x", ValJSONPath: types.NewJSONPath().Append("list", 0)},
+			wantOut: types.KVDropped{},
+		},
+		{
+			name: "drop keep array index",
+			fields: types.KVMaskConfig{
+				MaskType:  types.MaskTypeDrop,
+				DropParam: types.MaskRuleDropParam{KeepArrayIndex: true},
+			},
+			kv:      types.KVPair{Val: "x", ValJSONPath: types.NewJSONPath().Append("list", 0)},
+			wantOut: nil,
+		},
+		{
+			name:    "null",
+			fields:  types.KVMaskConfig{MaskType: types.MaskTypeNull},
+			kv:      types.KVPair{Val: "x"},
+			wantOut: nil,
+		},
+		{
+			name:    "const default",
+			fields:  types.KVMaskConfig{MaskType: types.MaskTypeConst},
+			kv:      types.KVPair{Val: json.Number("1")},
+			wantOut: DefaultConstValue,
+		},
+		{
+			name: "const object",
+			fields: types.KVMaskConfig{
+				MaskType:   types.MaskTypeConst,
+				ConstParam: types.MaskRuleConstParam{Value: map[string]interface{}{"masked": true}},
+			},
+			kv:      types.KVPair{Val: "x"},
+			wantOut: json.RawMessage(`{"masked":true}`),
+		},
+		{
+			name:    "default number",
+			fields:  types.KVMaskConfig{MaskType: types.MaskTypeDefault},
+			kv:      types.KVPair{Val: json.Number("3.14")},
+			wantOut: json.Number("0"),
+		},
+		{
+			name:    "default bool",
+			fields:  types.KVMaskConfig{MaskType: types.MaskTypeDefault},
+			kv:      types.KVPair{Val: true},
+			wantOut: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := New(&tt.fields)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			gotOut, err := m.(ValueMasker).MaskValue(context.Background(), &tt.kv)
+			if err != nil {
+				t.Errorf("MaskValue() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(gotOut, tt.wantOut) {
+				t.Errorf("MaskValue() = %v, want %v", gotOut, tt.wantOut)
+			}
+		})
+	}
+}
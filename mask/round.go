@@ -0,0 +1,123 @@
+package mask
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/senayuki/mosaic/pkg/decimal"
+	"github.com/senayuki/mosaic/types"
+)
+
+// round number to multiple of step, or to lower bound of bucket, 12345.67 -> 12000
+type MarkRoundProcesser struct {
+	Step    *big.Rat
+	Mode    string
+	Buckets []*big.Rat
+	// decimal places of output
+	decimals int
+}
+
+func (m *MarkRoundProcesser) Init(maskRule *types.KVMaskConfig) {
+	m.initChecked(maskRule)
+}
+
+func (m *MarkRoundProcesser) initChecked(maskRule *types.KVMaskConfig) error {
+	param := maskRule.RoundParam
+	switch param.Mode {
+	case "", "round", "floor", "ceil":
+	default:
+		return fmt.Errorf("RoundParam.Mode: unknown mode %q", param.Mode)
+	}
+	m.Mode = param.Mode
+	step := param.Step
+	if step <= 0 {
+		step = 1
+	}
+	m.Step, m.decimals = ratOfFloat(step)
+	m.Buckets = nil
+	for _, bound := range param.Buckets {
+		rat, decimals := ratOfFloat(bound)
+		m.Buckets = append(m.Buckets, rat)
+		if decimals > m.decimals {
+			m.decimals = decimals
+		}
+	}
+	sort.Slice(m.Buckets, func(i, j int) bool {
+		return m.Buckets[i].Cmp(m.Buckets[j]) < 0
+	})
+	return nil
+}
+
+// exact rational of float in shortest decimal form, with count of decimal places
+func ratOfFloat(f float64) (*big.Rat, int) {
+	text := strconv.FormatFloat(f, 'f', -1, 64)
+	rat, _ := decimal.ParseRat(text)
+	decimals := 0
+	if dot := strings.IndexByte(text, '.'); dot >= 0 {
+		decimals = len(text) - dot - 1
+	}
+	return rat, decimals
+}
+
+// value not a number is covered
+func (m MarkRoundProcesser) Mask(ctx context.Context, in string) (out string, err error) {
+	num, ok := decimal.ParseRat(strings.TrimSpace(in))
+	if !ok {
+		return coverAll(in, MarkCoverProcesser{}.DefaultCoverChar()), nil
+	}
+	if len(m.Buckets) > 0 {
+		return m.format(m.bucket(num)), nil
+	}
+	// round(num / step) * step
+	q := new(big.Rat).Quo(num, m.Step)
+	n := new(big.Int).Quo(q.Num(), q.Denom())
+	r := new(big.Rat).Sub(q, new(big.Rat).SetInt(n))
+	switch m.Mode {
+	case "floor":
+		if r.Sign() < 0 {
+			n.Sub(n, big.NewInt(1))
+		}
+	case "ceil":
+		if r.Sign() > 0 {
+			n.Add(n, big.NewInt(1))
+		}
+	default:
+		half := big.NewRat(1, 2)
+		if r.Cmp(half) >= 0 {
+			n.Add(n, big.NewInt(1))
+		} else if r.Cmp(new(big.Rat).Neg(half)) <= 0 {
+			n.Sub(n, big.NewInt(1))
+		}
+	}
+	return m.format(new(big.Rat).Mul(new(big.Rat).SetInt(n), m.Step)), nil
+}
+
+func (m MarkRoundProcesser) MaskValue(ctx context.Context, kv *types.KVPair) (out interface{}, err error) {
+	out, err = m.Mask(ctx, kv.GetValString())
+	if err != nil {
+		return nil, err
+	}
+	return KeepType(kv, out.(string)), nil
+}
+
+func (m MarkRoundProcesser) bucket(num *big.Rat) *big.Rat {
+	lower := m.Buckets[0]
+	for _, bound := range m.Buckets {
+		if num.Cmp(bound) < 0 {
+			break
+		}
+		lower = bound
+	}
+	return lower
+}
+
+func (m MarkRoundProcesser) format(num *big.Rat) string {
+	if num.IsInt() {
+		return num.Num().String()
+	}
+	return num.FloatString(m.decimals)
+}
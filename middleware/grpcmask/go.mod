@@ -0,0 +1,24 @@
+module github.com/senayuki/mosaic/middleware/grpcmask
+
+go 1.19
+
+replace (
+	github.com/senayuki/mosaic => ../..
+	github.com/senayuki/mosaic/grpc => ../../grpc
+)
+
+require (
+	github.com/senayuki/mosaic v0.0.0-00010101000000-000000000000
+	github.com/senayuki/mosaic/grpc v0.0.0-00010101000000-000000000000
+	google.golang.org/grpc v1.60.1
+	google.golang.org/protobuf v1.32.0
+)
+
+require (
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/valyala/fastjson v1.6.4 // indirect
+	golang.org/x/net v0.16.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20231002182017-d307bd883b97 // indirect
+)
@@ -0,0 +1,135 @@
+// gRPC interceptors masking protobuf messages by processer
+package grpcmask
+
+import (
+	"context"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type Direction string
+
+const (
+	DirectionRequest  Direction = "request"
+	DirectionResponse Direction = "response"
+)
+
+type MessageInfo struct {
+	FullMethod string
+	Direction  Direction
+}
+
+type Config struct {
+	/*keys & paths use field names in proto, e.g. phone_number
+	by default lowerCamelCase JSON names of protojson are used, e.g. phoneNumber
+	*/
+	UseProtoNames bool
+	MaskRequest   bool // replace request received by masked copy before it reaches handler
+	MaskResponse  bool // replace response by masked copy before it is sent
+	// called with masked copy of each request & response, e.g. for logging, input message is not changed
+	OnMessage func(ctx context.Context, info MessageInfo, masked proto.Message, pairs []types.KVPair)
+}
+
+type Interceptor struct {
+	m      processer.KVProcesser
+	config Config
+}
+
+func New(m processer.KVProcesser, config Config) *Interceptor {
+	return &Interceptor{m: m, config: config}
+}
+
+// server interceptor of unary calls
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		req, err := i.intercept(ctx, MessageInfo{FullMethod: info.FullMethod, Direction: DirectionRequest}, req, i.config.MaskRequest)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+		return i.intercept(ctx, MessageInfo{FullMethod: info.FullMethod, Direction: DirectionResponse}, resp, i.config.MaskResponse)
+	}
+}
+
+// server interceptor of streams, each message received or sent is intercepted
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, i: i, fullMethod: info.FullMethod})
+	}
+}
+
+// mask copy of message, returns masked copy & masked pairs
+func (i *Interceptor) MaskMessage(ctx context.Context, msg proto.Message) (proto.Message, []types.KVPair, error) {
+	masked := proto.Clone(msg)
+	w := walker{ctx: ctx, m: i.m, useProtoNames: i.config.UseProtoNames}
+	drop, err := w.messageValue(masked.ProtoReflect(), types.NewJSONPath(), "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if drop {
+		// dropped root is empty message
+		proto.Reset(masked)
+	}
+	return masked, w.pairs, nil
+}
+
+// mask copy of message for callback, returns masked copy if replace, or message as is
+func (i *Interceptor) intercept(ctx context.Context, info MessageInfo, msg interface{}, replace bool) (interface{}, error) {
+	pm, ok := msg.(proto.Message)
+	if !ok || (!replace && i.config.OnMessage == nil) {
+		return msg, nil
+	}
+	masked, pairs, err := i.MaskMessage(ctx, pm)
+	if err != nil {
+		if replace {
+			return nil, status.Errorf(codes.Internal, "mask %s: %v", info.Direction, err)
+		}
+		// message is not logged rather than failing the call
+		return msg, nil
+	}
+	if i.config.OnMessage != nil {
+		i.config.OnMessage(ctx, info, masked, pairs)
+	}
+	if replace {
+		return masked, nil
+	}
+	return msg, nil
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	i          *Interceptor
+	fullMethod string
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	masked, err := s.i.intercept(s.Context(), MessageInfo{FullMethod: s.fullMethod, Direction: DirectionRequest}, m, s.i.config.MaskRequest)
+	if err != nil {
+		return err
+	}
+	if masked != m {
+		// message received is filled by caller, overwrite it by masked copy
+		proto.Reset(m.(proto.Message))
+		proto.Merge(m.(proto.Message), masked.(proto.Message))
+	}
+	return nil
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	masked, err := s.i.intercept(s.Context(), MessageInfo{FullMethod: s.fullMethod, Direction: DirectionResponse}, m, s.i.config.MaskResponse)
+	if err != nil {
+		return err
+	}
+	return s.ServerStream.SendMsg(masked)
+}
@@ -0,0 +1,200 @@
+package grpcmask
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/senayuki/mosaic/grpc/client"
+	"github.com/senayuki/mosaic/grpc/mosaicpb"
+	"github.com/senayuki/mosaic/grpc/server"
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func mustStruct(t *testing.T, s string) *structpb.Struct {
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	st, err := structpb.NewStruct(m)
+	if err != nil {
+		t.Fatalf("structpb.NewStruct() error = %v", err)
+	}
+	return st
+}
+
+func TestInterceptor_MaskMessage(t *testing.T) {
+	rule := types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{KeyEqs: []string{"val", "phone", "age", "value"}},
+			{KeyEqs: []string{"valJsonPath", "val_json_path"}, MaskRef: "null"},
+			{ValEqs: []string{"secret"}, MaskRef: "drop"},
+			{ValRegex: []string{`^12345$`}},
+		},
+		MaskRules: []types.KVMaskConfig{
+			{RuleName: "null", MaskType: types.MaskTypeNull},
+			{RuleName: "drop", MaskType: types.MaskTypeDrop},
+		},
+	}
+	tests := []struct {
+		name          string
+		useProtoNames bool
+		input         func(t *testing.T) proto.Message
+		wantOutput    string
+		wantPaths     []string
+	}{
+		{
+			name: "nested & repeated",
+			input: func(t *testing.T) proto.Message {
+				return &mosaicpb.DetectResponse{Pairs: []*mosaicpb.KVPair{
+					{Key: "password", Val: `"abc"`, ValJsonPath: `["password"]`},
+					{Key: "secret", Val: `1`},
+				}}
+			},
+			wantOutput: `{"pairs":[{"key":"password","val":"*****"},{"val":"*"}]}`,
+			wantPaths:  []string{"pairs->0->val", "pairs->0->valJsonPath", "pairs->1->key", "pairs->1->val"},
+		},
+		{
+			name:          "proto names",
+			useProtoNames: true,
+			input: func(t *testing.T) proto.Message {
+				return &mosaicpb.KVPair{Key: "password", ValJsonPath: `["password"]`}
+			},
+			wantOutput: `{"key":"password"}`,
+			wantPaths:  []string{"val_json_path"},
+		},
+		{
+			name: "struct",
+			input: func(t *testing.T) proto.Message {
+				return mustStruct(t, `{"user":{"phone":"123","age":30,"name":"a"},"list":["secret","a"],"flag":true}`)
+			},
+			wantOutput: `{"flag":true,"list":["a"],"user":{"age":"**","name":"a","phone":"***"}}`,
+			wantPaths:  []string{"list->0", "user->age", "user->phone"},
+		},
+		{
+			name: "wrapper",
+			input: func(t *testing.T) proto.Message {
+				return wrapperspb.Int64(12345)
+			},
+			wantOutput: `"0"`,
+			wantPaths:  []string{""},
+		},
+	}
+	m := processer.NewKVProcesser(rule)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input(t)
+			original := proto.Clone(input)
+			i := New(m, Config{UseProtoNames: tt.useProtoNames})
+			masked, pairs, err := i.MaskMessage(context.Background(), input)
+			if err != nil {
+				t.Fatalf("MaskMessage() error = %v", err)
+			}
+			if !proto.Equal(input, original) {
+				t.Errorf("MaskMessage() changed input")
+			}
+			got, err := protojson.MarshalOptions{UseProtoNames: tt.useProtoNames}.Marshal(masked)
+			if err != nil {
+				t.Fatalf("protojson.Marshal() error = %v", err)
+			}
+			// protojson output is not stable, compare compacted JSON
+			var gotJSON interface{}
+			json.Unmarshal(got, &gotJSON)
+			gotBytes, _ := json.Marshal(gotJSON)
+			if string(gotBytes) != tt.wantOutput {
+				t.Errorf("MaskMessage() = %v, want %v", string(gotBytes), tt.wantOutput)
+			}
+			var gotPaths []string
+			for _, pair := range pairs {
+				gotPaths = append(gotPaths, pair.ValJSONPath.String())
+			}
+			sort.Strings(gotPaths)
+			if strings.Join(gotPaths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("MaskMessage() paths = %v, want %v", gotPaths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestInterceptor_Server(t *testing.T) {
+	mosaic := server.New()
+	if err := mosaic.SetRuleSet("", types.KVRules{DetectRules: []types.KVDetectConfig{{KeyEqs: []string{"password"}}}}); err != nil {
+		t.Fatalf("SetRuleSet() error = %v", err)
+	}
+	var mu sync.Mutex
+	logged := map[Direction][]string{}
+	i := New(processer.NewKVProcesser(types.KVRules{
+		DetectRules: []types.KVDetectConfig{{KeyEqs: []string{"key", "input", "batch"}}},
+	}), Config{
+		MaskResponse: true,
+		OnMessage: func(ctx context.Context, info MessageInfo, masked proto.Message, pairs []types.KVPair) {
+			mu.Lock()
+			defer mu.Unlock()
+			data, _ := protojson.Marshal(masked)
+			logged[info.Direction] = append(logged[info.Direction], info.FullMethod+" "+string(data))
+		},
+	})
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(i.Unary()), grpc.StreamInterceptor(i.Stream()))
+	mosaicpb.RegisterMosaicServer(grpcServer, mosaic)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("DialContext() error = %v", err)
+	}
+	defer conn.Close()
+	c := client.New(conn)
+
+	// unary: request is logged masked, response is masked
+	_, pairs, err := c.Mask(context.Background(), "", []byte(`{"password":"abc"}`))
+	if err != nil {
+		t.Fatalf("Mask() error = %v", err)
+	}
+	if len(pairs) != 1 || pairs[0].Key != "********" || pairs[0].GetValString() != "abc" {
+		t.Errorf("Mask() pairs = %+v, want key masked", pairs)
+	}
+	// stream: each message is intercepted
+	stream, err := c.MaskStream(context.Background(), "")
+	if err != nil {
+		t.Fatalf("MaskStream() error = %v", err)
+	}
+	if err := stream.Send([]byte(`{"password":"abc"}`)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	batch, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv() error = %v", err)
+	}
+	if strings.Trim(string(batch.Batch), "*") != "" {
+		t.Errorf("Recv() batch = %q, want masked", batch.Batch)
+	}
+	stream.CloseSend()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(logged[DirectionRequest]) != 2 || len(logged[DirectionResponse]) != 2 {
+		t.Fatalf("OnMessage() logged %v", logged)
+	}
+	if want := `/mosaic.v1.Mosaic/Mask {"input":"`; !strings.HasPrefix(strings.ReplaceAll(logged[DirectionRequest][0], " ", ""), strings.ReplaceAll(want, " ", "")) ||
+		strings.Contains(logged[DirectionRequest][0], "eyJwYXNzd29yZCI6ImFiYyJ9") {
+		t.Errorf("OnMessage() request = %v, want input masked", logged[DirectionRequest][0])
+	}
+}
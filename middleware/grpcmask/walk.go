@@ -0,0 +1,309 @@
+package grpcmask
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// wrappers of scalars, mapped to scalar like protojson
+var wrapperNames = map[protoreflect.FullName]struct{}{
+	"google.protobuf.DoubleValue": {},
+	"google.protobuf.FloatValue":  {},
+	"google.protobuf.Int64Value":  {},
+	"google.protobuf.UInt64Value": {},
+	"google.protobuf.Int32Value":  {},
+	"google.protobuf.UInt32Value": {},
+	"google.protobuf.BoolValue":   {},
+	"google.protobuf.StringValue": {},
+	"google.protobuf.BytesValue":  {},
+}
+
+/*
+walk message and mask fields in place, paths are paths of protojson:
+fields are keyed by JSON name (or proto name), list elements by index, map values by map key;
+wrappers, Struct, Value & ListValue are mapped to JSON values like protojson
+scalars are masked as JSON values: 64 bits integers & floats as json.Number, bytes as base64, enums by name
+*/
+type walker struct {
+	ctx           context.Context
+	m             processer.KVProcesser
+	useProtoNames bool
+	pairs         []types.KVPair
+}
+
+func (w *walker) fieldName(fd protoreflect.FieldDescriptor) string {
+	switch {
+	case fd.IsExtension():
+		return "[" + string(fd.FullName()) + "]"
+	case w.useProtoNames:
+		return string(fd.Name())
+	default:
+		return fd.JSONName()
+	}
+}
+
+func (w *walker) message(msg protoreflect.Message, path types.JSONPath) error {
+	// message is not changed while ranging
+	var fields []protoreflect.FieldDescriptor
+	msg.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fields = append(fields, fd)
+		return true
+	})
+	for _, fd := range fields {
+		name := w.fieldName(fd)
+		if err := w.field(msg, fd, path.Append(name), name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mask field of message, dropped list elements are removed, dropped map values are deleted
+func (w *walker) field(msg protoreflect.Message, fd protoreflect.FieldDescriptor, path types.JSONPath, key string) error {
+	switch {
+	case fd.IsList():
+		list := msg.Mutable(fd).List()
+		kept := make([]protoreflect.Value, 0, list.Len())
+		for idx := 0; idx < list.Len(); idx++ {
+			v, drop, err := w.value(fd, list.Get(idx), path.Append(idx), key)
+			if err != nil {
+				return err
+			}
+			if !drop {
+				kept = append(kept, v)
+			}
+		}
+		list.Truncate(0)
+		for _, v := range kept {
+			list.Append(v)
+		}
+	case fd.IsMap():
+		mp := msg.Mutable(fd).Map()
+		var mapKeys []protoreflect.MapKey
+		mp.Range(func(mk protoreflect.MapKey, _ protoreflect.Value) bool {
+			mapKeys = append(mapKeys, mk)
+			return true
+		})
+		for _, mk := range mapKeys {
+			k := mk.String()
+			v, drop, err := w.value(fd.MapValue(), mp.Get(mk), path.Append(k), k)
+			if err != nil {
+				return err
+			}
+			if drop {
+				mp.Clear(mk)
+			} else {
+				mp.Set(mk, v)
+			}
+		}
+	default:
+		v, drop, err := w.value(fd, msg.Get(fd), path, key)
+		if err != nil {
+			return err
+		}
+		if drop {
+			msg.Clear(fd)
+		} else {
+			msg.Set(fd, v)
+		}
+	}
+	return nil
+}
+
+// mask value of field, list element or map value, messages are masked in place
+// returns true if value is dropped
+func (w *walker) value(fd protoreflect.FieldDescriptor, v protoreflect.Value, path types.JSONPath, key string) (protoreflect.Value, bool, error) {
+	if fd.Message() == nil {
+		return w.scalar(fd, v, path, key)
+	}
+	drop, err := w.messageValue(v.Message(), path, key)
+	return v, drop, err
+}
+
+// mask message in place, well-known types are mapped to JSON values
+func (w *walker) messageValue(msg protoreflect.Message, path types.JSONPath, key string) (bool, error) {
+	desc := msg.Descriptor()
+	if _, ok := wrapperNames[desc.FullName()]; ok {
+		valueField := desc.Fields().ByName("value")
+		masked, drop, err := w.scalar(valueField, msg.Get(valueField), path, key)
+		if err != nil || drop {
+			return drop, err
+		}
+		msg.Set(valueField, masked)
+		return false, nil
+	}
+	switch desc.FullName() {
+	case "google.protobuf.Struct":
+		return false, w.field(msg, desc.Fields().ByName("fields"), path, key)
+	case "google.protobuf.ListValue":
+		return false, w.field(msg, desc.Fields().ByName("values"), path, key)
+	case "google.protobuf.Value":
+		return w.structValue(msg, path, key)
+	}
+	return false, w.message(msg, path)
+}
+
+// mask google.protobuf.Value, kind of value changes with type of masked value
+func (w *walker) structValue(msg protoreflect.Message, path types.JSONPath, key string) (bool, error) {
+	kind := msg.WhichOneof(msg.Descriptor().Oneofs().ByName("kind"))
+	if kind == nil {
+		return false, nil
+	}
+	var val interface{}
+	switch kind.Name() {
+	case "struct_value", "list_value":
+		return w.messageValue(msg.Get(kind).Message(), path, key)
+	case "null_value":
+		val = nil
+	default:
+		val = scalarOf(kind, msg.Get(kind))
+	}
+	masked, matched, err := w.mask(val, path, key)
+	if err != nil || !matched {
+		return false, err
+	}
+	if _, dropped := masked.(types.KVDropped); dropped {
+		return true, nil
+	}
+	pv, ok := msg.Interface().(*structpb.Value)
+	if !ok {
+		return false, fmt.Errorf("mask %s: unsupported %T", path, msg.Interface())
+	}
+	switch out := masked.(type) {
+	case json.Number:
+		f, _ := out.Float64()
+		pv.Kind = &structpb.Value_NumberValue{NumberValue: f}
+	case json.RawMessage:
+		var raw interface{}
+		if err := json.Unmarshal(out, &raw); err != nil {
+			return false, fmt.Errorf("mask %s: %w", path, err)
+		}
+		newValue, err := structpb.NewValue(raw)
+		if err != nil {
+			return false, fmt.Errorf("mask %s: %w", path, err)
+		}
+		pv.Kind = newValue.Kind
+	default:
+		newValue, err := structpb.NewValue(out)
+		if err != nil {
+			newValue = structpb.NewStringValue(fmt.Sprint(out))
+		}
+		pv.Kind = newValue.Kind
+	}
+	return false, nil
+}
+
+// mask scalar, masked text not convertible to kind of field is set to default value
+func (w *walker) scalar(fd protoreflect.FieldDescriptor, v protoreflect.Value, path types.JSONPath, key string) (protoreflect.Value, bool, error) {
+	masked, matched, err := w.mask(scalarOf(fd, v), path, key)
+	if err != nil || !matched {
+		return v, false, err
+	}
+	switch masked.(type) {
+	case types.KVDropped:
+		return v, true, nil
+	case nil:
+		return fd.Default(), false, nil
+	}
+	return scalarFrom(fd, processer.MaskedText(masked)), false, nil
+}
+
+func (w *walker) mask(val interface{}, path types.JSONPath, key string) (interface{}, bool, error) {
+	pair, matched, err := w.m.MaskPair(w.ctx, types.KVPair{Key: key, Val: val, ValJSONPath: path})
+	if err != nil {
+		return nil, false, fmt.Errorf("mask %s: %w", path, err)
+	}
+	if matched {
+		w.pairs = append(w.pairs, pair)
+	}
+	return pair.ValMasked, matched, nil
+}
+
+// JSON value of scalar
+func scalarOf(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return v.String()
+	case protoreflect.BoolKind:
+		return v.Bool()
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes())
+	case protoreflect.EnumKind:
+		if enumValue := fd.Enum().Values().ByNumber(v.Enum()); enumValue != nil {
+			return string(enumValue.Name())
+		}
+		return json.Number(strconv.Itoa(int(v.Enum())))
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+		bits := 64
+		if fd.Kind() == protoreflect.FloatKind {
+			bits = 32
+		}
+		return json.Number(strconv.FormatFloat(f, 'g', -1, bits))
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return json.Number(strconv.FormatUint(v.Uint(), 10))
+	default:
+		return json.Number(strconv.FormatInt(v.Int(), 10))
+	}
+}
+
+// scalar of field kind from masked text, default value if not convertible
+func scalarFrom(fd protoreflect.FieldDescriptor, text string) protoreflect.Value {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(text)
+	case protoreflect.BoolKind:
+		if b, err := strconv.ParseBool(text); err == nil {
+			return protoreflect.ValueOfBool(b)
+		}
+	case protoreflect.BytesKind:
+		if b, err := base64.StdEncoding.DecodeString(text); err == nil {
+			return protoreflect.ValueOfBytes(b)
+		}
+		return protoreflect.ValueOfBytes([]byte(text))
+	case protoreflect.EnumKind:
+		if enumValue := fd.Enum().Values().ByName(protoreflect.Name(text)); enumValue != nil {
+			return protoreflect.ValueOfEnum(enumValue.Number())
+		}
+		if i, err := strconv.ParseInt(text, 10, 32); err == nil {
+			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(i))
+		}
+	case protoreflect.FloatKind:
+		if f, err := strconv.ParseFloat(text, 32); err == nil {
+			return protoreflect.ValueOfFloat32(float32(f))
+		}
+	case protoreflect.DoubleKind:
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return protoreflect.ValueOfFloat64(f)
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		if i, err := strconv.ParseInt(text, 10, 32); err == nil {
+			return protoreflect.ValueOfInt32(int32(i))
+		}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return protoreflect.ValueOfInt64(i)
+		}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		if u, err := strconv.ParseUint(text, 10, 32); err == nil {
+			return protoreflect.ValueOfUint32(uint32(u))
+		}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if u, err := strconv.ParseUint(text, 10, 64); err == nil {
+			return protoreflect.ValueOfUint64(u)
+		}
+	}
+	return fd.Default()
+}
@@ -0,0 +1,436 @@
+// net/http middleware masking JSON request & response bodies by processer
+package httpmask
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+)
+
+var (
+	DefaultContentTypes       = []string{"application/json"}
+	DefaultStreamContentTypes = []string{"application/x-ndjson", "application/jsonl"}
+)
+
+var ErrBodyTooLarge = errors.New("body too large")
+
+type Direction string
+
+const (
+	DirectionRequest  Direction = "request"
+	DirectionResponse Direction = "response"
+)
+
+type Config struct {
+	/*media types of JSON bodies, buffered and masked as a whole, default DefaultContentTypes
+	types with suffix "+json" are JSON as well, e.g. application/problem+json
+	*/
+	ContentTypes []string
+	// media types of newline delimited JSON, masked line by line while streaming, default DefaultStreamContentTypes
+	StreamContentTypes []string
+	MaskRequest        bool // mask request body before it reaches handler
+	SkipResponse       bool // do not mask response body
+	/*limit of buffered body in bytes, 0 is unlimited
+	body or line of stream larger than limit is rejected even if FailOpen
+	*/
+	MaxBodySize int64
+	/*write original body if masking failed, e.g. invalid JSON or unsupported Content-Encoding
+	by default request is rejected by 400, response is replaced by 500 and failed lines of stream are dropped
+	request body failed to read is rejected even if FailOpen, as it is consumed
+	*/
+	FailOpen bool
+	// called with detected pairs of each masked body or line, for auditing
+	OnDetect func(r *http.Request, direction Direction, pairs []types.KVPair)
+	/*called with masked copy of request body, handler still gets original body unless MaskRequest
+	without MaskRequest, request failed to mask is passed with original body like FailOpen
+	*/
+	OnRequestBody func(r *http.Request, masked []byte)
+	// called if masking failed
+	OnError func(r *http.Request, direction Direction, err error)
+}
+
+type bodyMode int
+
+const (
+	modePass bodyMode = iota
+	modeBuffer
+	modeStream
+)
+
+type middleware struct {
+	m      processer.KVProcesser
+	config Config
+}
+
+// create middleware masking bodies by processer
+func New(m processer.KVProcesser, config Config) func(http.Handler) http.Handler {
+	if config.ContentTypes == nil {
+		config.ContentTypes = DefaultContentTypes
+	}
+	if config.StreamContentTypes == nil {
+		config.StreamContentTypes = DefaultStreamContentTypes
+	}
+	mw := &middleware{m: m, config: config}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mw.serve(next, w, r)
+		})
+	}
+}
+
+func (mw *middleware) serve(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	if mw.config.MaskRequest || mw.config.OnRequestBody != nil {
+		if !mw.maskRequest(w, r) {
+			return
+		}
+	}
+	if mw.config.SkipResponse {
+		next.ServeHTTP(w, r)
+		return
+	}
+	rw := &responseWriter{ResponseWriter: w, mw: mw, r: r}
+	next.ServeHTTP(rw, r)
+	rw.finish()
+}
+
+// mask request body, returns false if request is rejected
+func (mw *middleware) maskRequest(w http.ResponseWriter, r *http.Request) bool {
+	mode := mw.mode(r.Header)
+	if r.Body == nil || r.Body == http.NoBody || mode == modePass {
+		return true
+	}
+	encoding := r.Header.Get("Content-Encoding")
+	body, err := mw.readAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		// body is consumed, so request is rejected even if FailOpen
+		mw.onError(r, DirectionRequest, err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return false
+	}
+	masked, err := mw.maskEncoded(r, DirectionRequest, encoding, body, mw.isStream(r.Header))
+	if err == nil && mw.config.OnRequestBody != nil {
+		mw.config.OnRequestBody(r, masked)
+	}
+	if err == nil && mw.config.MaskRequest {
+		masked, err = encodeBody(encoding, masked)
+	}
+	switch {
+	case err == nil && mw.config.MaskRequest:
+		body = masked
+	case err != nil:
+		mw.onError(r, DirectionRequest, err)
+		// audit only passes the original body, as handler gets it anyway
+		if errors.Is(err, ErrBodyTooLarge) || (mw.config.MaskRequest && !mw.config.FailOpen) {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return false
+		}
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return true
+}
+
+// how body is masked, by Content-Type & Content-Encoding
+func (mw *middleware) mode(header http.Header) bodyMode {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return modePass
+	}
+	switch {
+	case containsFold(mw.config.StreamContentTypes, mediaType):
+		if encoding := header.Get("Content-Encoding"); encoding != "" && !strings.EqualFold(encoding, "identity") {
+			// compressed stream is buffered to be decompressed
+			return modeBuffer
+		}
+		return modeStream
+	case containsFold(mw.config.ContentTypes, mediaType), strings.HasSuffix(mediaType, "+json"):
+		return modeBuffer
+	}
+	return modePass
+}
+
+// whether body is newline delimited JSON
+func (mw *middleware) isStream(header http.Header) bool {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	return containsFold(mw.config.StreamContentTypes, mediaType)
+}
+
+// decode body in Content-Encoding then mask, masked body is not encoded back
+// identity and gzip are supported
+func (mw *middleware) maskEncoded(r *http.Request, direction Direction, encoding string, body []byte, stream bool) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if body, err = mw.readAll(zr); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported Content-Encoding %q", encoding)
+	}
+	return mw.maskBody(r, direction, body, stream)
+}
+
+// encode masked body back to Content-Encoding
+func encodeBody(encoding string, body []byte) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case "gzip", "x-gzip":
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return body, nil
+	}
+}
+
+// mask JSON document, or newline delimited JSON documents if stream
+func (mw *middleware) maskBody(r *http.Request, direction Direction, body []byte, stream bool) ([]byte, error) {
+	if !stream {
+		return mw.maskDocument(r, direction, body)
+	}
+	var out bytes.Buffer
+	for len(body) > 0 {
+		line := body
+		if idx := bytes.IndexByte(body, '\n'); idx >= 0 {
+			line, body = body[:idx+1], body[idx+1:]
+		} else {
+			body = nil
+		}
+		masked, err := mw.maskLine(r, direction, line)
+		if err != nil {
+			return nil, err
+		}
+		out.Write(masked)
+	}
+	return out.Bytes(), nil
+}
+
+// mask a line of stream, line ending is kept
+func (mw *middleware) maskLine(r *http.Request, direction Direction, line []byte) ([]byte, error) {
+	content := bytes.TrimRight(line, "\r\n")
+	masked, err := mw.maskDocument(r, direction, content)
+	if err != nil {
+		return nil, err
+	}
+	return append(masked, line[len(content):]...), nil
+}
+
+// mask a JSON document, blank document is kept as is
+func (mw *middleware) maskDocument(r *http.Request, direction Direction, doc []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(doc)) == 0 {
+		return doc, nil
+	}
+	masked, pairs, err := mw.m.Mask(contextOf(r), doc)
+	if err != nil {
+		return nil, err
+	}
+	if len(pairs) > 0 && mw.config.OnDetect != nil {
+		mw.config.OnDetect(r, direction, pairs)
+	}
+	return masked, nil
+}
+
+func (mw *middleware) readAll(reader io.Reader) ([]byte, error) {
+	if mw.config.MaxBodySize <= 0 {
+		return io.ReadAll(reader)
+	}
+	body, err := io.ReadAll(io.LimitReader(reader, mw.config.MaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > mw.config.MaxBodySize {
+		return nil, ErrBodyTooLarge
+	}
+	return body, nil
+}
+
+func (mw *middleware) onError(r *http.Request, direction Direction, err error) {
+	if mw.config.OnError != nil {
+		mw.config.OnError(r, direction, err)
+	}
+}
+
+// response writer buffering or streaming body to be masked
+type responseWriter struct {
+	http.ResponseWriter
+	mw          *middleware
+	r           *http.Request
+	status      int
+	wroteHeader bool
+	mode        bodyMode
+	buf         bytes.Buffer
+	err         error // body too large, remaining body is discarded
+}
+
+func (rw *responseWriter) WriteHeader(status int) {
+	if rw.wroteHeader {
+		return
+	}
+	rw.wroteHeader = true
+	rw.status = status
+	rw.mode = rw.mw.mode(rw.Header())
+	if rw.r.Method == http.MethodHead || status == http.StatusNoContent || status == http.StatusNotModified {
+		rw.mode = modePass
+	}
+	switch rw.mode {
+	case modePass:
+		rw.ResponseWriter.WriteHeader(status)
+	case modeStream:
+		rw.Header().Del("Content-Length")
+		rw.ResponseWriter.WriteHeader(status)
+	}
+	// buffered response writes header after masked
+}
+
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	if rw.err != nil {
+		return 0, rw.err
+	}
+	switch rw.mode {
+	case modeBuffer:
+		if rw.mw.config.MaxBodySize > 0 && int64(rw.buf.Len()+len(p)) > rw.mw.config.MaxBodySize {
+			rw.fail(ErrBodyTooLarge)
+			return 0, rw.err
+		}
+		return rw.buf.Write(p)
+	case modeStream:
+		rw.buf.Write(p)
+		for {
+			idx := bytes.IndexByte(rw.buf.Bytes(), '\n')
+			if idx < 0 {
+				break
+			}
+			if err := rw.writeLine(rw.buf.Next(idx + 1)); err != nil {
+				return 0, err
+			}
+		}
+		if rw.mw.config.MaxBodySize > 0 && int64(rw.buf.Len()) > rw.mw.config.MaxBodySize {
+			rw.fail(ErrBodyTooLarge)
+			return 0, rw.err
+		}
+		return len(p), nil
+	default:
+		return rw.ResponseWriter.Write(p)
+	}
+}
+
+// discard body and report error
+func (rw *responseWriter) fail(err error) {
+	rw.err = err
+	rw.buf.Reset()
+	rw.mw.onError(rw.r, DirectionResponse, err)
+}
+
+// write masked line of stream, failed line is dropped unless FailOpen
+func (rw *responseWriter) writeLine(line []byte) error {
+	masked, err := rw.mw.maskLine(rw.r, DirectionResponse, line)
+	if err != nil {
+		rw.mw.onError(rw.r, DirectionResponse, err)
+		if !rw.mw.config.FailOpen {
+			return nil
+		}
+		masked = line
+	}
+	_, err = rw.ResponseWriter.Write(masked)
+	return err
+}
+
+// complete lines of stream are already written, buffered body can not be flushed
+func (rw *responseWriter) Flush() {
+	if rw.mode == modeBuffer {
+		return
+	}
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// write masked body after handler returned
+func (rw *responseWriter) finish() {
+	if !rw.wroteHeader {
+		return
+	}
+	switch rw.mode {
+	case modeStream:
+		if rw.buf.Len() > 0 {
+			rw.writeLine(rw.buf.Bytes())
+		}
+	case modeBuffer:
+		if rw.err != nil {
+			rw.writeError()
+			return
+		}
+		body := rw.buf.Bytes()
+		if len(body) == 0 {
+			rw.ResponseWriter.WriteHeader(rw.status)
+			return
+		}
+		encoding := rw.Header().Get("Content-Encoding")
+		masked, err := rw.mw.maskEncoded(rw.r, DirectionResponse, encoding, body, rw.mw.isStream(rw.Header()))
+		if err == nil {
+			masked, err = encodeBody(encoding, masked)
+		}
+		if err != nil {
+			rw.mw.onError(rw.r, DirectionResponse, err)
+			if !rw.mw.config.FailOpen {
+				rw.writeError()
+				return
+			}
+			masked = body
+		}
+		rw.Header().Set("Content-Length", strconv.Itoa(len(masked)))
+		rw.ResponseWriter.WriteHeader(rw.status)
+		rw.ResponseWriter.Write(masked)
+	}
+}
+
+// replace response by 500
+func (rw *responseWriter) writeError() {
+	rw.Header().Del("Content-Encoding")
+	rw.Header().Del("Content-Length")
+	http.Error(rw.ResponseWriter, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func contextOf(r *http.Request) context.Context {
+	if r == nil {
+		return context.Background()
+	}
+	return r.Context()
+}
@@ -0,0 +1,324 @@
+package httpmask
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+)
+
+var passwordRule = types.KVRules{
+	DetectRules: []types.KVDetectConfig{
+		{KeyEqs: []string{"password"}},
+	},
+}
+
+func gzipString(s string) string {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(s))
+	zw.Close()
+	return buf.String()
+}
+
+func gunzipString(t *testing.T, s string) string {
+	zr, err := gzip.NewReader(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("gzip read error = %v", err)
+	}
+	return string(out)
+}
+
+func TestNew_Response(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		contentType string
+		encoding    string
+		status      int
+		body        []string // written by several Write calls
+		wantStatus  int
+		wantBody    string
+		wantPairs   int
+	}{
+		{
+			name:        "json",
+			contentType: "application/json; charset=utf-8",
+			body:        []string{`{"user":"alice",`, `"password":"123456"}`},
+			wantStatus:  http.StatusOK,
+			wantBody:    `{"user":"alice","password":"******"}`,
+			wantPairs:   1,
+		},
+		{
+			name:        "json suffix",
+			contentType: "application/problem+json",
+			status:      http.StatusBadRequest,
+			body:        []string{`{"password":"abc"}`},
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    `{"password":"***"}`,
+			wantPairs:   1,
+		},
+		{
+			name:        "not json",
+			contentType: "text/plain",
+			body:        []string{`{"password":"abc"}`},
+			wantStatus:  http.StatusOK,
+			wantBody:    `{"password":"abc"}`,
+		},
+		{
+			name:        "ndjson stream",
+			contentType: "application/x-ndjson",
+			body:        []string{"{\"password\":\"abc\"}\n{\"pass", "word\":\"de\"}\n\n{\"password\":\"f\"}"},
+			wantStatus:  http.StatusOK,
+			wantBody:    "{\"password\":\"***\"}\n{\"password\":\"**\"}\n\n{\"password\":\"*\"}",
+			wantPairs:   3,
+		},
+		{
+			name:        "gzip",
+			contentType: "application/json",
+			encoding:    "gzip",
+			body:        []string{gzipString(`{"password":"abc"}`)},
+			wantStatus:  http.StatusOK,
+			wantBody:    `{"password":"***"}`,
+			wantPairs:   1,
+		},
+		{
+			name:        "gzip ndjson",
+			contentType: "application/x-ndjson",
+			encoding:    "gzip",
+			body:        []string{gzipString("{\"password\":\"abc\"}\n{\"password\":\"de\"}\n")},
+			wantStatus:  http.StatusOK,
+			wantBody:    "{\"password\":\"***\"}\n{\"password\":\"**\"}\n",
+			wantPairs:   2,
+		},
+		{
+			name:        "invalid json",
+			contentType: "application/json",
+			body:        []string{`{"password":"abc"`},
+			wantStatus:  http.StatusInternalServerError,
+			wantBody:    "Internal Server Error\n",
+		},
+		{
+			name:        "invalid json fail open",
+			config:      Config{FailOpen: true},
+			contentType: "application/json",
+			body:        []string{`{"password":"abc"`},
+			wantStatus:  http.StatusOK,
+			wantBody:    `{"password":"abc"`,
+		},
+		{
+			name:        "unsupported encoding",
+			contentType: "application/json",
+			encoding:    "br",
+			body:        []string{`{"password":"abc"}`},
+			wantStatus:  http.StatusInternalServerError,
+			wantBody:    "Internal Server Error\n",
+		},
+		{
+			name:        "invalid line dropped",
+			contentType: "application/x-ndjson",
+			body:        []string{"{\"password\":\"abc\"}\n{\n{\"password\":\"de\"}\n"},
+			wantStatus:  http.StatusOK,
+			wantBody:    "{\"password\":\"***\"}\n{\"password\":\"**\"}\n",
+			wantPairs:   2,
+		},
+		{
+			name:        "body too large",
+			config:      Config{MaxBodySize: 8},
+			contentType: "application/json",
+			body:        []string{`{"password":"abc"}`},
+			wantStatus:  http.StatusInternalServerError,
+			wantBody:    "Internal Server Error\n",
+		},
+		{
+			name:        "skip response",
+			config:      Config{SkipResponse: true},
+			contentType: "application/json",
+			body:        []string{`{"password":"abc"}`},
+			wantStatus:  http.StatusOK,
+			wantBody:    `{"password":"abc"}`,
+		},
+	}
+	m := processer.NewKVProcesser(passwordRule)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPairs int
+			config := tt.config
+			config.OnDetect = func(r *http.Request, direction Direction, pairs []types.KVPair) {
+				if direction != DirectionResponse {
+					t.Errorf("OnDetect() direction = %v, want %v", direction, DirectionResponse)
+				}
+				gotPairs += len(pairs)
+			}
+			handler := New(m, config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				w.Header().Set("Content-Length", strconv.Itoa(len(strings.Join(tt.body, ""))))
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				for _, chunk := range tt.body {
+					w.Write([]byte(chunk))
+					w.(http.Flusher).Flush()
+				}
+			}))
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			req.Header.Set("Accept-Encoding", "identity")
+			resp, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("read body error = %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+			gotBody := string(body)
+			if resp.Header.Get("Content-Encoding") == "gzip" {
+				gotBody = gunzipString(t, gotBody)
+			}
+			if gotBody != tt.wantBody {
+				t.Errorf("body = %q, want %q", gotBody, tt.wantBody)
+			}
+			if resp.ContentLength >= 0 && resp.ContentLength != int64(len(body)) {
+				t.Errorf("Content-Length = %v, want %v", resp.ContentLength, len(body))
+			}
+			if gotPairs != tt.wantPairs {
+				t.Errorf("OnDetect() got %d pairs, want %d", gotPairs, tt.wantPairs)
+			}
+		})
+	}
+}
+
+func TestNew_Request(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		contentType string
+		encoding    string
+		body        string
+		readErr     bool
+		wantStatus  int
+		wantBody    string
+		wantLogged  string
+	}{
+		{
+			name:        "mask request",
+			config:      Config{MaskRequest: true},
+			contentType: "application/json",
+			body:        `{"password":"abc"}`,
+			wantStatus:  http.StatusOK,
+			wantBody:    `{"password":"***"}`,
+		},
+		{
+			name:        "mask gzip request",
+			config:      Config{MaskRequest: true},
+			contentType: "application/json",
+			encoding:    "gzip",
+			body:        gzipString(`{"password":"abc"}`),
+			wantStatus:  http.StatusOK,
+			wantBody:    `{"password":"***"}`,
+		},
+		{
+			name:        "log masked request only",
+			contentType: "application/json",
+			body:        `{"password":"abc"}`,
+			wantStatus:  http.StatusOK,
+			wantBody:    `{"password":"abc"}`,
+			wantLogged:  `{"password":"***"}`,
+		},
+		{
+			name:        "invalid request",
+			config:      Config{MaskRequest: true},
+			contentType: "application/json",
+			body:        `{"password":`,
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "log invalid request only",
+			contentType: "application/json",
+			body:        `{"password":`,
+			wantStatus:  http.StatusOK,
+			wantBody:    `{"password":`,
+		},
+		{
+			name:        "request too large",
+			config:      Config{MaskRequest: true, MaxBodySize: 4, FailOpen: true},
+			contentType: "application/json",
+			body:        `{"password":"abc"}`,
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "unreadable request",
+			config:      Config{MaskRequest: true, FailOpen: true},
+			contentType: "application/json",
+			body:        `{"password":"abc"}`,
+			readErr:     true,
+			wantStatus:  http.StatusBadRequest,
+		},
+	}
+	m := processer.NewKVProcesser(passwordRule)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotLogged string
+			config := tt.config
+			config.SkipResponse = true
+			if tt.wantLogged != "" {
+				config.OnRequestBody = func(r *http.Request, masked []byte) {
+					gotLogged = string(masked)
+				}
+			}
+			var gotBody string
+			handler := New(m, config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if r.ContentLength != int64(len(body)) {
+					t.Errorf("ContentLength = %v, want %v", r.ContentLength, len(body))
+				}
+				gotBody = string(body)
+				if r.Header.Get("Content-Encoding") == "gzip" {
+					gotBody = gunzipString(t, gotBody)
+				}
+			}))
+			var body io.Reader = strings.NewReader(tt.body)
+			if tt.readErr {
+				body = io.MultiReader(body, iotest.ErrReader(errors.New("connection reset")))
+			}
+			req := httptest.NewRequest(http.MethodPost, "/", body)
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+			if gotBody != tt.wantBody {
+				t.Errorf("handler body = %q, want %q", gotBody, tt.wantBody)
+			}
+			if gotLogged != tt.wantLogged {
+				t.Errorf("OnRequestBody() body = %q, want %q", gotLogged, tt.wantLogged)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+// check digits of identifiers, shared by detection & fake values
+package checksum
+
+// digits with check digit at the end are valid by Luhn, at least 2 digits
+func LuhnValid(digits []byte) bool {
+	if len(digits) < 2 {
+		return false
+	}
+	for _, d := range digits {
+		if d < '0' || d > '9' {
+			return false
+		}
+	}
+	return LuhnCheckDigit(digits[:len(digits)-1]) == digits[len(digits)-1]
+}
+
+// check digit appended to digits by Luhn
+func LuhnCheckDigit(digits []byte) byte {
+	sum := 0
+	double := true
+	for idx := len(digits) - 1; idx >= 0; idx-- {
+		d := int(digits[idx] - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return byte('0' + (10-sum%10)%10)
+}
@@ -0,0 +1,30 @@
+package checksum
+
+import "testing"
+
+func TestLuhnValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		digits string
+		want   bool
+	}{
+		{name: "visa", digits: "4111111111111111", want: true},
+		{name: "wrong check digit", digits: "4111111111111112", want: false},
+		{name: "short", digits: "0", want: false},
+		{name: "two digits", digits: "18", want: true},
+		{name: "not digits", digits: "41111a1111111111", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LuhnValid([]byte(tt.digits)); got != tt.want {
+				t.Errorf("LuhnValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLuhnCheckDigit(t *testing.T) {
+	if got := LuhnCheckDigit([]byte("411111111111111")); got != '1' {
+		t.Errorf("LuhnCheckDigit() = %c, want 1", got)
+	}
+}
@@ -0,0 +1,32 @@
+/*
+decimal numbers of untrusted input, parsed exactly with bounded size
+
+big.Rat allocates 10^exp for exponents, so 1e999999 takes seconds to parse;
+numbers longer than MaxLen or with exponent bigger than MaxExp are rejected
+*/
+package decimal
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	MaxLen = 1 << 10 // length of number text
+	MaxExp = 10000   // absolute value of exponent
+)
+
+// exact rational of decimal text, e.g. -1.5 or 3e10, false if not a number or out of bounds
+func ParseRat(s string) (*big.Rat, bool) {
+	if len(s) > MaxLen {
+		return nil, false
+	}
+	if idx := strings.IndexAny(s, "eE"); idx >= 0 {
+		exp, err := strconv.Atoi(s[idx+1:])
+		if err != nil || exp > MaxExp || exp < -MaxExp {
+			return nil, false
+		}
+	}
+	return new(big.Rat).SetString(s)
+}
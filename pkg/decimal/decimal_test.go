@@ -0,0 +1,37 @@
+package decimal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRat(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		want   string
+		wantOk bool
+	}{
+		{name: "integer", in: "42", want: "42/1", wantOk: true},
+		{name: "decimal", in: "-1.25", want: "-5/4", wantOk: true},
+		{name: "exponent", in: "1.5e3", want: "1500/1", wantOk: true},
+		{name: "negative exponent", in: "25E-2", want: "1/4", wantOk: true},
+		{name: "max exponent", in: "1e10000", wantOk: true},
+		{name: "exponent too large", in: "1e999999", wantOk: false},
+		{name: "negative exponent too large", in: "1e-10001", wantOk: false},
+		{name: "exponent overflow", in: "1e99999999999999999999", wantOk: false},
+		{name: "too long", in: strings.Repeat("9", MaxLen+1), wantOk: false},
+		{name: "not a number", in: "abc", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseRat(tt.in)
+			if ok != tt.wantOk {
+				t.Fatalf("ParseRat() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if tt.want != "" && got.String() != tt.want {
+				t.Errorf("ParseRat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+/*
+dictionaries of sensitive words only recognisable by lookup, e.g. employee names or project codenames
+
+words are kept in a hash set for exact matching, or an Aho-Corasick automaton for contains matching,
+so lookups do not slow down with 100k+ words; dictionaries are safe for concurrent use and reloaded in place
+*/
+package dict
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/senayuki/mosaic/types"
+	"golang.org/x/text/cases"
+)
+
+// longest line of word lists
+const maxLineSize = 1 << 20
+
+type Dictionary struct {
+	match    types.KVDictMatch
+	caseFold bool
+	open     func() (io.ReadCloser, error) // source of Reload, nil if built from words
+
+	mu    sync.RWMutex
+	words map[string]struct{} // exact matching
+	ac    *automaton          // contains matching
+	size  int
+}
+
+// dictionary of words, config.File is ignored
+func New(words []string, config types.KVDictionary) (*Dictionary, error) {
+	d, err := newDictionary(config, nil)
+	if err != nil {
+		return nil, err
+	}
+	d.Set(words)
+	return d, nil
+}
+
+// dictionary of config.File in fsys, e.g. embed.FS or os.DirFS
+func Open(fsys fs.FS, config types.KVDictionary) (*Dictionary, error) {
+	return load(config, func() (io.ReadCloser, error) {
+		return fsys.Open(config.File)
+	})
+}
+
+/*
+dictionaries of configs in fsys by name, for rules from untrusted sources, e.g. services,
+files must be valid paths of fsys, so absolute paths & ".." can not escape root of fsys;
+any dictionary is an error if fsys is nil
+*/
+func OpenAll(fsys fs.FS, configs map[string]types.KVDictionary) (map[string]*Dictionary, error) {
+	dicts := make(map[string]*Dictionary, len(configs))
+	for name, config := range configs {
+		if fsys == nil {
+			return nil, fmt.Errorf("dictionary %q: dictionaries are not enabled", name)
+		}
+		if !fs.ValidPath(config.File) || config.File == "." {
+			return nil, fmt.Errorf("dictionary %q: invalid file %q, must be relative without . or ..", name, config.File)
+		}
+		d, err := Open(fsys, config)
+		if err != nil {
+			return nil, fmt.Errorf("dictionary %q: %w", name, err)
+		}
+		dicts[name] = d
+	}
+	return dicts, nil
+}
+
+// dictionary of config.File in file system, relative to working directory
+func OpenFile(config types.KVDictionary) (*Dictionary, error) {
+	return load(config, func() (io.ReadCloser, error) {
+		return os.Open(config.File)
+	})
+}
+
+func load(config types.KVDictionary, open func() (io.ReadCloser, error)) (*Dictionary, error) {
+	d, err := newDictionary(config, open)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func newDictionary(config types.KVDictionary, open func() (io.ReadCloser, error)) (*Dictionary, error) {
+	switch config.Match {
+	case types.KVDictMatchDefault, types.KVDictMatchExact, types.KVDictMatchContains:
+	default:
+		return nil, fmt.Errorf("unknown dictionary match %q", config.Match)
+	}
+	return &Dictionary{match: config.Match, caseFold: config.CaseFold, open: open}, nil
+}
+
+/*
+read words from source again, a dictionary built from words is kept as is
+words are replaced only if the source is read completely, so lookups never see a partial list
+*/
+func (d *Dictionary) Reload() error {
+	if d.open == nil {
+		return nil
+	}
+	f, err := d.open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	words, err := Read(f)
+	if err != nil {
+		return err
+	}
+	d.Set(words)
+	return nil
+}
+
+// replace words of dictionary
+func (d *Dictionary) Set(words []string) {
+	folded := make([]string, 0, len(words))
+	for _, word := range words {
+		if word != "" {
+			folded = append(folded, d.fold(word))
+		}
+	}
+	var set map[string]struct{}
+	var ac *automaton
+	var size int
+	if d.match == types.KVDictMatchContains {
+		ac = newAutomaton(folded)
+		size = ac.words
+	} else {
+		set = make(map[string]struct{}, len(folded))
+		for _, word := range folded {
+			set[word] = struct{}{}
+		}
+		size = len(set)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.words, d.ac, d.size = set, ac, size
+}
+
+// number of distinct words
+func (d *Dictionary) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.size
+}
+
+// whether s equals a word, or contains a word in contains matching
+func (d *Dictionary) Match(s string) bool {
+	s = d.fold(s)
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.ac != nil {
+		return d.ac.contains(s)
+	}
+	_, ok := d.words[s]
+	return ok
+}
+
+func (d *Dictionary) fold(s string) string {
+	if !d.caseFold {
+		return s
+	}
+	// caser is stateful, not shared between goroutines
+	return cases.Fold().String(s)
+}
+
+// words of list, one word per line, spaces around words, blank lines & lines starting with # are skipped
+func Read(r io.Reader) ([]string, error) {
+	var words []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineSize)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		words = append(words, word)
+	}
+	return words, scanner.Err()
+}
+
+/*
+Aho-Corasick automaton over bytes, nodes are numbered in breadth-first order
+edges of a node are contiguous in edges & sorted by byte, node 0 is root
+*/
+type automaton struct {
+	nodes []acNode
+	edges []acEdge
+	words int // distinct words
+}
+
+type acNode struct {
+	first, count int32 // edges[first : first+count]
+	fail         int32
+	out          bool // a word ends here or at a node of fail chain
+}
+
+type acEdge struct {
+	b  byte
+	to int32
+}
+
+/*
+build trie from sorted words breadth-first, words sharing prefix of a node are a range of sorted words,
+so children of a node are groups of the range by byte at depth of node; fail links are set in the same pass
+as nodes of fail chain are shallower and already have their edges
+*/
+func newAutomaton(words []string) *automaton {
+	words = append([]string(nil), words...)
+	sort.Strings(words)
+	type task struct {
+		node, lo, hi, depth int
+	}
+	a := &automaton{nodes: []acNode{{}}}
+	queue := []task{{0, 0, len(words), 0}}
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		// words ending at node sort first, duplicates are counted once
+		lo := t.lo
+		for lo < t.hi && len(words[lo]) == t.depth {
+			if lo == t.lo {
+				a.nodes[t.node].out = true
+				a.words++
+			}
+			lo++
+		}
+		a.nodes[t.node].first = int32(len(a.edges))
+		for lo < t.hi {
+			b := words[lo][t.depth]
+			hi := lo + 1
+			for hi < t.hi && words[hi][t.depth] == b {
+				hi++
+			}
+			child := int32(len(a.nodes))
+			a.nodes = append(a.nodes, acNode{fail: a.failOf(t.node, b)})
+			a.edges = append(a.edges, acEdge{b: b, to: child})
+			queue = append(queue, task{int(child), lo, hi, t.depth + 1})
+			lo = hi
+		}
+		a.nodes[t.node].count = int32(len(a.edges)) - a.nodes[t.node].first
+		if t.node != 0 {
+			a.nodes[t.node].out = a.nodes[t.node].out || a.nodes[a.nodes[t.node].fail].out
+		}
+	}
+	return a
+}
+
+// fail link of child of parent by b
+func (a *automaton) failOf(parent int, b byte) int32 {
+	if parent == 0 {
+		return 0
+	}
+	return a.next(a.nodes[parent].fail, b)
+}
+
+// transition by b, following fail links
+func (a *automaton) next(state int32, b byte) int32 {
+	for {
+		if to, ok := a.edge(state, b); ok {
+			return to
+		}
+		if state == 0 {
+			return 0
+		}
+		state = a.nodes[state].fail
+	}
+}
+
+func (a *automaton) edge(state int32, b byte) (int32, bool) {
+	node := a.nodes[state]
+	edges := a.edges[node.first : node.first+node.count]
+	lo, hi := 0, len(edges)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch {
+		case edges[mid].b == b:
+			return edges[mid].to, true
+		case edges[mid].b < b:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return 0, false
+}
+
+// whether s contains any word
+func (a *automaton) contains(s string) bool {
+	var state int32
+	for idx := 0; idx < len(s); idx++ {
+		state = a.next(state, s[idx])
+		if a.nodes[state].out {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package dict
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+func TestDictionary_Match(t *testing.T) {
+	words := []string{"Alice Zhang", "project falcon", "he", "she", "hers", "Straße", ""}
+	tests := []struct {
+		name   string
+		config types.KVDictionary
+		in     string
+		want   bool
+	}{
+		{name: "exact", in: "Alice Zhang", want: true},
+		{name: "exact is case-sensitive", in: "alice zhang", want: false},
+		{name: "exact not contains", in: "Alice Zhang Jr.", want: false},
+		{name: "empty word is skipped", in: "", want: false},
+		{name: "exact fold", config: types.KVDictionary{CaseFold: true}, in: "ALICE ZHANG", want: true},
+		{name: "unicode fold", config: types.KVDictionary{CaseFold: true}, in: "STRASSE", want: true},
+		{name: "contains", config: types.KVDictionary{Match: types.KVDictMatchContains}, in: "weekly sync of project falcon", want: true},
+		{name: "contains by fail link", config: types.KVDictionary{Match: types.KVDictMatchContains}, in: "ushers", want: true},
+		{name: "contains nothing", config: types.KVDictionary{Match: types.KVDictMatchContains}, in: "project eagle", want: false},
+		{name: "contains fold", config: types.KVDictionary{Match: types.KVDictMatchContains, CaseFold: true}, in: "PROJECT FALCON", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := New(words, tt.config)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if got := d.Match(tt.in); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+	if _, err := New(words, types.KVDictionary{Match: "prefix"}); err == nil {
+		t.Errorf("New() unknown match error = nil")
+	}
+}
+
+func TestDictionary_Large(t *testing.T) {
+	words := make([]string, 100000)
+	for idx := range words {
+		words[idx] = fmt.Sprintf("ACCT-%07d", idx*7)
+	}
+	for _, match := range []types.KVDictMatch{types.KVDictMatchExact, types.KVDictMatchContains} {
+		d, err := New(append(words, words[:10]...), types.KVDictionary{Match: match})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if d.Len() != len(words) {
+			t.Errorf("%s: Len() = %d, want %d", match, d.Len(), len(words))
+		}
+		if !d.Match("ACCT-0699965") || d.Match("ACCT-0699966") {
+			t.Errorf("%s: Match() of large dictionary is wrong", match)
+		}
+	}
+}
+
+func TestOpen_Reload(t *testing.T) {
+	fsys := fstest.MapFS{"names.txt": {Data: []byte("# employees\n  alice  \n\nbob\n")}}
+	d, err := Open(fsys, types.KVDictionary{File: "names.txt"})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if d.Len() != 2 || !d.Match("alice") || d.Match("# employees") {
+		t.Errorf("Open() Len() = %d", d.Len())
+	}
+	fsys["names.txt"] = &fstest.MapFile{Data: []byte("carol\n")}
+	if err := d.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if !d.Match("carol") || d.Match("alice") {
+		t.Errorf("Reload() words are not replaced")
+	}
+	// words are kept if source fails
+	fsys["names.txt"] = &fstest.MapFile{Data: []byte(strings.Repeat("x", maxLineSize+1))}
+	if err := d.Reload(); err == nil {
+		t.Errorf("Reload() long line error = nil")
+	}
+	if !d.Match("carol") {
+		t.Errorf("Reload() failed but words are replaced")
+	}
+	if _, err := Open(fsys, types.KVDictionary{File: "missing.txt"}); err == nil {
+		t.Errorf("Open() missing file error = nil")
+	}
+}
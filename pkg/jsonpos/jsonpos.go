@@ -0,0 +1,297 @@
+// positions of JSON values in source, fastjson does not keep offsets
+package jsonpos
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+// byte span [Start, End) in input
+type Span struct {
+	Start int
+	End   int
+}
+
+// location of value, Key is empty for array elements & root
+// spans of strings include quotes
+type Location struct {
+	Key Span
+	Val Span
+}
+
+// 1-based line & column, column counts unicode code points
+type Position struct {
+	Line   int
+	Column int
+}
+
+// locations of all values in a JSON document, keyed by path
+// members of duplicated keys are located at the first one, like fastjson
+type Index struct {
+	*Lines
+	locations map[string]Location
+}
+
+// positions of byte offsets in input, e.g. free text
+type Lines struct {
+	input  []byte
+	starts []int // offsets of line starts
+}
+
+func NewLines(input []byte) *Lines {
+	l := &Lines{input: input, starts: []int{0}}
+	for i, c := range input {
+		if c == '\n' {
+			l.starts = append(l.starts, i+1)
+		}
+	}
+	return l
+}
+
+// scan JSON document, returns error at offset if input is not valid JSON
+func Parse(input []byte) (*Index, error) {
+	s := scanner{input: input, locations: map[string]Location{}}
+	s.skipSpace()
+	start := s.pos
+	if err := s.value(nil); err != nil {
+		return nil, err
+	}
+	s.locations[""] = Location{Val: Span{Start: start, End: s.pos}}
+	s.skipSpace()
+	if s.pos < len(input) {
+		return nil, s.errorf("unexpected %q after top-level value", input[s.pos])
+	}
+	return &Index{Lines: NewLines(input), locations: s.locations}, nil
+}
+
+/*
+location of value at path, path of embedded payload is located at the string it is decoded from
+false if path does not exist
+*/
+func (idx *Index) Locate(path types.JSONPath) (Location, bool) {
+	elements := path.Elements()
+	for i, elem := range elements {
+		if _, ok := elem.(types.JSONPathDecode); ok {
+			elements = elements[:i]
+			break
+		}
+	}
+	loc, ok := idx.locations[pathKey(elements)]
+	return loc, ok
+}
+
+// position of byte offset, offset in the middle of a rune is counted as the rune
+func (l *Lines) Position(offset int) Position {
+	if offset > len(l.input) {
+		offset = len(l.input)
+	}
+	line := sort.Search(len(l.starts), func(i int) bool { return l.starts[i] > offset }) - 1
+	return Position{Line: line + 1, Column: utf8.RuneCount(l.input[l.starts[line]:offset]) + 1}
+}
+
+// unique key of path, object keys are quoted
+func pathKey(elements []interface{}) string {
+	var key strings.Builder
+	for _, elem := range elements {
+		key.WriteByte('/')
+		switch v := elem.(type) {
+		case string:
+			key.WriteString(strconv.Quote(v))
+		case int:
+			key.WriteString(strconv.Itoa(v))
+		default:
+			fmt.Fprint(&key, v)
+		}
+	}
+	return key.String()
+}
+
+type scanner struct {
+	input     []byte
+	pos       int
+	locations map[string]Location
+}
+
+func (s *scanner) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("offset %d: %s", s.pos, fmt.Sprintf(format, args...))
+}
+
+func (s *scanner) skipSpace() {
+	for s.pos < len(s.input) {
+		switch s.input[s.pos] {
+		case ' ', '\t', '\r', '\n':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// scan value at pos, locations of nested values are recorded under path
+func (s *scanner) value(path []interface{}) error {
+	if s.pos >= len(s.input) {
+		return s.errorf("unexpected end of input")
+	}
+	switch c := s.input[s.pos]; {
+	case c == '{':
+		return s.object(path)
+	case c == '[':
+		return s.array(path)
+	case c == '"':
+		_, err := s.str()
+		return err
+	case c == '-' || (c >= '0' && c <= '9'):
+		return s.number()
+	default:
+		for _, literal := range []string{"true", "false", "null"} {
+			if bytes.HasPrefix(s.input[s.pos:], []byte(literal)) {
+				s.pos += len(literal)
+				return nil
+			}
+		}
+		return s.errorf("unexpected %q", c)
+	}
+}
+
+func (s *scanner) object(path []interface{}) error {
+	s.pos++ // {
+	s.skipSpace()
+	if s.pos < len(s.input) && s.input[s.pos] == '}' {
+		s.pos++
+		return nil
+	}
+	for {
+		s.skipSpace()
+		if s.pos >= len(s.input) || s.input[s.pos] != '"' {
+			return s.errorf("expected object key")
+		}
+		keyStart := s.pos
+		key, err := s.str()
+		if err != nil {
+			return err
+		}
+		keySpan := Span{Start: keyStart, End: s.pos}
+		s.skipSpace()
+		if s.pos >= len(s.input) || s.input[s.pos] != ':' {
+			return s.errorf("expected ':'")
+		}
+		s.pos++
+		s.skipSpace()
+		memberPath := append(path[:len(path):len(path)], key)
+		valStart := s.pos
+		if err := s.value(memberPath); err != nil {
+			return err
+		}
+		if k := pathKey(memberPath); !s.has(k) {
+			s.locations[k] = Location{Key: keySpan, Val: Span{Start: valStart, End: s.pos}}
+		}
+		s.skipSpace()
+		if s.pos >= len(s.input) {
+			return s.errorf("unexpected end of object")
+		}
+		switch s.input[s.pos] {
+		case ',':
+			s.pos++
+		case '}':
+			s.pos++
+			return nil
+		default:
+			return s.errorf("expected ',' or '}'")
+		}
+	}
+}
+
+func (s *scanner) has(key string) bool {
+	_, ok := s.locations[key]
+	return ok
+}
+
+func (s *scanner) array(path []interface{}) error {
+	s.pos++ // [
+	s.skipSpace()
+	if s.pos < len(s.input) && s.input[s.pos] == ']' {
+		s.pos++
+		return nil
+	}
+	for idx := 0; ; idx++ {
+		s.skipSpace()
+		elemPath := append(path[:len(path):len(path)], idx)
+		valStart := s.pos
+		if err := s.value(elemPath); err != nil {
+			return err
+		}
+		if k := pathKey(elemPath); !s.has(k) {
+			s.locations[k] = Location{Val: Span{Start: valStart, End: s.pos}}
+		}
+		s.skipSpace()
+		if s.pos >= len(s.input) {
+			return s.errorf("unexpected end of array")
+		}
+		switch s.input[s.pos] {
+		case ',':
+			s.pos++
+		case ']':
+			s.pos++
+			return nil
+		default:
+			return s.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+// scan string at pos, returns unescaped string
+func (s *scanner) str() (string, error) {
+	start := s.pos
+	s.pos++ // "
+	escaped := false
+	for s.pos < len(s.input) {
+		switch s.input[s.pos] {
+		case '\\':
+			escaped = true
+			s.pos += 2
+		case '"':
+			s.pos++
+			raw := s.input[start:s.pos]
+			if !escaped {
+				return string(raw[1 : len(raw)-1]), nil
+			}
+			var str string
+			if err := json.Unmarshal(raw, &str); err != nil {
+				return "", fmt.Errorf("offset %d: %w", start, err)
+			}
+			return str, nil
+		default:
+			s.pos++
+		}
+	}
+	return "", s.errorf("unterminated string")
+}
+
+func (s *scanner) number() error {
+	start := s.pos
+	for s.pos < len(s.input) {
+		switch c := s.input[s.pos]; {
+		case c >= '0' && c <= '9', c == '-', c == '+', c == '.', c == 'e', c == 'E':
+			s.pos++
+			continue
+		}
+		break
+	}
+	if _, err := strconv.ParseFloat(string(s.input[start:s.pos]), 64); err != nil && !isRangeError(err) {
+		return fmt.Errorf("offset %d: invalid number %q", start, s.input[start:s.pos])
+	}
+	return nil
+}
+
+// big numbers are valid JSON
+func isRangeError(err error) bool {
+	numErr, ok := err.(*strconv.NumError)
+	return ok && numErr.Err == strconv.ErrRange
+}
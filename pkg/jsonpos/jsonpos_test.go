@@ -0,0 +1,59 @@
+package jsonpos
+
+import (
+	"testing"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+func TestIndex_Locate(t *testing.T) {
+	input := "{\n  \"user\": {\"name\": \"张三\", \"tags\": [1, \"a\\\"b\"]},\n  \"k\\u0065y\": null,\n  \"payload\": \"{}\",\n  \"user\": 1\n}"
+	idx, err := Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		path    types.JSONPath
+		wantKey string
+		wantVal string
+		wantPos Position
+		wantOk  bool
+	}{
+		{name: "root", path: types.NewJSONPath(), wantVal: input, wantPos: Position{Line: 1, Column: 1}, wantOk: true},
+		{name: "member", path: types.NewJSONPath().Append("user", "name"), wantKey: `"name"`, wantVal: `"张三"`, wantPos: Position{Line: 2, Column: 20}, wantOk: true},
+		{name: "element", path: types.NewJSONPath().Append("user", "tags", 1), wantVal: `"a\"b"`, wantPos: Position{Line: 2, Column: 38}, wantOk: true},
+		{name: "escaped key", path: types.NewJSONPath().Append("key"), wantKey: `"k\u0065y"`, wantVal: "null", wantPos: Position{Line: 3, Column: 15}, wantOk: true},
+		{name: "embedded payload", path: types.NewJSONPath().Append("payload", types.JSONPathDecode(types.KVDecoderJSON), "a"), wantKey: `"payload"`, wantVal: `"{}"`, wantPos: Position{Line: 4, Column: 14}, wantOk: true},
+		{name: "first duplicated key", path: types.NewJSONPath().Append("user", "tags", 0), wantVal: "1", wantPos: Position{Line: 2, Column: 35}, wantOk: true},
+		{name: "not found", path: types.NewJSONPath().Append("user", "age")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, ok := idx.Locate(tt.path)
+			if ok != tt.wantOk {
+				t.Fatalf("Locate() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !ok {
+				return
+			}
+			if got := input[loc.Key.Start:loc.Key.End]; got != tt.wantKey {
+				t.Errorf("Locate() key = %v, want %v", got, tt.wantKey)
+			}
+			if got := input[loc.Val.Start:loc.Val.End]; got != tt.wantVal {
+				t.Errorf("Locate() val = %v, want %v", got, tt.wantVal)
+			}
+			if got := idx.Position(loc.Val.Start); got != tt.wantPos {
+				t.Errorf("Position() = %+v, want %+v", got, tt.wantPos)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, input := range []string{``, `{`, `{"a" 1}`, `[1,]`, `"abc`, `{"a":1} x`, `tru`, `-`} {
+		if _, err := Parse([]byte(input)); err == nil {
+			t.Errorf("Parse(%q) error = nil, want error", input)
+		}
+	}
+}
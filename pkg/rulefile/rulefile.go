@@ -0,0 +1,171 @@
+// rules files in YAML or JSON, shared by command, serverless & services
+package rulefile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/senayuki/mosaic/pkg/dict"
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/rulepack"
+	"github.com/senayuki/mosaic/ruleset"
+	"github.com/senayuki/mosaic/types"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+parse rules, fields are named like types.KVRules and matched case-insensitively:
+
+	detectRules:
+	  - keyEqs: [password, token]
+	    maskRef: cover
+	maskRules:
+	  - ruleName: cover
+	    maskType: cover
+
+name with .json extension is parsed as JSON, others as YAML converted to JSON
+unknown fields are errors
+
+rules may extend a rule pack and disable or override its rules by ID, see rulepack:
+
+	extends: pci-dss@1.0.0
+	disable: [pci.expiry]
+*/
+func Parse(name string, data []byte) (types.KVRules, error) {
+	set, err := ParseSet(name, data)
+	if err != nil || set.Extends == "" && len(set.Disable) == 0 {
+		return set.KVRules, err
+	}
+	sets := map[string]types.KVRuleSet{}
+	for _, pack := range rulepack.Sets() {
+		sets[pack.Name] = pack
+	}
+	// keyed by file name with extension, so rules file never shadows pack of the same name
+	sets[name] = set
+	return ruleset.Resolve(sets, name)
+}
+
+/*
+parse rule set, fields of types.KVRules are inline next to name, extends & disable:
+
+	extends: baseline
+	disable: [phone]
+	detectRules:
+	  - id: password
+	    keyEqs: [password, passwd]
+
+name of rule set is base name of file without extension if omitted
+*/
+func ParseSet(name string, data []byte) (types.KVRuleSet, error) {
+	var set types.KVRuleSet
+	if err := decode(name, data, &set); err != nil {
+		return set, err
+	}
+	if set.Name == "" {
+		set.Name = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	}
+	return set, nil
+}
+
+// parse all rules files of dir in fsys as rule sets, files are matched by extension
+func LoadSets(fsys fs.FS, dir string) ([]types.KVRuleSet, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var sets []types.KVRuleSet
+	for _, entry := range entries {
+		if entry.IsDir() || !isRulesFile(entry.Name()) {
+			continue
+		}
+		name := path.Join(dir, entry.Name())
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		set, err := ParseSet(name, data)
+		if err != nil {
+			return nil, fmt.Errorf("rule set %s: %w", name, err)
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
+
+// extensions of rules files
+var exts = []string{".json", ".yaml", ".yml"}
+
+func isRulesFile(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	for _, e := range exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+func decode(name string, data []byte, v interface{}) error {
+	if ext := strings.ToLower(filepath.Ext(name)); ext != ".json" {
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			return err
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+/*
+read, parse & compile rules file in fsys, e.g. embed.FS or os.DirFS
+relative files of dictionaries are read from fsys relative to the rules file,
+and returned relative to root of fsys, absolute ones are read from file system
+*/
+func Load(fsys fs.FS, name string) (types.KVRules, processer.KVProcesser, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return types.KVRules{}, processer.KVProcesser{}, err
+	}
+	rules, err := Parse(name, data)
+	if err != nil {
+		return rules, processer.KVProcesser{}, fmt.Errorf("rules %s: %w", name, err)
+	}
+	dicts, err := openDictionaries(fsys, path.Dir(name), rules.Dictionaries)
+	if err != nil {
+		return rules, processer.KVProcesser{}, fmt.Errorf("rules %s: Dictionaries: %w", name, err)
+	}
+	m, err := processer.CompileKVProcesserWith(rules, dicts)
+	if err != nil {
+		return rules, processer.KVProcesser{}, fmt.Errorf("rules %s: %w", name, err)
+	}
+	return rules, m, nil
+}
+
+// open dictionaries in fsys relative to dir, files of configs are rewritten relative to root of fsys
+func openDictionaries(fsys fs.FS, dir string, configs map[string]types.KVDictionary) (map[string]*dict.Dictionary, error) {
+	dicts := make(map[string]*dict.Dictionary, len(configs))
+	for name, config := range configs {
+		if config.File == "" || filepath.IsAbs(config.File) {
+			// left to processer
+			continue
+		}
+		config.File = path.Join(dir, filepath.ToSlash(config.File))
+		d, err := dict.Open(fsys, config)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", name, err)
+		}
+		configs[name] = config
+		dicts[name] = d
+	}
+	return dicts, nil
+}
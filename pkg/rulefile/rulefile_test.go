@@ -0,0 +1,161 @@
+package rulefile
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+)
+
+func TestParse(t *testing.T) {
+	want := types.KVRules{
+		DetectRules: []types.KVDetectConfig{{KeyEqs: []string{"password"}, MaskRef: "hash"}},
+		MaskRules:   []types.KVMaskConfig{{RuleName: "hash", MaskType: types.MaskTypeCover, CoverParam: types.MaskRuleCoverParam{Char: "#"}}},
+	}
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "yaml",
+			file: "rules.yaml",
+			data: "detectRules:\n  - keyEqs: [password]\n    maskRef: hash\nmaskRules:\n  - ruleName: hash\n    maskType: cover\n    coverParam: {char: '#'}\n",
+		},
+		{
+			name: "json",
+			file: "rules.json",
+			data: `{"DetectRules":[{"KeyEqs":["password"],"MaskRef":"hash"}],"MaskRules":[{"RuleName":"hash","MaskType":"cover","CoverParam":{"Char":"#"}}]}`,
+		},
+		{
+			name:    "unknown field",
+			file:    "rules.yml",
+			data:    "detectRules:\n  - keyEquals: [password]\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.file, []byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, want) {
+				t.Errorf("Parse() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"rules.yaml":   {Data: []byte("detectRules:\n  - keyEqs: [password]\n")},
+		"invalid.yaml": {Data: []byte("detectRules:\n  - keyRegex: ['(']\n")},
+	}
+	if _, _, err := Load(fsys, "rules.yaml"); err != nil {
+		t.Errorf("Load() error = %v", err)
+	}
+	if _, _, err := Load(fsys, "invalid.yaml"); err == nil {
+		t.Errorf("Load() invalid regex error = nil")
+	}
+	if _, _, err := Load(fsys, "missing.yaml"); err == nil {
+		t.Errorf("Load() missing file error = nil")
+	}
+}
+
+func TestLoad_Dictionaries(t *testing.T) {
+	fsys := fstest.MapFS{
+		"rules/rules.yaml":         {Data: []byte("dictionaries:\n  employees: {file: dict/employees.txt, caseFold: true}\ndetectRules:\n  - valDictionary: employees\n")},
+		"rules/dict/employees.txt": {Data: []byte("Alice Zhang\n")},
+		"missing.yaml":             {Data: []byte("dictionaries:\n  employees: {file: employees.txt}\n")},
+	}
+	rules, m, err := Load(fsys, "rules/rules.yaml")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := rules.Dictionaries["employees"].File; got != "rules/dict/employees.txt" {
+		t.Errorf("Load() dictionary file = %v", got)
+	}
+	if pairs, _ := m.Detect([]byte(`{"owner":"ALICE ZHANG"}`)); len(pairs) != 1 {
+		t.Errorf("Detect() = %+v, want 1 pair", pairs)
+	}
+	if _, _, err := Load(fsys, "missing.yaml"); err == nil {
+		t.Errorf("Load() missing dictionary error = nil")
+	}
+}
+
+func TestLoadSets(t *testing.T) {
+	fsys := fstest.MapFS{
+		"tenants/baseline.yaml": {Data: []byte("detectRules:\n  - id: password\n    keyEqs: [password]\n")},
+		"tenants/payments.json": {Data: []byte(`{"Extends":"baseline","Disable":["password"],"DetectRules":[{"KeyEqs":["pan"]}]}`)},
+		"tenants/named.yml":     {Data: []byte("name: support\nextends: baseline\n")},
+		"tenants/README.md":     {Data: []byte("# tenants")},
+	}
+	sets, err := LoadSets(fsys, "tenants")
+	if err != nil {
+		t.Fatalf("LoadSets() error = %v", err)
+	}
+	want := []types.KVRuleSet{
+		{Name: "baseline", KVRules: types.KVRules{DetectRules: []types.KVDetectConfig{{ID: "password", KeyEqs: []string{"password"}}}}},
+		{Name: "support", Extends: "baseline"},
+		{Name: "payments", Extends: "baseline", Disable: []string{"password"}, KVRules: types.KVRules{DetectRules: []types.KVDetectConfig{{KeyEqs: []string{"pan"}}}}},
+	}
+	if !reflect.DeepEqual(sets, want) {
+		t.Errorf("LoadSets() = %+v, want %+v", sets, want)
+	}
+}
+
+func TestParse_Extends(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantIDs []string // IDs of the last detect rules
+		wantErr bool
+	}{
+		{
+			name:    "pinned pack with disable & own rule",
+			data:    "extends: pci-dss@1.0.0\ndisable: [pci.expiry]\ndetectRules:\n  - id: pci.cvv\n    keyEqs: [cvc]\n    maskRef: pci.drop\n  - keyEqs: [password]\n",
+			wantIDs: []string{"pci.cardholder-name", ""},
+		},
+		{
+			name:    "unknown pack",
+			data:    "extends: pci-dss@0.1.0\n",
+			wantErr: true,
+		},
+		{
+			name:    "disable unknown rule",
+			data:    "extends: hipaa\ndisable: [pci.cvv]\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := Parse("pci-dss.yaml", []byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var ids []string
+			for _, rule := range rules.DetectRules {
+				if rule.ID == "pci.expiry" {
+					t.Errorf("Parse() disabled rule %q is kept", rule.ID)
+				}
+				if rule.ID == "pci.cvv" && rule.KeyEqs[0] != "cvc" {
+					t.Errorf("Parse() rule %q is not overridden: %+v", rule.ID, rule)
+				}
+				ids = append(ids, rule.ID)
+			}
+			if got := ids[len(ids)-len(tt.wantIDs):]; !reflect.DeepEqual(got, tt.wantIDs) {
+				t.Errorf("Parse() last rule IDs = %v, want %v", got, tt.wantIDs)
+			}
+			if _, err := processer.CompileKVProcesser(rules); err != nil {
+				t.Errorf("CompileKVProcesser() error = %v", err)
+			}
+		})
+	}
+}
@@ -72,3 +72,64 @@ func BenchmarkBytes2Runes(b *testing.B) {
 		Bytes2Runes(bytes)
 	}
 }
+
+func TestSplitWords(t *testing.T) {
+	type args struct {
+		in string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "camelCase",
+			args: args{
+				in: "phoneNumber",
+			},
+			want: []string{"phone", "Number"},
+		},
+		{
+			name: "snake & kebab",
+			args: args{
+				in: "PHONE-NUMBER_ext",
+			},
+			want: []string{"PHONE", "NUMBER", "ext"},
+		},
+		{
+			name: "acronym",
+			args: args{
+				in: "HTTPServer2Url",
+			},
+			want: []string{"HTTP", "Server2", "Url"},
+		},
+		{
+			name: "repeated separators",
+			args: args{
+				in: "__user  id__",
+			},
+			want: []string{"user", "id"},
+		},
+		{
+			name: "CJK",
+			args: args{
+				in: "手机号",
+			},
+			want: []string{"手机号"},
+		},
+		{
+			name: "empty",
+			args: args{
+				in: "",
+			},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitWords(tt.args.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitWords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package str
+
+import (
+	"unicode"
+)
+
+// split identifier into words by separators (_ - . space) and camelCase boundaries
+// "phoneNumber", "phone_number", "PHONE-NUMBER" -> ["phone", "Number"], ["phone", "number"], ["PHONE", "NUMBER"]
+// "HTTPServer" -> ["HTTP", "Server"]
+func SplitWords(in string) []string {
+	runes := []rune(in)
+	words := make([]string, 0, 4)
+	start := -1
+	for idx, r := range runes {
+		if r == '_' || r == '-' || r == '.' || unicode.IsSpace(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:idx]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = idx
+			continue
+		}
+		prev := runes[idx-1]
+		boundary := false
+		if unicode.IsUpper(r) {
+			// aB -> a|B, ABc -> A|Bc
+			boundary = unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && idx+1 < len(runes) && unicode.IsLower(runes[idx+1]))
+		}
+		if boundary {
+			words = append(words, string(runes[start:idx]))
+			start = idx
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
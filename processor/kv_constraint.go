@@ -0,0 +1,248 @@
+package processer
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/senayuki/mosaic/pkg/checksum"
+	"github.com/senayuki/mosaic/pkg/decimal"
+	"github.com/senayuki/mosaic/types"
+)
+
+// numeric range in interval notation, nil bound is unlimited
+type numRange struct {
+	min, max         *big.Rat
+	minOpen, maxOpen bool
+}
+
+func parseNumRange(input string) (*numRange, error) {
+	input = strings.TrimSpace(input)
+	if len(input) < 3 {
+		return nil, fmt.Errorf("invalid range %q", input)
+	}
+	r := &numRange{}
+	switch input[0] {
+	case '[':
+	case '(':
+		r.minOpen = true
+	default:
+		return nil, fmt.Errorf("invalid range %q: must start with [ or (", input)
+	}
+	switch input[len(input)-1] {
+	case ']':
+	case ')':
+		r.maxOpen = true
+	default:
+		return nil, fmt.Errorf("invalid range %q: must end with ] or )", input)
+	}
+	bounds := strings.Split(input[1:len(input)-1], ",")
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("invalid range %q: must have 2 bounds", input)
+	}
+	for idx, bound := range bounds {
+		bound = strings.TrimSpace(bound)
+		if bound == "" {
+			continue
+		}
+		num, ok := new(big.Rat).SetString(bound)
+		if !ok {
+			return nil, fmt.Errorf("invalid range %q: bound %q is not a number", input, bound)
+		}
+		if idx == 0 {
+			r.min = num
+		} else {
+			r.max = num
+		}
+	}
+	if r.min != nil && r.max != nil && r.min.Cmp(r.max) > 0 {
+		return nil, fmt.Errorf("invalid range %q: min is bigger than max", input)
+	}
+	return r, nil
+}
+
+func (r *numRange) contains(num *big.Rat) bool {
+	if r.min != nil {
+		cmp := num.Cmp(r.min)
+		if cmp < 0 || (cmp == 0 && r.minOpen) {
+			return false
+		}
+	}
+	if r.max != nil {
+		cmp := num.Cmp(r.max)
+		if cmp > 0 || (cmp == 0 && r.maxOpen) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasValType(valTypes []types.KVValType, valType types.KVValType) bool {
+	for _, t := range valTypes {
+		if t == valType {
+			return true
+		}
+	}
+	return false
+}
+
+func validValTypes(valTypes []types.KVValType) error {
+	for _, valType := range valTypes {
+		switch valType {
+		case types.KVValTypeString, types.KVValTypeNumber, types.KVValTypeInteger, types.KVValTypeBool, types.KVValTypeNull:
+		default:
+			return fmt.Errorf("unknown value type %q", valType)
+		}
+	}
+	return nil
+}
+
+// value constraints of config, checked before criteria
+func (m KVProcesser) matchConstraint(configIdx int, pair types.KVPair, valString string) bool {
+	config := &m.detectConfig[configIdx]
+	valType := pair.GetValType()
+	// numbers are parsed only if checked, parsing is not cheap for big numbers
+	var num *big.Rat
+	if valType == types.KVValTypeNumber && (m.detectExp[configIdx].ValRange != nil || hasValType(config.ValTypes, types.KVValTypeInteger)) {
+		num, _ = decimal.ParseRat(valString)
+	}
+	if len(config.ValTypes) > 0 {
+		allowed := false
+		for _, t := range config.ValTypes {
+			if t == valType || (t == types.KVValTypeInteger && num != nil && num.IsInt()) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	if config.ValMinLen > 0 || config.ValMaxLen > 0 {
+		length := utf8.RuneCountInString(valString)
+		if length < config.ValMinLen || (config.ValMaxLen > 0 && length > config.ValMaxLen) {
+			return false
+		}
+	}
+	if r := m.detectExp[configIdx].ValRange; r != nil {
+		if num == nil || !r.contains(num) {
+			return false
+		}
+	}
+	if config.ValChecksum != "" && !validChecksum(config.ValChecksum, valString) {
+		return false
+	}
+	return true
+}
+
+func validChecksumType(kind types.KVChecksum) error {
+	switch kind {
+	case "", types.KVChecksumLuhn, types.KVChecksumCNID, types.KVChecksumDNI, types.KVChecksumCF:
+		return nil
+	}
+	return fmt.Errorf("unknown checksum %q", kind)
+}
+
+// check digit of value, spaces & dashes are ignored
+func validChecksum(kind types.KVChecksum, val string) bool {
+	chars := make([]byte, 0, len(val))
+	for idx := 0; idx < len(val); idx++ {
+		switch c := val[idx]; c {
+		case ' ', '-':
+		default:
+			chars = append(chars, c)
+		}
+	}
+	switch kind {
+	case types.KVChecksumLuhn:
+		return checksum.LuhnValid(chars)
+	case types.KVChecksumCNID:
+		return cnidValid(chars)
+	case types.KVChecksumDNI:
+		return dniValid(chars)
+	case types.KVChecksumCF:
+		return codiceFiscaleValid(chars)
+	}
+	return false
+}
+
+// 17 digits & check digit of ISO 7064 MOD 11-2, X is 10
+func cnidValid(chars []byte) bool {
+	if len(chars) != 18 {
+		return false
+	}
+	sum := 0
+	for idx, c := range chars[:17] {
+		if c < '0' || c > '9' {
+			return false
+		}
+		sum += int(c-'0') * cnidWeights[idx]
+	}
+	check := chars[17]
+	if check == 'x' {
+		check = 'X'
+	}
+	return check == "10X98765432"[sum%11]
+}
+
+var cnidWeights = [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+
+/*
+8 digits & control letter of DNI, or NIE of X, Y, Z as 0, 1, 2, 7 digits & control letter,
+control letter is number mod 23 in dniLetters
+*/
+func dniValid(chars []byte) bool {
+	if len(chars) != 9 {
+		return false
+	}
+	num := 0
+	for idx, c := range chars[:8] {
+		switch {
+		case c >= '0' && c <= '9':
+			num = num*10 + int(c-'0')
+		case idx == 0 && strings.IndexByte("XYZ", upper(c)) >= 0:
+			num = strings.IndexByte("XYZ", upper(c))
+		default:
+			return false
+		}
+	}
+	return upper(chars[8]) == dniLetters[num%23]
+}
+
+const dniLetters = "TRWAGMYFPDXBNJZSQVHLCKE"
+
+// 15 letters & digits and check letter of Italian codice fiscale, letters replacing digits by omocodia count as letters
+func codiceFiscaleValid(chars []byte) bool {
+	if len(chars) != 16 {
+		return false
+	}
+	sum := 0
+	for idx, c := range chars[:15] {
+		var v int
+		switch c = upper(c); {
+		case c >= '0' && c <= '9':
+			v = int(c - '0')
+		case c >= 'A' && c <= 'Z':
+			v = int(c - 'A')
+		default:
+			return false
+		}
+		// odd positions counting from 1
+		if idx%2 == 0 {
+			v = cfOddValues[v]
+		}
+		sum += v
+	}
+	return upper(chars[15]) == byte('A'+sum%26)
+}
+
+// values of characters at odd positions, digits are valued as letters A to J
+var cfOddValues = [26]int{1, 0, 5, 7, 9, 13, 15, 17, 19, 21, 2, 4, 18, 20, 11, 3, 6, 8, 12, 14, 16, 10, 22, 25, 24, 23}
+
+func upper(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
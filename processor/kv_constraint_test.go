@@ -0,0 +1,131 @@
+package processer
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+func TestParseNumRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		contains []string
+		excludes []string
+		wantErr  bool
+	}{
+		{
+			name:     "half-open",
+			input:    "[1e10, 1e11)",
+			contains: []string{"10000000000", "99999999999"},
+			excludes: []string{"9999999999", "100000000000"},
+		},
+		{
+			name:     "unlimited max",
+			input:    "(0,]",
+			contains: []string{"0.0001", "123456789012345678901234567890"},
+			excludes: []string{"0", "-1"},
+		},
+		{
+			name:     "unlimited min & decimals",
+			input:    "[ , 3.14]",
+			contains: []string{"3.14", "-100"},
+			excludes: []string{"3.1401"},
+		},
+		{
+			name:    "missing bracket",
+			input:   "1, 2",
+			wantErr: true,
+		},
+		{
+			name:    "not a number",
+			input:   "[a, 2]",
+			wantErr: true,
+		},
+		{
+			name:    "min bigger than max",
+			input:   "[2, 1]",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := parseNumRange(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseNumRange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			for _, num := range tt.contains {
+				rat, _ := new(big.Rat).SetString(num)
+				if !r.contains(rat) {
+					t.Errorf("contains(%s) = false, want true", num)
+				}
+			}
+			for _, num := range tt.excludes {
+				rat, _ := new(big.Rat).SetString(num)
+				if r.contains(rat) {
+					t.Errorf("contains(%s) = true, want false", num)
+				}
+			}
+		})
+	}
+}
+
+func TestValidChecksum(t *testing.T) {
+	tests := []struct {
+		checksum types.KVChecksum
+		val      string
+		want     bool
+	}{
+		{types.KVChecksumLuhn, "4111111111111111", true},
+		{types.KVChecksumLuhn, "4111-1111 1111-1111", true},
+		{types.KVChecksumLuhn, "4111111111111112", false},
+		{types.KVChecksumLuhn, "411111111111111a", false},
+		{types.KVChecksumLuhn, "0", false},
+		{types.KVChecksumCNID, "11010519491231002X", true},
+		{types.KVChecksumCNID, "11010519491231002x", true},
+		{types.KVChecksumCNID, "110105194912310021", false},
+		{types.KVChecksumCNID, "1101051949123100", false},
+		{types.KVChecksumDNI, "12345678Z", true},
+		{types.KVChecksumDNI, "12345678-z", true},
+		{types.KVChecksumDNI, "12345678A", false},
+		{types.KVChecksumDNI, "X1234567L", true},
+		{types.KVChecksumDNI, "Y-1234567-X", true},
+		{types.KVChecksumDNI, "Y-1234567-R", false},
+		{types.KVChecksumDNI, "1X234567L", false},
+		{types.KVChecksumCF, "RSSMRA85T10A562S", true},
+		{types.KVChecksumCF, "rssmra85t10a562s", true},
+		{types.KVChecksumCF, "RSSMRA85T10A56NH", true},
+		{types.KVChecksumCF, "RSSMRA85T10A562T", false},
+		{types.KVChecksumCF, "ABCDEF12G34H567I", false},
+	}
+	for _, tt := range tests {
+		if got := validChecksum(tt.checksum, tt.val); got != tt.want {
+			t.Errorf("validChecksum(%q, %q) = %v, want %v", tt.checksum, tt.val, got, tt.want)
+		}
+	}
+	if _, err := CompileKVProcesser(types.KVRules{DetectRules: []types.KVDetectConfig{{ValChecksum: "crc"}}}); err == nil {
+		t.Errorf("CompileKVProcesser() unknown checksum error = nil")
+	}
+}
+
+func TestKVProcesser_Detect_BigNumbers(t *testing.T) {
+	m := NewKVProcesser(types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{KeyEqs: []string{"password"}},
+			{KeyEqs: []string{"account"}, ValTypes: []types.KVValType{types.KVValTypeInteger}},
+			{KeyEqs: []string{"amount"}, ValRange: "[0, 100]"},
+		},
+	})
+	input := `{"password":1e999999,"account":1e999999,"amount":1e-999999,"list":[` + strings.Repeat("1e999999,", 200) + `1]}`
+	got, err := m.Detect([]byte(input))
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	// numbers out of bounds are not numbers of constraints
+	if len(got) != 1 || got[0].Key != "password" {
+		t.Errorf("Detect() = %+v, want password only", got)
+	}
+}
@@ -0,0 +1,220 @@
+package processer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/senayuki/mosaic/types"
+	"github.com/valyala/fastjson"
+)
+
+// max depth of nested payloads, e.g. JSON in base64 in JSON string
+const maxDecodeDepth = 4
+
+// payload decoded from string value
+type embedded struct {
+	root   *fastjson.Value
+	encode func(root *fastjson.Value) string // encode payload back to the original encoding
+}
+
+// decode string to payload, returns false if string is not encoded in this way
+type decoder func(s string) (*embedded, bool)
+
+var decoders = map[types.KVDecoder]decoder{
+	types.KVDecoderJSON:   decodeJSON,
+	types.KVDecoderBase64: decodeBase64,
+	types.KVDecoderForm:   decodeForm,
+	types.KVDecoderJWT:    decodeJWT,
+}
+
+func compileDecoders(names []types.KVDecoder) ([]types.KVDecoder, error) {
+	for _, name := range names {
+		if _, ok := decoders[name]; !ok {
+			return nil, fmt.Errorf("unknown decoder %q", name)
+		}
+	}
+	return names, nil
+}
+
+// try decoders in order, returns the first decoded payload
+func (m KVProcesser) decodeEmbedded(valJSONPath types.JSONPath, s string) (types.KVDecoder, *embedded) {
+	if len(m.decoders) == 0 {
+		return "", nil
+	}
+	depth := 0
+	for _, elem := range valJSONPath.Elements() {
+		if _, ok := elem.(types.JSONPathDecode); ok {
+			depth++
+		}
+	}
+	if depth >= maxDecodeDepth {
+		return "", nil
+	}
+	for _, name := range m.decoders {
+		if payload, ok := decoders[name](s); ok {
+			return name, payload
+		}
+	}
+	return "", nil
+}
+
+// JSON object or array only, scalar in string is not treated as JSON
+func parseStructured(data []byte) (*fastjson.Value, bool) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || (data[0] != '{' && data[0] != '[') {
+		return nil, false
+	}
+	root, err := fastjson.ParseBytes(data)
+	if err != nil {
+		return nil, false
+	}
+	return root, true
+}
+
+func decodeJSON(s string) (*embedded, bool) {
+	root, ok := parseStructured([]byte(s))
+	if !ok {
+		return nil, false
+	}
+	return &embedded{
+		root: root,
+		encode: func(root *fastjson.Value) string {
+			return string(root.MarshalTo(nil))
+		},
+	}, true
+}
+
+// base64 variant decided by alphabet & padding
+func base64Encoding(s string) *base64.Encoding {
+	url := strings.ContainsAny(s, "-_")
+	padded := strings.HasSuffix(s, "=")
+	switch {
+	case url && padded:
+		return base64.URLEncoding
+	case url:
+		return base64.RawURLEncoding
+	case padded:
+		return base64.StdEncoding
+	default:
+		return base64.RawStdEncoding
+	}
+}
+
+func decodeBase64(s string) (*embedded, bool) {
+	// shortest base64 of JSON object or array like {"a":1}
+	if len(s) < 8 {
+		return nil, false
+	}
+	enc := base64Encoding(s)
+	data, err := enc.DecodeString(s)
+	if err != nil {
+		return nil, false
+	}
+	root, ok := parseStructured(data)
+	if !ok {
+		return nil, false
+	}
+	return &embedded{
+		root: root,
+		encode: func(root *fastjson.Value) string {
+			return enc.EncodeToString(root.MarshalTo(nil))
+		},
+	}, true
+}
+
+// keys in original order, repeated keys are decoded as array
+func decodeForm(s string) (*embedded, bool) {
+	if !strings.Contains(s, "=") || strings.ContainsAny(s, " \t\r\n{}[]\"") {
+		return nil, false
+	}
+	var arena fastjson.Arena
+	root := arena.NewObject()
+	for _, part := range strings.Split(s, "&") {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok || k == "" {
+			return nil, false
+		}
+		key, err := url.QueryUnescape(k)
+		if err != nil {
+			return nil, false
+		}
+		val, err := url.QueryUnescape(v)
+		if err != nil {
+			return nil, false
+		}
+		exist := root.Get(key)
+		switch {
+		case exist == nil:
+			root.Set(key, arena.NewString(val))
+		case exist.Type() == fastjson.TypeArray:
+			exist.SetArrayItem(len(exist.GetArray()), arena.NewString(val))
+		default:
+			arr := arena.NewArray()
+			arr.SetArrayItem(0, exist)
+			arr.SetArrayItem(1, arena.NewString(val))
+			root.Set(key, arr)
+		}
+	}
+	return &embedded{root: root, encode: encodeForm}, true
+}
+
+func encodeForm(root *fastjson.Value) string {
+	var buf strings.Builder
+	write := func(key string, v *fastjson.Value) {
+		if buf.Len() > 0 {
+			buf.WriteByte('&')
+		}
+		buf.WriteString(url.QueryEscape(key))
+		buf.WriteByte('=')
+		switch v.Type() {
+		case fastjson.TypeString:
+			buf.WriteString(url.QueryEscape(string(v.GetStringBytes())))
+		case fastjson.TypeNull:
+		default:
+			buf.WriteString(url.QueryEscape(string(v.MarshalTo(nil))))
+		}
+	}
+	root.GetObject().Visit(func(key []byte, v *fastjson.Value) {
+		if v.Type() == fastjson.TypeArray {
+			for _, item := range v.GetArray() {
+				write(string(key), item)
+			}
+			return
+		}
+		write(string(key), v)
+	})
+	return buf.String()
+}
+
+// claims of JWT, header must be an object with "alg"
+func decodeJWT(s string) (*embedded, bool) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+	header, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[0], "="))
+	if err != nil {
+		return nil, false
+	}
+	headerVal, err := fastjson.ParseBytes(header)
+	if err != nil || headerVal.Type() != fastjson.TypeObject || !headerVal.Exists("alg") {
+		return nil, false
+	}
+	claims, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, false
+	}
+	root, ok := parseStructured(claims)
+	if !ok {
+		return nil, false
+	}
+	return &embedded{
+		root: root,
+		encode: func(root *fastjson.Value) string {
+			return parts[0] + "." + base64.RawURLEncoding.EncodeToString(root.MarshalTo(nil)) + "." + parts[2]
+		},
+	}, true
+}
@@ -0,0 +1,164 @@
+package processer
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+func TestKVProcesser_MaskEmbedded(t *testing.T) {
+	b64 := base64.StdEncoding.EncodeToString
+	b64url := base64.RawURLEncoding.EncodeToString
+	jwtHeader := b64url([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	decoders := []types.KVDecoder{
+		types.KVDecoderJWT,
+		types.KVDecoderJSON,
+		types.KVDecoderBase64,
+		types.KVDecoderForm,
+	}
+	passwordRule := types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{
+				KeyEqs: []string{"password"},
+			},
+		},
+		Decoders: decoders,
+	}
+	tests := []struct {
+		name       string
+		rule       types.KVRules
+		input      map[string]interface{}
+		wantOutput map[string]interface{}
+		wantPaths  []string
+	}{
+		{
+			name: "JSON in string",
+			rule: passwordRule,
+			input: map[string]interface{}{
+				"payload": `{"user":"alice","password":"abc"}`,
+			},
+			wantOutput: map[string]interface{}{
+				"payload": `{"user":"alice","password":"***"}`,
+			},
+			wantPaths: []string{`["payload",{"decode":"json"},"password"]`},
+		},
+		{
+			name: "base64 JSON",
+			rule: passwordRule,
+			input: map[string]interface{}{
+				"payload": b64([]byte(`{"password":"abc"}`)),
+			},
+			wantOutput: map[string]interface{}{
+				"payload": b64([]byte(`{"password":"***"}`)),
+			},
+			wantPaths: []string{`["payload",{"decode":"base64"},"password"]`},
+		},
+		{
+			name: "form in JSON in string",
+			rule: passwordRule,
+			input: map[string]interface{}{
+				"payload": `["user=alice&password=abc&password=d%26f"]`,
+			},
+			wantOutput: map[string]interface{}{
+				"payload": `["user=alice&password=%2A%2A%2A&password=%2A%2A%2A"]`,
+			},
+			wantPaths: []string{
+				`["payload",{"decode":"json"},0,{"decode":"form"},"password",0]`,
+				`["payload",{"decode":"json"},0,{"decode":"form"},"password",1]`,
+			},
+		},
+		{
+			name: "JWT claims",
+			rule: passwordRule,
+			input: map[string]interface{}{
+				"token": jwtHeader + "." + b64url([]byte(`{"sub":"1","password":"abc"}`)) + ".sig",
+			},
+			wantOutput: map[string]interface{}{
+				"token": jwtHeader + "." + b64url([]byte(`{"sub":"1","password":"***"}`)) + ".sig",
+			},
+			wantPaths: []string{`["token",{"decode":"jwt"},"password"]`},
+		},
+		{
+			name: "masked ancestor wins",
+			rule: types.KVRules{
+				DetectRules: []types.KVDetectConfig{
+					{
+						KeyEqs: []string{"password", "token"},
+					},
+				},
+				Decoders: decoders,
+			},
+			input: map[string]interface{}{
+				"token": `{"password":"abc"}`,
+			},
+			wantOutput: map[string]interface{}{
+				"token": `******************`,
+			},
+			wantPaths: []string{`["token"]`, `["token",{"decode":"json"},"password"]`},
+		},
+		{
+			name: "decoders disabled",
+			rule: types.KVRules{
+				DetectRules: passwordRule.DetectRules,
+			},
+			input: map[string]interface{}{
+				"payload": `{"password":"abc"}`,
+			},
+			wantOutput: map[string]interface{}{
+				"payload": `{"password":"abc"}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := json.Marshal(tt.input)
+			if err != nil {
+				t.Fatalf("json.Marshal() input error = %v", err)
+			}
+			wantOutput, err := json.Marshal(tt.wantOutput)
+			if err != nil {
+				t.Fatalf("json.Marshal() wantOutput error = %v", err)
+			}
+			m := NewKVProcesser(tt.rule)
+			gotOutput, gotPairs, err := m.Mask(context.Background(), input)
+			if err != nil {
+				t.Fatalf("Mask() error = %v", err)
+			}
+			// reorder keys of output to compare with wantOutput
+			var got interface{}
+			if err := json.Unmarshal(gotOutput, &got); err != nil {
+				t.Fatalf("json.Unmarshal() output error = %v", err)
+			}
+			if gotBytes, _ := json.Marshal(got); string(gotBytes) != string(wantOutput) {
+				t.Errorf("Mask() output = %v, want %v", string(gotOutput), string(wantOutput))
+			}
+			if len(gotPairs) != len(tt.wantPaths) {
+				t.Fatalf("Mask() got %d pairs, want %d", len(gotPairs), len(tt.wantPaths))
+			}
+			for idx, pair := range gotPairs {
+				path, _ := json.Marshal(pair.ValJSONPath)
+				if string(path) != tt.wantPaths[idx] {
+					t.Errorf("Mask() pair %d path = %v, want %v", idx, string(path), tt.wantPaths[idx])
+				}
+			}
+		})
+	}
+}
+
+func TestJSONPath_UnmarshalJSON(t *testing.T) {
+	path := types.NewJSONPath().Append("token", types.JSONPathDecode(types.KVDecoderJWT), "emails", 0)
+	data, err := json.Marshal(path)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var got types.JSONPath
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got.String() != "token-><jwt>->emails->0" {
+		t.Errorf("JSONPath.String() = %v, want %v", got.String(), "token-><jwt>->emails->0")
+	}
+}
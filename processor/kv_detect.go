@@ -1,53 +1,109 @@
 package processer
 
 import (
+	"encoding/json"
 	"regexp"
 	"strings"
 
+	"github.com/senayuki/mosaic/pkg/dict"
 	"github.com/senayuki/mosaic/types"
 	"github.com/valyala/fastjson"
 )
 
 type detectExp struct {
-	KeyRegex []*regexp.Regexp
-	ValRegex []*regexp.Regexp
+	KeyNorm     keyNorm
+	KeyEqs      []string // normalized KeyEqs
+	KeyContains []string // normalized KeyContains
+	KeyRegex    []*regexp.Regexp
+	ValRegex    []*regexp.Regexp
+	Expr        exprNode
+	ValRange    *numRange
+	ValDict     *dict.Dictionary
 }
 
-// input JSON bytes
+// pair matched by config
+type detected struct {
+	pair      types.KVPair
+	configIdx int
+}
+
+// input JSON bytes, pairs are located in input by KVPair.Pos
 func (m KVProcesser) Detect(input []byte) ([]types.KVPair, error) {
 	val, err := fastjson.ParseBytes(input)
 	if err != nil {
 		return nil, err
 	}
+	found := m.detect(val)
+	locate(input, found)
+	matched := make([]types.KVPair, 0, len(found))
+	for _, d := range found {
+		matched = append(matched, d.pair)
+	}
+	return matched, nil
+}
+
+func (m KVProcesser) detect(val *fastjson.Value) []detected {
 	// extract all k-v pair (include k-v pair in fields
 	var elements []types.KVPair
 	elements = m.visit(types.NewJSONPath(), "", val, nil, elements)
-	matched := make([]types.KVPair, 0, len(elements))
+	matched := make([]detected, 0, len(elements))
 	for _, v := range elements {
 		valString := v.GetValString()
-		for configIdx, config := range m.detectConfig {
-			if v.KVFieldRel != config.KVFieldOpt {
-				continue
-			}
-			if m.matchKV(configIdx, v, valString) {
-				matched = append(matched, v)
+		keys := keyNormCache{key: v.Key}
+		for configIdx := range m.detectConfig {
+			if m.matchPair(configIdx, v, valString, &keys) {
+				v.DetectRule = configIdx
+				v.Rule = m.ruleMeta(configIdx)
+				matched = append(matched, detected{pair: v, configIdx: configIdx})
 			}
 		}
 	}
-	return matched, nil
+	return matched
+}
+
+// whether pair is matched by config
+func (m KVProcesser) matchPair(configIdx int, v types.KVPair, valString string, keys *keyNormCache) bool {
+	config := m.detectConfig[configIdx]
+	if v.ObjectKey && !config.MatchObjectKey {
+		return false
+	}
+	if !matchKVField(config, v.KVFieldRel) {
+		return false
+	}
+	if !m.matchConstraint(configIdx, v, valString) {
+		return false
+	}
+	// match with normalized key, but return the original pair
+	normalized := v
+	normalized.Key = keys.get(m.detectExp[configIdx].KeyNorm)
+	return m.matchKV(configIdx, normalized, valString)
+}
+
+// whether pair extracted (or not) by k-v fields applies to the config
+func matchKVField(config types.KVDetectConfig, rel *types.KVField) bool {
+	if config.KVFieldOpt == nil {
+		return rel == nil
+	}
+	if rel == nil {
+		return config.KVFieldPlain
+	}
+	return config.KVFieldOpt.Has(*rel)
 }
 
 func (m KVProcesser) matchKV(configIdx int, pair types.KVPair, valString string) bool {
+	if expr := m.detectExp[configIdx].Expr; expr != nil {
+		return expr.eval(&pair, valString)
+	}
 	keyEqMatch := false
 	keyContainsMatch := false
 	keyRegMatch := false
-	for _, keyKeyword := range m.detectConfig[configIdx].KeyEqs {
+	for _, keyKeyword := range m.detectExp[configIdx].KeyEqs {
 		if strings.EqualFold(keyKeyword, pair.Key) {
 			keyEqMatch = true
 			break
 		}
 	}
-	for _, keyContains := range m.detectConfig[configIdx].KeyContains {
+	for _, keyContains := range m.detectExp[configIdx].KeyContains {
 		if strings.Contains(pair.Key, keyContains) {
 			keyContainsMatch = true
 			break
@@ -63,6 +119,7 @@ func (m KVProcesser) matchKV(configIdx int, pair types.KVPair, valString string)
 	valEqMatch := false
 	valContainsMatch := false
 	valRegMatch := false
+	valDictMatch := false
 	for _, valKeyword := range m.detectConfig[configIdx].ValEqs {
 		if strings.EqualFold(valKeyword, valString) {
 			valEqMatch = true
@@ -81,13 +138,16 @@ func (m KVProcesser) matchKV(configIdx int, pair types.KVPair, valString string)
 			break
 		}
 	}
+	if d := m.detectExp[configIdx].ValDict; d != nil {
+		valDictMatch = d.Match(valString)
+	}
 	switch m.detectConfig[configIdx].MatchMode {
 	case types.KVMatchDefault, types.KVMatchOr:
 		return (keyEqMatch || keyContainsMatch || keyRegMatch) ||
-			(valEqMatch || valContainsMatch || valRegMatch)
+			(valEqMatch || valContainsMatch || valRegMatch || valDictMatch)
 	case types.KVMatchAnd:
 		return (keyEqMatch || keyContainsMatch || keyRegMatch) &&
-			(valEqMatch || valContainsMatch || valRegMatch)
+			(valEqMatch || valContainsMatch || valRegMatch || valDictMatch)
 	default:
 		return false
 	}
@@ -97,26 +157,42 @@ func (m KVProcesser) matchKV(configIdx int, pair types.KVPair, valString string)
 func (m KVProcesser) visit(valJSONPath types.JSONPath, key string, val *fastjson.Value, kvFieldRel *types.KVField, elements []types.KVPair) []types.KVPair {
 	switch val.Type() {
 	case fastjson.TypeObject:
-		keyFieldProbable := map[string]struct{}{}
+		if kvFieldRel != nil {
+			// value of k-v fields is an object, all members belong to the real key
+			val.GetObject().Visit(func(k []byte, v *fastjson.Value) {
+				elements = m.visit(valJSONPath.Append(string(k)), key, v, kvFieldRel, elements)
+			})
+			break
+		}
+		var keyFieldProbable []string
 		field := map[string]*fastjson.Value{}
-		val.GetObject().Visit(func(key []byte, v *fastjson.Value) {
-			keyStr := string(key)
+		val.GetObject().Visit(func(k []byte, v *fastjson.Value) {
+			keyStr := string(k)
 			if _, ok := m.detectKVField[keyStr]; ok {
-				keyFieldProbable[keyStr] = struct{}{}
+				keyFieldProbable = append(keyFieldProbable, keyStr)
 			}
 			field[keyStr] = v
+			if m.matchObjectKey {
+				// object key as value, key of the object as key
+				elements = append(elements, types.KVPair{
+					Key:         key,
+					ValJSONPath: valJSONPath.Append(keyStr),
+					Val:         keyStr,
+					ValMasked:   keyStr,
+					ObjectKey:   true,
+				})
+			}
 			elements = m.visit(valJSONPath.Append(keyStr), keyStr, v, nil, elements)
 		})
 		// add k-v fields relations
-		for keyField, _ := range keyFieldProbable {
+		for _, keyField := range keyFieldProbable {
 			// key must be string
 			if keyVal, ok := field[keyField]; ok && keyVal.Type() == fastjson.TypeString {
 				// value of keyField is the real key
 				key := string(keyVal.GetStringBytes())
-				for valField, kvFieldRel := range m.detectKVField[keyField] {
-					// value is impossible to be object
-					if val, ok := field[valField]; ok && val.Type() != fastjson.TypeObject {
-						elements = m.visit(valJSONPath.Append(valField), key, val, kvFieldRel, elements)
+				for _, kvFieldRel := range m.detectKVField[keyField] {
+					if val, ok := field[kvFieldRel.Val]; ok {
+						elements = m.visit(valJSONPath.Append(kvFieldRel.Val), key, val, kvFieldRel, elements)
 					}
 				}
 			}
@@ -127,19 +203,26 @@ func (m KVProcesser) visit(valJSONPath types.JSONPath, key string, val *fastjson
 			elements = m.visit(valJSONPath.Append(idx), key, item, kvFieldRel, elements)
 		}
 	case fastjson.TypeString:
+		str := string(val.GetStringBytes())
 		elements = append(elements, types.KVPair{
 			Key:         key,
 			ValJSONPath: valJSONPath,
-			Val:         string(val.GetStringBytes()),
-			ValMasked:   string(val.GetStringBytes()),
+			Val:         str,
+			ValMasked:   str,
 			KVFieldRel:  kvFieldRel,
 		})
+		// rules are applied inside embedded payload as well
+		if name, payload := m.decodeEmbedded(valJSONPath, str); payload != nil {
+			elements = m.visit(valJSONPath.Append(types.JSONPathDecode(name)), key, payload.root, kvFieldRel, elements)
+		}
 	case fastjson.TypeNumber:
+		// keep raw text of number, avoid losing precision of float & big number
+		num := json.Number(val.MarshalTo(nil))
 		elements = append(elements, types.KVPair{
 			Key:         key,
 			ValJSONPath: valJSONPath,
-			Val:         val.GetInt64(),
-			ValMasked:   val.GetInt64(),
+			Val:         num,
+			ValMasked:   num,
 			KVFieldRel:  kvFieldRel,
 		})
 	case fastjson.TypeNull:
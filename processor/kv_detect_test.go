@@ -102,6 +102,7 @@ func TestKVProcesser_Detect(t *testing.T) {
 					ValMasked:   true,
 					ValJSONPath: types.NewJSONPath().Append("isMember"),
 					KVFieldRel:  nil,
+					DetectRule:  1,
 				},
 				{
 					Key:         "mobile1234",
@@ -109,6 +110,7 @@ func TestKVProcesser_Detect(t *testing.T) {
 					ValJSONPath: types.NewJSONPath().Append("mobile1234"),
 					ValMasked:   "12344321",
 					KVFieldRel:  nil,
+					DetectRule:  3,
 				},
 				{
 					Key:         "phonenumber",
@@ -123,6 +125,7 @@ func TestKVProcesser_Detect(t *testing.T) {
 					ValMasked:   nil,
 					ValJSONPath: types.NewJSONPath().Append("status"),
 					KVFieldRel:  nil,
+					DetectRule:  2,
 				},
 			},
 			wantErr: false,
@@ -229,6 +232,307 @@ func TestKVProcesser_Detect(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "match header-style array & multiple value fields",
+			args: args{
+				rule: types.KVRules{
+					DetectRules: []types.KVDetectConfig{
+						{
+							KeyEqs: []string{
+								"authorization",
+							},
+							KVFieldOpt: &types.KVField{
+								Key:  "name",
+								Val:  "value",
+								Vals: []string{"values"},
+							},
+						},
+					},
+				},
+				input: map[string]interface{}{
+					"headers": []interface{}{
+						map[string]interface{}{
+							"name":  "Authorization",
+							"value": "Bearer token",
+						},
+						map[string]interface{}{
+							"name":   "Authorization",
+							"values": []string{"Basic dXNlcg=="},
+						},
+						map[string]interface{}{
+							"name":  "Accept",
+							"value": "*/*",
+						},
+					},
+				},
+			},
+			wantDetect: []types.KVPair{
+				{
+					Key:         "Authorization",
+					Val:         "Bearer token",
+					ValJSONPath: types.NewJSONPath().Append("headers", 0, "value"),
+					ValMasked:   "Bearer token",
+					KVFieldRel: &types.KVField{
+						Key: "name",
+						Val: "value",
+					},
+				},
+				{
+					Key:         "Authorization",
+					Val:         "Basic dXNlcg==",
+					ValJSONPath: types.NewJSONPath().Append("headers", 1, "values", 0),
+					ValMasked:   "Basic dXNlcg==",
+					KVFieldRel: &types.KVField{
+						Key: "name",
+						Val: "values",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "match object value of kv fields & plain pairs",
+			args: args{
+				rule: types.KVRules{
+					DetectRules: []types.KVDetectConfig{
+						{
+							KeyEqs: []string{
+								"token",
+							},
+							KVFieldOpt: &types.KVField{
+								Key: "name",
+								Val: "content",
+							},
+							KVFieldPlain: true,
+						},
+						{
+							ValEqs: []string{
+								"secret",
+							},
+							MatchMode: types.KVMatchAnd,
+							KeyEqs: []string{
+								"token",
+							},
+							KVFieldOpt: &types.KVField{
+								Key: "name",
+								Val: "content",
+							},
+						},
+					},
+				},
+				input: map[string]interface{}{
+					"cred": map[string]interface{}{
+						"name": "token",
+						"content": map[string]interface{}{
+							"raw": "secret",
+						},
+					},
+					"token": "plain",
+				},
+			},
+			wantDetect: []types.KVPair{
+				{
+					Key:         "token",
+					Val:         "secret",
+					ValJSONPath: types.NewJSONPath().Append("cred", "content", "raw"),
+					ValMasked:   "secret",
+					KVFieldRel: &types.KVField{
+						Key: "name",
+						Val: "content",
+					},
+				},
+				{
+					Key:         "token",
+					Val:         "secret",
+					ValJSONPath: types.NewJSONPath().Append("cred", "content", "raw"),
+					ValMasked:   "secret",
+					KVFieldRel: &types.KVField{
+						Key: "name",
+						Val: "content",
+					},
+					DetectRule: 1,
+				},
+				{
+					Key:         "token",
+					Val:         "plain",
+					ValJSONPath: types.NewJSONPath().Append("token"),
+					ValMasked:   "plain",
+					KVFieldRel:  nil,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "match boolean expression",
+			args: args{
+				rule: types.KVRules{
+					DetectRules: []types.KVDetectConfig{
+						{
+							Expr: `(keyContains("card") AND valRegex(card16)) OR keyEq("pan")`,
+						},
+						{
+							Expr: `keyContains("id") && !keyEq("request_id")`,
+						},
+					},
+					Patterns: map[string]string{
+						"card16": `^[0-9]{16}$`,
+					},
+				},
+				input: map[string]interface{}{
+					"cardNo":     "4111111111111111",
+					"cardName":   "Alice",
+					"PAN":        "5500000000000004",
+					"request_id": "r-1",
+					"user_id":    "u-1",
+				},
+			},
+			wantDetect: []types.KVPair{
+				{
+					Key:         "PAN",
+					Val:         "5500000000000004",
+					ValJSONPath: types.NewJSONPath().Append("PAN"),
+					ValMasked:   "5500000000000004",
+					KVFieldRel:  nil,
+				},
+				{
+					Key:         "cardNo",
+					Val:         "4111111111111111",
+					ValJSONPath: types.NewJSONPath().Append("cardNo"),
+					ValMasked:   "4111111111111111",
+					KVFieldRel:  nil,
+				},
+				{
+					Key:         "user_id",
+					Val:         "u-1",
+					ValJSONPath: types.NewJSONPath().Append("user_id"),
+					ValMasked:   "u-1",
+					KVFieldRel:  nil,
+					DetectRule:  1,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "match value type, length & range constraints",
+			args: args{
+				rule: types.KVRules{
+					DetectRules: []types.KVDetectConfig{
+						{
+							KeyContains: []string{"phone"},
+							ValTypes:    []types.KVValType{types.KVValTypeString},
+							ValMinLen:   11,
+							ValMaxLen:   11,
+						},
+						{
+							KeyContains: []string{"account"},
+							ValTypes:    []types.KVValType{types.KVValTypeInteger},
+							ValRange:    "[1e10, 1e11)",
+						},
+						{
+							ValEqs:   []string{"null", "true"},
+							ValTypes: []types.KVValType{types.KVValTypeString},
+						},
+					},
+				},
+				input: map[string]interface{}{
+					"phone":      "13800138000",
+					"phoneShort": "1380013",
+					"phoneNum":   13800138000,
+					"account":    12345678901,
+					"accountMax": 100000000000,
+					"accountStr": "12345678901",
+					"isNull":     nil,
+					"isTrue":     true,
+					"nullStr":    "null",
+				},
+			},
+			wantDetect: []types.KVPair{
+				{
+					Key:         "account",
+					Val:         12345678901,
+					ValJSONPath: types.NewJSONPath().Append("account"),
+					ValMasked:   12345678901,
+					KVFieldRel:  nil,
+					DetectRule:  1,
+				},
+				{
+					Key:         "nullStr",
+					Val:         "null",
+					ValJSONPath: types.NewJSONPath().Append("nullStr"),
+					ValMasked:   "null",
+					KVFieldRel:  nil,
+					DetectRule:  2,
+				},
+				{
+					Key:         "phone",
+					Val:         "13800138000",
+					ValJSONPath: types.NewJSONPath().Append("phone"),
+					ValMasked:   "13800138000",
+					KVFieldRel:  nil,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "match normalized keys",
+			args: args{
+				rule: types.KVRules{
+					DetectRules: []types.KVDetectConfig{
+						{
+							KeyEqs: []string{"phoneNumber"},
+						},
+						{
+							KeyContains:  []string{"Secret"},
+							KeyNormalize: []types.KVKeyNormalize{types.KVKeyNormalizeLower},
+						},
+					},
+					KeyNormalize: []types.KVKeyNormalize{
+						types.KVKeyNormalizeNFKC,
+						types.KVKeyNormalizeWords,
+						types.KVKeyNormalizeLower,
+					},
+				},
+				input: map[string]interface{}{
+					"PHONE-NUMBER": "1",
+					"phone_number": "2",
+					"phonenumber":  "3",
+					"ｐｈｏｎｅＮｕｍｂｅｒ":  "4",
+					"clientSECRET": "5",
+				},
+			},
+			wantDetect: []types.KVPair{
+				{
+					Key:         "PHONE-NUMBER",
+					Val:         "1",
+					ValJSONPath: types.NewJSONPath().Append("PHONE-NUMBER"),
+					ValMasked:   "1",
+					KVFieldRel:  nil,
+				},
+				{
+					Key:         "clientSECRET",
+					Val:         "5",
+					ValJSONPath: types.NewJSONPath().Append("clientSECRET"),
+					ValMasked:   "5",
+					KVFieldRel:  nil,
+					DetectRule:  1,
+				},
+				{
+					Key:         "phone_number",
+					Val:         "2",
+					ValJSONPath: types.NewJSONPath().Append("phone_number"),
+					ValMasked:   "2",
+					KVFieldRel:  nil,
+				},
+				{
+					Key:         "ｐｈｏｎｅＮｕｍｂｅｒ",
+					Val:         "4",
+					ValJSONPath: types.NewJSONPath().Append("ｐｈｏｎｅＮｕｍｂｅｒ"),
+					ValMasked:   "4",
+					KVFieldRel:  nil,
+				},
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -248,6 +552,10 @@ func TestKVProcesser_Detect(t *testing.T) {
 				t.Errorf("Detect() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			// positions are tested by TestKVProcesser_DetectPos
+			for idx := range got {
+				got[idx].Pos = nil
+			}
 			gotPairBytes, err := json.Marshal(got)
 			if err != nil {
 				t.Errorf("json.Marshal() gotPairBytes error = %v", err)
@@ -0,0 +1,50 @@
+package processer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/senayuki/mosaic/pkg/dict"
+	"github.com/senayuki/mosaic/types"
+)
+
+// dictionaries by name, given ones are used as is, others of configs are opened by file path
+func compileDictionaries(configs map[string]types.KVDictionary, given map[string]*dict.Dictionary) (map[string]*dict.Dictionary, error) {
+	dicts := make(map[string]*dict.Dictionary, len(configs)+len(given))
+	for name, d := range given {
+		dicts[name] = d
+	}
+	for name, config := range configs {
+		if _, ok := dicts[name]; ok {
+			continue
+		}
+		if config.File == "" {
+			return nil, fmt.Errorf("%q: File is required", name)
+		}
+		d, err := dict.OpenFile(config)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", name, err)
+		}
+		dicts[name] = d
+	}
+	return dicts, nil
+}
+
+/*
+read dictionaries from their files again, processers sharing dictionaries see new words as well
+dictionaries failed to read keep their words, the first error is returned after all are tried
+*/
+func (m KVProcesser) ReloadDictionaries() error {
+	names := make([]string, 0, len(m.dictionaries))
+	for name := range m.dictionaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var first error
+	for _, name := range names {
+		if err := m.dictionaries[name].Reload(); err != nil && first == nil {
+			first = fmt.Errorf("dictionary %q: %w", name, err)
+		}
+	}
+	return first
+}
@@ -0,0 +1,74 @@
+package processer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/senayuki/mosaic/pkg/dict"
+	"github.com/senayuki/mosaic/types"
+)
+
+func TestKVProcesser_ValDictionary(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "codenames.txt")
+	if err := os.WriteFile(file, []byte("falcon\nheron\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	employees, err := dict.New([]string{"Alice Zhang", "Bob Li"}, types.KVDictionary{CaseFold: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{ValDictionary: "employees", MaskRef: "drop"},
+			{KeyContains: []string{"note"}, ValDictionary: "codenames", MatchMode: types.KVMatchAnd},
+		},
+		MaskRules: []types.KVMaskConfig{{RuleName: "drop", MaskType: types.MaskTypeDrop}},
+		Dictionaries: map[string]types.KVDictionary{
+			"employees": {File: "not-read.txt"},
+			"codenames": {File: file, Match: types.KVDictMatchContains},
+		},
+	}
+	m, err := CompileKVProcesserWith(rules, map[string]*dict.Dictionary{"employees": employees})
+	if err != nil {
+		t.Fatalf("CompileKVProcesserWith() error = %v", err)
+	}
+	input := []byte(`{"owner":"alice zhang","note":"Project falcon kickoff","title":"falcon","reviewer":"Carol"}`)
+	output, _, err := m.Mask(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Mask() error = %v", err)
+	}
+	if want := `{"note":"**********************","title":"falcon","reviewer":"Carol"}`; string(output) != want {
+		t.Errorf("Mask() = %s, want %s", output, want)
+	}
+
+	// reloaded words apply to compiled processer
+	if err := os.WriteFile(file, []byte("eagle\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReloadDictionaries(); err != nil {
+		t.Fatalf("ReloadDictionaries() error = %v", err)
+	}
+	pairs, err := m.Detect([]byte(`{"note":"falcon","notes":"eagle landed"}`))
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(pairs) != 1 || pairs[0].Key != "notes" {
+		t.Errorf("Detect() after reload = %+v", pairs)
+	}
+
+	for name, rules := range map[string]types.KVRules{
+		"unknown dictionary": {DetectRules: []types.KVDetectConfig{{ValDictionary: "employees"}}},
+		"missing file":       {Dictionaries: map[string]types.KVDictionary{"employees": {File: filepath.Join(t.TempDir(), "missing.txt")}}},
+		"unknown match":      {Dictionaries: map[string]types.KVDictionary{"employees": {File: file, Match: "prefix"}}},
+		"with expression": {
+			DetectRules:  []types.KVDetectConfig{{ValDictionary: "employees", Expr: `keyContains("owner")`}},
+			Dictionaries: map[string]types.KVDictionary{"employees": {File: file}},
+		},
+	} {
+		if _, err := CompileKVProcesser(rules); err == nil {
+			t.Errorf("CompileKVProcesser() %s error = nil", name)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package processer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+/*
+boolean expression over criteria, e.g.
+
+	(keyContains("card") AND valRegex(luhn16)) OR keyEq("pan")
+	keyContains("id") AND NOT keyEq("request_id")
+
+grammar:
+
+	expr    = and { ("OR" | "||") and }
+	and     = unary { ("AND" | "&&") unary }
+	unary   = ("NOT" | "!") unary | primary
+	primary = "(" expr ")" | ident "(" [ arg { "," arg } ] ")"
+	arg     = string | ident
+
+keywords are case-insensitive, string is quoted by double quotes or backquotes,
+ident as argument references a named pattern in KVRules.Patterns,
+criterion with several arguments is matched if any of them matched
+*/
+type exprNode interface {
+	eval(pair *types.KVPair, valString string) bool
+}
+
+type (
+	exprOr  []exprNode
+	exprAnd []exprNode
+	exprNot struct {
+		node exprNode
+	}
+	exprCriterion struct {
+		name  string
+		args  []string
+		regex []*regexp.Regexp
+	}
+)
+
+func (e exprOr) eval(pair *types.KVPair, valString string) bool {
+	for _, node := range e {
+		if node.eval(pair, valString) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e exprAnd) eval(pair *types.KVPair, valString string) bool {
+	for _, node := range e {
+		if !node.eval(pair, valString) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e exprNot) eval(pair *types.KVPair, valString string) bool {
+	return !e.node.eval(pair, valString)
+}
+
+func (e exprCriterion) eval(pair *types.KVPair, valString string) bool {
+	switch e.name {
+	case "keyeq":
+		return anyEqualFold(e.args, pair.Key)
+	case "valeq":
+		return anyEqualFold(e.args, valString)
+	case "keycontains":
+		return anyContains(e.args, pair.Key)
+	case "valcontains":
+		return anyContains(e.args, valString)
+	case "keyregex":
+		return anyMatch(e.regex, pair.Key)
+	case "valregex":
+		return anyMatch(e.regex, valString)
+	}
+	return false
+}
+
+func anyEqualFold(keywords []string, s string) bool {
+	for _, keyword := range keywords {
+		if strings.EqualFold(keyword, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyContains(keywords []string, s string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(s, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyMatch(regex []*regexp.Regexp, s string) bool {
+	for _, exp := range regex {
+		if exp.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+type exprTokenKind int
+
+const (
+	exprTokenEOF exprTokenKind = iota
+	exprTokenIdent
+	exprTokenString
+	exprTokenLParen
+	exprTokenRParen
+	exprTokenComma
+	exprTokenAnd
+	exprTokenOr
+	exprTokenNot
+)
+
+type exprToken struct {
+	kind exprTokenKind
+	text string
+	pos  int
+}
+
+func lexExpr(input string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, exprToken{kind: exprTokenLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, exprToken{kind: exprTokenRParen, text: ")", pos: i})
+			i++
+		case c == ',':
+			tokens = append(tokens, exprToken{kind: exprTokenComma, text: ",", pos: i})
+			i++
+		case c == '!':
+			tokens = append(tokens, exprToken{kind: exprTokenNot, text: "!", pos: i})
+			i++
+		case strings.HasPrefix(input[i:], "&&"):
+			tokens = append(tokens, exprToken{kind: exprTokenAnd, text: "&&", pos: i})
+			i += 2
+		case strings.HasPrefix(input[i:], "||"):
+			tokens = append(tokens, exprToken{kind: exprTokenOr, text: "||", pos: i})
+			i += 2
+		case c == '"' || c == '`':
+			quoted, err := strconv.QuotedPrefix(input[i:])
+			if err != nil {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			text, err := strconv.Unquote(quoted)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d: %w", i, err)
+			}
+			tokens = append(tokens, exprToken{kind: exprTokenString, text: text, pos: i})
+			i += len(quoted)
+		case c == '_' || unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(input) && (input[i] == '_' || unicode.IsLetter(rune(input[i])) || unicode.IsDigit(rune(input[i]))) {
+				i++
+			}
+			text := input[start:i]
+			kind := exprTokenIdent
+			switch strings.ToUpper(text) {
+			case "AND":
+				kind = exprTokenAnd
+			case "OR":
+				kind = exprTokenOr
+			case "NOT":
+				kind = exprTokenNot
+			}
+			tokens = append(tokens, exprToken{kind: kind, text: text, pos: start})
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", c, i)
+		}
+	}
+	return append(tokens, exprToken{kind: exprTokenEOF, pos: len(input)}), nil
+}
+
+type exprParser struct {
+	tokens   []exprToken
+	cur      int
+	patterns map[string]string
+	keyNorm  keyNorm
+}
+
+// parse expression, identifiers in arguments are resolved by patterns,
+// arguments of keyEq & keyContains are normalized by keyNorm
+func parseExpr(input string, patterns map[string]string, keyNorm keyNorm) (exprNode, error) {
+	tokens, err := lexExpr(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens, patterns: patterns, keyNorm: keyNorm}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != exprTokenEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.pos)
+	}
+	return node, nil
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.cur]
+}
+
+func (p *exprParser) next() exprToken {
+	tok := p.tokens[p.cur]
+	if tok.kind != exprTokenEOF {
+		p.cur++
+	}
+	return tok
+}
+
+func (p *exprParser) expect(kind exprTokenKind, text string) (exprToken, error) {
+	tok := p.next()
+	if tok.kind != kind {
+		if tok.kind == exprTokenEOF {
+			return tok, fmt.Errorf("expect %s but got end of expression", text)
+		}
+		return tok, fmt.Errorf("expect %s but got %q at %d", text, tok.text, tok.pos)
+	}
+	return tok, nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	node, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	nodes := exprOr{node}
+	for p.peek().kind == exprTokenOr {
+		p.next()
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	node, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	nodes := exprAnd{node}
+	for p.peek().kind == exprTokenAnd {
+		p.next()
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.peek().kind == exprTokenNot {
+		p.next()
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return exprNot{node: node}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	if p.peek().kind == exprTokenLParen {
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(exprTokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+	name, err := p.expect(exprTokenIdent, "criterion")
+	if err != nil {
+		return nil, err
+	}
+	criterion := exprCriterion{name: strings.ToLower(name.text)}
+	switch criterion.name {
+	case "keyeq", "valeq", "keycontains", "valcontains", "keyregex", "valregex":
+	default:
+		return nil, fmt.Errorf("unknown criterion %q at %d", name.text, name.pos)
+	}
+	if _, err := p.expect(exprTokenLParen, "("); err != nil {
+		return nil, err
+	}
+	for p.peek().kind != exprTokenRParen {
+		if len(criterion.args) > 0 {
+			if _, err := p.expect(exprTokenComma, ","); err != nil {
+				return nil, err
+			}
+		}
+		arg := p.next()
+		switch arg.kind {
+		case exprTokenString:
+			criterion.args = append(criterion.args, arg.text)
+		case exprTokenIdent:
+			pattern, ok := p.patterns[arg.text]
+			if !ok {
+				return nil, fmt.Errorf("unknown pattern %q at %d", arg.text, arg.pos)
+			}
+			criterion.args = append(criterion.args, pattern)
+		default:
+			return nil, fmt.Errorf("expect argument but got %q at %d", arg.text, arg.pos)
+		}
+	}
+	p.next()
+	if criterion.name == "keyeq" || criterion.name == "keycontains" {
+		criterion.args = p.keyNorm.applyAll(criterion.args)
+	}
+	if strings.HasSuffix(criterion.name, "regex") {
+		for _, arg := range criterion.args {
+			exp, err := regexp.Compile(arg)
+			if err != nil {
+				return nil, fmt.Errorf("criterion %q at %d: %w", name.text, name.pos, err)
+			}
+			criterion.regex = append(criterion.regex, exp)
+		}
+	}
+	return criterion, nil
+}
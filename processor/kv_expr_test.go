@@ -0,0 +1,123 @@
+package processer
+
+import (
+	"testing"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+func TestParseExpr(t *testing.T) {
+	patterns := map[string]string{
+		"digits": `^[0-9]+$`,
+	}
+	tests := []struct {
+		name    string
+		expr    string
+		pair    types.KVPair
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "or",
+			expr: `keyEq("pan") OR valEq("x")`,
+			pair: types.KVPair{Key: "PAN", Val: "1"},
+			want: true,
+		},
+		{
+			name: "and precedence over or",
+			expr: `keyEq("a") or keyEq("b") and valEq("y")`,
+			pair: types.KVPair{Key: "b", Val: "x"},
+			want: false,
+		},
+		{
+			name: "parentheses",
+			expr: `(keyEq("a") || keyEq("b")) && valRegex(digits)`,
+			pair: types.KVPair{Key: "b", Val: "123"},
+			want: true,
+		},
+		{
+			name: "not",
+			expr: `keyContains("id") AND NOT keyEq("request_id", "trace_id")`,
+			pair: types.KVPair{Key: "trace_id", Val: "1"},
+			want: false,
+		},
+		{
+			name: "double not",
+			expr: `!!valContains("secret")`,
+			pair: types.KVPair{Key: "k", Val: "top secret"},
+			want: true,
+		},
+		{
+			name: "raw string",
+			expr: "keyRegex(`^mobile[0-9]*$`)",
+			pair: types.KVPair{Key: "mobile1", Val: "1"},
+			want: true,
+		},
+		{
+			name:    "unknown criterion",
+			expr:    `keyLike("a")`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown pattern",
+			expr:    `valRegex(luhn16)`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid regex",
+			expr:    `valRegex("(")`,
+			wantErr: true,
+		},
+		{
+			name:    "unbalanced parentheses",
+			expr:    `(keyEq("a") OR keyEq("b")`,
+			wantErr: true,
+		},
+		{
+			name:    "dangling operator",
+			expr:    `keyEq("a") AND`,
+			wantErr: true,
+		},
+		{
+			name:    "unterminated string",
+			expr:    `keyEq("a)`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := parseExpr(tt.expr, patterns, 0)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseExpr() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got := node.eval(&tt.pair, tt.pair.GetValString()); got != tt.want {
+				t.Errorf("eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileKVProcesser(t *testing.T) {
+	_, err := CompileKVProcesser(types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{KeyEqs: []string{"password"}},
+			{Expr: `keyEq("a") AND`},
+		},
+	})
+	if err == nil {
+		t.Errorf("CompileKVProcesser() error = nil, want error")
+	}
+	_, err = CompileKVProcesser(types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{ID: "password", KeyEqs: []string{"password"}},
+			{ID: "password", KeyEqs: []string{"passwd"}},
+		},
+	})
+	if err == nil {
+		t.Errorf("CompileKVProcesser() duplicate ID error = nil, want error")
+	}
+}
@@ -0,0 +1,81 @@
+package processer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/senayuki/mosaic/pkg/str"
+	"github.com/senayuki/mosaic/types"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// set of key normalization, applied in order of nfkc, words, lower
+type keyNorm uint8
+
+const (
+	keyNormNFKC keyNorm = 1 << iota
+	keyNormWords
+	keyNormLower
+
+	keyNormAll = keyNormNFKC | keyNormWords | keyNormLower
+)
+
+func parseKeyNorm(opts []types.KVKeyNormalize) (keyNorm, error) {
+	var n keyNorm
+	for _, opt := range opts {
+		switch opt {
+		case types.KVKeyNormalizeNFKC:
+			n |= keyNormNFKC
+		case types.KVKeyNormalizeWords:
+			n |= keyNormWords
+		case types.KVKeyNormalizeLower:
+			n |= keyNormLower
+		default:
+			return 0, fmt.Errorf("unknown key normalization %q", opt)
+		}
+	}
+	return n, nil
+}
+
+func (n keyNorm) apply(key string) string {
+	if n&keyNormNFKC != 0 {
+		key = width.Fold.String(norm.NFKC.String(key))
+	}
+	if n&keyNormWords != 0 {
+		key = strings.Join(str.SplitWords(key), "_")
+	}
+	if n&keyNormLower != 0 {
+		key = strings.ToLower(key)
+	}
+	return key
+}
+
+func (n keyNorm) applyAll(keys []string) []string {
+	if n == 0 {
+		return keys
+	}
+	normalized := make([]string, 0, len(keys))
+	for _, key := range keys {
+		normalized = append(normalized, n.apply(key))
+	}
+	return normalized
+}
+
+// normalized keys of a pair, cached per normalization
+type keyNormCache struct {
+	key   string
+	done  [keyNormAll + 1]bool
+	cache [keyNormAll + 1]string
+}
+
+func (c *keyNormCache) get(n keyNorm) string {
+	if n == 0 {
+		return c.key
+	}
+	if !c.done[n] {
+		c.cache[n] = n.apply(c.key)
+		c.done[n] = true
+	}
+	return c.cache[n]
+}
@@ -0,0 +1,66 @@
+package processer
+
+import (
+	"testing"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+func TestKeyNorm_apply(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []types.KVKeyNormalize
+		key  string
+		want string
+	}{
+		{
+			name: "none",
+			key:  "phoneNumber",
+			want: "phoneNumber",
+		},
+		{
+			name: "lower",
+			opts: []types.KVKeyNormalize{types.KVKeyNormalizeLower},
+			key:  "PHONE-NUMBER",
+			want: "phone-number",
+		},
+		{
+			name: "words & lower",
+			opts: []types.KVKeyNormalize{types.KVKeyNormalizeLower, types.KVKeyNormalizeWords},
+			key:  "PHONE-NUMBER",
+			want: "phone_number",
+		},
+		{
+			name: "words keeps case",
+			opts: []types.KVKeyNormalize{types.KVKeyNormalizeWords},
+			key:  "phoneNumber",
+			want: "phone_Number",
+		},
+		{
+			name: "nfkc full-width",
+			opts: []types.KVKeyNormalize{types.KVKeyNormalizeNFKC},
+			key:  "ＩＤ＿手机号",
+			want: "ID_手机号",
+		},
+		{
+			name: "nfkc half-width katakana",
+			opts: []types.KVKeyNormalize{types.KVKeyNormalizeNFKC},
+			key:  "ﾃﾞﾝﾜ",
+			want: "デンワ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := parseKeyNorm(tt.opts)
+			if err != nil {
+				t.Fatalf("parseKeyNorm() error = %v", err)
+			}
+			if got := n.apply(tt.key); got != tt.want {
+				t.Errorf("apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if _, err := parseKeyNorm([]types.KVKeyNormalize{"upper"}); err == nil {
+		t.Errorf("parseKeyNorm() error = nil, want error")
+	}
+}
@@ -0,0 +1,418 @@
+package processer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/senayuki/mosaic/mask"
+	"github.com/senayuki/mosaic/types"
+	"github.com/valyala/fastjson"
+)
+
+// default masker of rules without MaskRef, cover whole value
+var defaultMaskRule = types.KVMaskConfig{MaskType: types.MaskTypeCover}
+
+// mask detected values in JSON bytes, returns masked JSON & detected pairs with masked value, located in input
+// value of pair matched by several rules is masked by the first rule,
+// value inside a masked value (e.g. claims of a masked JWT) is not written back
+func (m KVProcesser) Mask(ctx context.Context, input []byte) ([]byte, []types.KVPair, error) {
+	var arena fastjson.Arena
+	root, err := fastjson.ParseBytes(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	found := m.detect(root)
+	locate(input, found)
+	masked := make(map[string]interface{}, len(found))
+	var writes []maskWrite
+	var ops []maskOp
+	renames := map[string]*keyRename{}
+	pairs := make([]types.KVPair, 0, len(found))
+	for _, d := range found {
+		path := d.pair.ValJSONPath.Elements()
+		key := pathKey(path)
+		if d.pair.ObjectKey {
+			key += objectKeySuffix
+		}
+		valMasked, ok := masked[key]
+		if !ok {
+			valMasked, err = m.maskValue(ctx, d)
+			if err != nil {
+				return nil, nil, fmt.Errorf("mask %s: %w", d.pair.ValJSONPath, err)
+			}
+			newVal, err := toJSONValue(&arena, valMasked)
+			if err != nil {
+				return nil, nil, fmt.Errorf("mask %s: %w", d.pair.ValJSONPath, err)
+			}
+			masked[key] = valMasked
+			_, dropped := valMasked.(types.KVDropped)
+			switch {
+			case dropped && len(path) == 0:
+				writes = append(writes, maskWrite{path: path, val: arena.NewNull()})
+			case dropped:
+				ops = append(ops, maskOp{path: path[:len(path)-1], drop: path[len(path)-1]})
+			case d.pair.ObjectKey:
+				// keys of an object are renamed together to resolve collisions
+				parent := pathKey(path[:len(path)-1])
+				rename, ok := renames[parent]
+				if !ok {
+					rename = &keyRename{keys: map[string]string{}}
+					renames[parent] = rename
+					ops = append(ops, maskOp{path: path[:len(path)-1], rename: rename})
+				}
+				rename.keys[d.pair.GetValString()] = fmt.Sprint(valMasked)
+			default:
+				writes = append(writes, maskWrite{path: path, val: newVal})
+			}
+		}
+		d.pair.ValMasked = valMasked
+		pairs = append(pairs, d.pair)
+	}
+	for _, w := range writes {
+		if hasMaskedAncestor(masked, w.path, len(w.path)) {
+			continue
+		}
+		newVal := w.val
+		root, err = m.setByPath(&arena, root, w.path, func(*fastjson.Value) (*fastjson.Value, error) {
+			return newVal, nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	// change structure after values written, deeper containers first to keep paths of parents valid
+	sort.SliceStable(ops, func(i, j int) bool {
+		return ops[i].less(ops[j])
+	})
+	for _, op := range ops {
+		if hasMaskedAncestor(masked, op.path, len(op.path)+1) {
+			continue
+		}
+		op := op
+		root, err = m.setByPath(&arena, root, op.path, func(container *fastjson.Value) (*fastjson.Value, error) {
+			return op.apply(&arena, container)
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	// report keys with collisions resolved
+	for idx := range pairs {
+		if !pairs[idx].ObjectKey {
+			continue
+		}
+		path := pairs[idx].ValJSONPath.Elements()
+		if rename, ok := renames[pathKey(path[:len(path)-1])]; ok && rename.resolved != nil {
+			if resolved, ok := rename.resolved[pairs[idx].GetValString()]; ok {
+				pairs[idx].ValMasked = resolved
+			}
+		}
+	}
+	return root.MarshalTo(nil), pairs, nil
+}
+
+// masked value to be written back
+type maskWrite struct {
+	path []interface{}
+	val  *fastjson.Value
+}
+
+// change of structure in container at path, drop a member/element or rename keys
+type maskOp struct {
+	path   []interface{}
+	drop   interface{} // key or index dropped from container
+	rename *keyRename
+}
+
+// deeper containers first, then drops before renames, then bigger index first
+func (op maskOp) less(other maskOp) bool {
+	if len(op.path) != len(other.path) {
+		return len(op.path) > len(other.path)
+	}
+	if (op.rename == nil) != (other.rename == nil) {
+		return op.rename == nil
+	}
+	idx, ok := op.drop.(int)
+	otherIdx, otherOk := other.drop.(int)
+	return ok && otherOk && idx > otherIdx
+}
+
+func (op maskOp) apply(arena *fastjson.Arena, container *fastjson.Value) (*fastjson.Value, error) {
+	if op.rename != nil {
+		return op.rename.apply(arena, container)
+	}
+	switch e := op.drop.(type) {
+	case string:
+		if container.Type() != fastjson.TypeObject {
+			return nil, fmt.Errorf("drop %q of non-object", e)
+		}
+		container.Del(e)
+	case int:
+		if container.Type() != fastjson.TypeArray || len(container.GetArray()) <= e {
+			return nil, fmt.Errorf("drop index %d out of array", e)
+		}
+		arr := arena.NewArray()
+		for idx, item := range container.GetArray() {
+			if idx != e {
+				arr.SetArrayItem(len(arr.GetArray()), item)
+			}
+		}
+		return arr, nil
+	default:
+		return nil, fmt.Errorf("can not drop %v", e)
+	}
+	return container, nil
+}
+
+// distinguish masked object key from masked value at the same path
+const objectKeySuffix = "\x00key"
+
+// masked keys of an object
+type keyRename struct {
+	keys     map[string]string // original key -> masked key
+	resolved map[string]string // original key -> masked key with collision resolved
+}
+
+// rebuild object in original order with masked keys,
+// masked key collided with former keys is appended "~1", "~2"...
+func (r *keyRename) apply(arena *fastjson.Arena, obj *fastjson.Value) (*fastjson.Value, error) {
+	if obj.Type() != fastjson.TypeObject {
+		return nil, fmt.Errorf("rename keys of non-object")
+	}
+	// keys not masked are kept as is
+	used := map[string]struct{}{}
+	obj.GetObject().Visit(func(k []byte, v *fastjson.Value) {
+		if _, ok := r.keys[string(k)]; !ok {
+			used[string(k)] = struct{}{}
+		}
+	})
+	r.resolved = make(map[string]string, len(r.keys))
+	newObj := arena.NewObject()
+	obj.GetObject().Visit(func(k []byte, v *fastjson.Value) {
+		key := string(k)
+		if maskedKey, ok := r.keys[key]; ok {
+			key = maskedKey
+			for i := 1; ; i++ {
+				if _, ok := used[key]; !ok {
+					break
+				}
+				key = maskedKey + "~" + strconv.Itoa(i)
+			}
+			used[key] = struct{}{}
+			r.resolved[string(k)] = key
+		}
+		newObj.Set(key, v)
+	})
+	return newObj, nil
+}
+
+// unique key of path, keeps types of elements
+func pathKey(path []interface{}) string {
+	var buf strings.Builder
+	for _, elem := range path {
+		switch e := elem.(type) {
+		case string:
+			buf.WriteString("\x00k")
+			buf.WriteString(e)
+		case int:
+			buf.WriteString("\x00i")
+			buf.WriteString(strconv.Itoa(e))
+		case types.JSONPathDecode:
+			buf.WriteString("\x00d")
+			buf.WriteString(string(e))
+		}
+	}
+	return buf.String()
+}
+
+// whether path[:i] (0 < i < end) is masked
+func hasMaskedAncestor(masked map[string]interface{}, path []interface{}, end int) bool {
+	if end > len(path) {
+		end = len(path)
+	}
+	for i := end - 1; i > 0; i-- {
+		if _, ok := masked[pathKey(path[:i])]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mask value by referenced masker, number is kept if masked text is still a number
+func (m KVProcesser) maskValue(ctx context.Context, d detected) (interface{}, error) {
+	return maskPairWith(ctx, m.maskers[m.detectConfig[d.configIdx].MaskRef], d.pair)
+}
+
+// mask value of pair by masker
+func maskPairWith(ctx context.Context, masker mask.Masker, pair types.KVPair) (interface{}, error) {
+	if valueMasker, ok := masker.(mask.ValueMasker); ok {
+		out, err := valueMasker.MaskValue(ctx, &pair)
+		if err != nil {
+			return nil, err
+		}
+		if pair.ObjectKey {
+			// key must be string, dropped key drops the member
+			if _, dropped := out.(types.KVDropped); !dropped {
+				kv := types.KVPair{Val: out}
+				out = kv.GetValString()
+			}
+		}
+		return out, nil
+	}
+	out, err := masker.Mask(ctx, pair.GetValString())
+	if err != nil {
+		return nil, err
+	}
+	return mask.KeepType(&pair, out), nil
+}
+
+// text of masked value, JSON string literal is unquoted, null is "null" and dropped value is empty
+func MaskedText(valMasked interface{}) string {
+	switch v := valMasked.(type) {
+	case types.KVDropped:
+		return ""
+	case nil:
+		return "null"
+	case json.RawMessage:
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			return s
+		}
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// mask a single pair by detect rules, returns false if no rule matched
+// the first matched rule is used, KVFieldOpt & decoders do not apply to a single pair
+func (m KVProcesser) MaskPair(ctx context.Context, pair types.KVPair) (types.KVPair, bool, error) {
+	configIdx, ok := m.firstMatch(pair)
+	if !ok {
+		return pair, false, nil
+	}
+	valMasked, err := m.maskValue(ctx, detected{pair: pair, configIdx: configIdx})
+	if err != nil {
+		return pair, true, err
+	}
+	pair.ValMasked = valMasked
+	pair.DetectRule = configIdx
+	pair.Rule = m.ruleMeta(configIdx)
+	return pair, true, nil
+}
+
+// index of first config matching a single pair
+func (m KVProcesser) firstMatch(pair types.KVPair) (int, bool) {
+	valString := pair.GetValString()
+	keys := keyNormCache{key: pair.Key}
+	for configIdx := range m.detectConfig {
+		if m.matchPair(configIdx, pair, valString, &keys) {
+			return configIdx, true
+		}
+	}
+	return 0, false
+}
+
+// masker of mask rule by RuleName, "" is the default masker
+func (m KVProcesser) Masker(ruleName string) (mask.Masker, bool) {
+	masker, ok := m.maskers[ruleName]
+	return masker, ok
+}
+
+// convert masked value to JSON value
+func toJSONValue(arena *fastjson.Arena, val interface{}) (*fastjson.Value, error) {
+	switch v := val.(type) {
+	case nil, types.KVDropped:
+		return arena.NewNull(), nil
+	case string:
+		return arena.NewString(v), nil
+	case json.Number:
+		return arena.NewNumberString(v.String()), nil
+	case bool:
+		if v {
+			return arena.NewTrue(), nil
+		}
+		return arena.NewFalse(), nil
+	case json.RawMessage:
+		return fastjson.ParseBytes(v)
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return fastjson.ParseBytes(raw)
+	}
+}
+
+// update value in path, returns new value of current node
+// embedded payload in path is decoded, modified and encoded back
+func (m KVProcesser) setByPath(arena *fastjson.Arena, cur *fastjson.Value, path []interface{}, update func(*fastjson.Value) (*fastjson.Value, error)) (*fastjson.Value, error) {
+	if cur == nil {
+		return nil, fmt.Errorf("path not found")
+	}
+	if len(path) == 0 {
+		return update(cur)
+	}
+	switch e := path[0].(type) {
+	case string:
+		if cur.Type() != fastjson.TypeObject {
+			return nil, fmt.Errorf("%q of non-object", e)
+		}
+		child, err := m.setByPath(arena, cur.Get(e), path[1:], update)
+		if err != nil {
+			return nil, err
+		}
+		cur.Set(e, child)
+	case int:
+		if cur.Type() != fastjson.TypeArray || len(cur.GetArray()) <= e {
+			return nil, fmt.Errorf("index %d out of array", e)
+		}
+		child, err := m.setByPath(arena, cur.GetArray()[e], path[1:], update)
+		if err != nil {
+			return nil, err
+		}
+		cur.SetArrayItem(e, child)
+	case types.JSONPathDecode:
+		decode, ok := decoders[types.KVDecoder(e)]
+		if !ok || cur.Type() != fastjson.TypeString {
+			return nil, fmt.Errorf("can not decode %q", e)
+		}
+		payload, ok := decode(string(cur.GetStringBytes()))
+		if !ok {
+			return nil, fmt.Errorf("can not decode %q", e)
+		}
+		root, err := m.setByPath(arena, payload.root, path[1:], update)
+		if err != nil {
+			return nil, err
+		}
+		return arena.NewString(payload.encode(root)), nil
+	}
+	return cur, nil
+}
+
+func compileMaskers(rules []types.KVMaskConfig) (map[string]mask.Masker, error) {
+	maskers := make(map[string]mask.Masker, len(rules)+1)
+	defaultMasker, err := mask.New(&defaultMaskRule)
+	if err != nil {
+		return nil, err
+	}
+	maskers[""] = defaultMasker
+	// mask rule without RuleName replaces the default masker
+	seen := make(map[string]struct{}, len(rules))
+	for idx := range rules {
+		if _, ok := seen[rules[idx].RuleName]; ok {
+			return nil, fmt.Errorf("mask rule %d: duplicated RuleName %q", idx, rules[idx].RuleName)
+		}
+		seen[rules[idx].RuleName] = struct{}{}
+		masker, err := mask.New(&rules[idx])
+		if err != nil {
+			return nil, fmt.Errorf("mask rule %d: %w", idx, err)
+		}
+		maskers[rules[idx].RuleName] = masker
+	}
+	return maskers, nil
+}
@@ -0,0 +1,320 @@
+package processer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+func TestKVProcesser_Mask(t *testing.T) {
+	type args struct {
+		rule  types.KVRules
+		input string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantOutput string
+		wantMasked []interface{}
+		wantErr    bool
+	}{
+		{
+			name: "default cover",
+			args: args{
+				rule: types.KVRules{
+					DetectRules: []types.KVDetectConfig{
+						{
+							KeyEqs: []string{"password"},
+						},
+					},
+				},
+				input: `{"password":"123456","user":{"password":"abc"},"name":"alice"}`,
+			},
+			wantOutput: `{"password":"******","user":{"password":"***"},"name":"alice"}`,
+			wantMasked: []interface{}{"******", "***"},
+			wantErr:    false,
+		},
+		{
+			name: "keep precision of numbers",
+			args: args{
+				rule: types.KVRules{
+					DetectRules: []types.KVDetectConfig{
+						{
+							KeyEqs:  []string{"account"},
+							MaskRef: "digits",
+						},
+					},
+					MaskRules: []types.KVMaskConfig{
+						{
+							RuleName: "digits",
+							MaskType: types.MaskTypeCover,
+							CoverParam: types.MaskRuleCoverParam{
+								Char:       "0",
+								Offset:     1,
+								Padding:    4,
+								DigitsOnly: true,
+							},
+						},
+					},
+				},
+				input: `{"account":12345678901234567890123,"pi":3.14159,"account2":[-3.14159]}`,
+			},
+			wantOutput: `{"account":10000000000000000000123,"pi":3.14159,"account2":[-3.14159]}`,
+			wantMasked: []interface{}{"10000000000000000000123"},
+			wantErr:    false,
+		},
+		{
+			name: "number switched to string",
+			args: args{
+				rule: types.KVRules{
+					DetectRules: []types.KVDetectConfig{
+						{
+							KeyContains: []string{"phone"},
+							MaskRef:     "phone",
+						},
+					},
+					MaskRules: []types.KVMaskConfig{
+						{
+							RuleName: "phone",
+							MaskType: types.MaskTypeCover,
+							CoverParam: types.MaskRuleCoverParam{
+								Offset:  3,
+								Padding: 4,
+							},
+						},
+					},
+				},
+				input: `{"phone":13800138000,"phones":[3.5]}`,
+			},
+			wantOutput: `{"phone":"138****8000","phones":["3*5"]}`,
+			wantMasked: []interface{}{"138****8000", "3*5"},
+			wantErr:    false,
+		},
+		{
+			name: "first rule wins",
+			args: args{
+				rule: types.KVRules{
+					DetectRules: []types.KVDetectConfig{
+						{
+							KeyEqs:  []string{"token"},
+							MaskRef: "partial",
+						},
+						{
+							ValContains: []string{"sk-"},
+						},
+					},
+					MaskRules: []types.KVMaskConfig{
+						{
+							RuleName: "partial",
+							MaskType: types.MaskTypeCover,
+							CoverParam: types.MaskRuleCoverParam{
+								Offset: 3,
+							},
+						},
+					},
+				},
+				input: `{"token":"sk-abc"}`,
+			},
+			wantOutput: `{"token":"sk-***"}`,
+			wantMasked: []interface{}{"sk-***", "sk-***"},
+			wantErr:    false,
+		},
+		{
+			name: "mask object keys",
+			args: args{
+				rule: types.KVRules{
+					DetectRules: []types.KVDetectConfig{
+						{
+							ValContains:    []string{"@"},
+							MatchObjectKey: true,
+						},
+						{
+							KeyEqs: []string{"phone"},
+						},
+					},
+					MaskRules: []types.KVMaskConfig{
+						{
+							MaskType: types.MaskTypeCover,
+							CoverParam: types.MaskRuleCoverParam{
+								Length: 3,
+							},
+						},
+					},
+				},
+				input: `{"contacts":{"***":"x","alice@example.com":{"phone":"1"},"bob@example.com":{"phone":"2"}},"owner":"carol@example.com"}`,
+			},
+			wantOutput: `{"contacts":{"***":"x","***~1":{"phone":"*"},"***~2":{"phone":"*"}},"owner":"***"}`,
+			wantMasked: []interface{}{"***~1", "*", "***~2", "*", "***"},
+			wantErr:    false,
+		},
+		{
+			name: "drop, null, const & default",
+			args: args{
+				rule: types.KVRules{
+					DetectRules: []types.KVDetectConfig{
+						{
+							KeyEqs:  []string{"password", "cards"},
+							MaskRef: "drop",
+						},
+						{
+							KeyEqs:  []string{"ssn"},
+							MaskRef: "null",
+						},
+						{
+							KeyEqs:  []string{"email"},
+							MaskRef: "const",
+						},
+						{
+							KeyEqs:  []string{"level"},
+							MaskRef: "const-number",
+						},
+						{
+							KeyContains: []string{"default"},
+							MaskRef:     "default",
+						},
+					},
+					MaskRules: []types.KVMaskConfig{
+						{
+							RuleName: "drop",
+							MaskType: types.MaskTypeDrop,
+						},
+						{
+							RuleName: "null",
+							MaskType: types.MaskTypeNull,
+						},
+						{
+							RuleName: "const",
+							MaskType: types.MaskTypeConst,
+						},
+						{
+							RuleName: "const-number",
+							MaskType: types.MaskTypeConst,
+							ConstParam: types.MaskRuleConstParam{
+								Value: -1,
+							},
+						},
+						{
+							RuleName: "default",
+							MaskType: types.MaskTypeDefault,
+						},
+					},
+				},
+				input: `{"user":{"password":"x","name":"a"},"cards":["1","2","3"],"ssn":"123","email":"a@b.c","level":3,"defaultStr":"s","defaultNum":1.5,"defaultBool":true}`,
+			},
+			wantOutput: `{"user":{"name":"a"},"cards":[],"ssn":null,"email":"[REDACTED]","level":-1,"defaultStr":"","defaultNum":0,"defaultBool":false}`,
+			wantMasked: []interface{}{"<dropped>", "<dropped>", "<dropped>", "<dropped>", "null", "[REDACTED]", "-1", "", "0", "false"},
+			wantErr:    false,
+		},
+		{
+			name: "drop array elements & keys",
+			args: args{
+				rule: types.KVRules{
+					DetectRules: []types.KVDetectConfig{
+						{
+							ValEqs: []string{"b", "d"},
+						},
+						{
+							ValContains:    []string{"@"},
+							MatchObjectKey: true,
+						},
+					},
+					MaskRules: []types.KVMaskConfig{
+						{
+							MaskType: types.MaskTypeDrop,
+							DropParam: types.MaskRuleDropParam{
+								KeepArrayIndex: true,
+							},
+						},
+					},
+				},
+				input: `{"list":["a","b",["c","d"]],"byEmail":{"a@b.c":1,"other":2}}`,
+			},
+			wantOutput: `{"list":["a",null,["c",null]],"byEmail":{"other":2}}`,
+			wantMasked: []interface{}{"null", "null", "<dropped>"},
+			wantErr:    false,
+		},
+		{
+			name: "invalid JSON",
+			args: args{
+				rule:  types.KVRules{},
+				input: `{"token":`,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewKVProcesser(tt.args.rule)
+			gotOutput, gotPairs, err := m.Mask(context.Background(), []byte(tt.args.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Mask() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if string(gotOutput) != tt.wantOutput {
+				t.Errorf("Mask() output = %v, want %v", string(gotOutput), tt.wantOutput)
+			}
+			if len(gotPairs) != len(tt.wantMasked) {
+				t.Fatalf("Mask() got %d pairs, want %d", len(gotPairs), len(tt.wantMasked))
+			}
+			for idx, pair := range gotPairs {
+				if pair.GetValMaskedString() != tt.wantMasked[idx] {
+					t.Errorf("Mask() pair %d masked = %v, want %v", idx, pair.ValMasked, tt.wantMasked[idx])
+				}
+			}
+		})
+	}
+}
+
+func TestCompileKVProcesser_MaskRef(t *testing.T) {
+	_, err := CompileKVProcesser(types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{KeyEqs: []string{"password"}, MaskRef: "missing"},
+		},
+	})
+	if err == nil {
+		t.Errorf("CompileKVProcesser() error = nil, want error")
+	}
+	_, err = CompileKVProcesser(types.KVRules{
+		MaskRules: []types.KVMaskConfig{
+			{RuleName: "unknown", MaskType: "unknown"},
+		},
+	})
+	if err == nil {
+		t.Errorf("CompileKVProcesser() error = nil, want error")
+	}
+	_, err = CompileKVProcesser(types.KVRules{
+		MaskRules: []types.KVMaskConfig{
+			{RuleName: "age", MaskType: types.MaskTypeAgeBand, AgeParam: types.MaskRuleAgeParam{Reference: "today"}},
+		},
+	})
+	if err == nil {
+		t.Errorf("CompileKVProcesser() invalid mask param error = nil, want error")
+	}
+}
+
+func TestMaskedText(t *testing.T) {
+	tests := []struct {
+		name      string
+		valMasked interface{}
+		want      string
+	}{
+		{name: "string", valMasked: "a***", want: "a***"},
+		{name: "number", valMasked: json.Number("12.50"), want: "12.50"},
+		{name: "null", valMasked: nil, want: "null"},
+		{name: "dropped", valMasked: types.KVDropped{}, want: ""},
+		{name: "JSON string", valMasked: json.RawMessage(`"a\"b"`), want: `a"b`},
+		{name: "JSON object", valMasked: json.RawMessage(`{"a":1}`), want: `{"a":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaskedText(tt.valMasked); got != tt.want {
+				t.Errorf("MaskedText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
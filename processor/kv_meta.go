@@ -0,0 +1,27 @@
+package processer
+
+import (
+	"fmt"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+// metadata of config, severity & category must be known if set
+func compileMeta(config *types.KVDetectConfig) (*types.KVRuleMeta, error) {
+	switch config.Severity {
+	case "", types.KVSeverityLow, types.KVSeverityMedium, types.KVSeverityHigh, types.KVSeverityCritical:
+	default:
+		return nil, fmt.Errorf("Severity: unknown severity %q", config.Severity)
+	}
+	switch config.Category {
+	case "", types.KVCategoryPII, types.KVCategoryPCI, types.KVCategoryPHI, types.KVCategoryCredential:
+	default:
+		return nil, fmt.Errorf("Category: unknown category %q", config.Category)
+	}
+	return config.Meta(), nil
+}
+
+// metadata of matched rule
+func (m KVProcesser) ruleMeta(configIdx int) *types.KVRuleMeta {
+	return m.detectMeta[configIdx]
+}
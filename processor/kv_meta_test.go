@@ -0,0 +1,68 @@
+package processer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+func TestKVProcesser_RuleMeta(t *testing.T) {
+	rules := types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{KeyEqs: []string{"name"}},
+			{
+				ID:          "card-number",
+				Name:        "Card number",
+				Description: "primary account number",
+				Severity:    types.KVSeverityCritical,
+				Category:    types.KVCategoryPCI,
+				Tags:        []string{"payments"},
+				Owner:       "payments-team",
+				KeyEqs:      []string{"pan"},
+			},
+		},
+	}
+	wantMeta := &types.KVRuleMeta{
+		ID:          "card-number",
+		Name:        "Card number",
+		Description: "primary account number",
+		Severity:    types.KVSeverityCritical,
+		Category:    types.KVCategoryPCI,
+		Tags:        []string{"payments"},
+		Owner:       "payments-team",
+	}
+	m := NewKVProcesser(rules)
+	input := []byte(`{"name":"a","pan":"4111111111111111"}`)
+	pairs, err := m.Detect(input)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	_, masked, err := m.Mask(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Mask() error = %v", err)
+	}
+	text := m.DetectText("pan=4111111111111111")
+	for name, got := range map[string][]types.KVPair{"Detect": pairs, "Mask": masked, "DetectText": text} {
+		byKey := map[string]*types.KVRuleMeta{}
+		for _, pair := range got {
+			byKey[pair.Key] = pair.Rule
+		}
+		if !reflect.DeepEqual(byKey["pan"], wantMeta) {
+			t.Errorf("%s() rule of pan = %+v, want %+v", name, byKey["pan"], wantMeta)
+		}
+		if byKey["name"] != nil {
+			t.Errorf("%s() rule of name = %+v, want nil", name, byKey["name"])
+		}
+	}
+
+	for _, config := range []types.KVDetectConfig{
+		{KeyEqs: []string{"a"}, Severity: "severe"},
+		{KeyEqs: []string{"a"}, Category: "secret"},
+	} {
+		if _, err := CompileKVProcesser(types.KVRules{DetectRules: []types.KVDetectConfig{config}}); err == nil {
+			t.Errorf("CompileKVProcesser(%+v) error = nil, want error", config)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package processer
+
+import (
+	"github.com/senayuki/mosaic/pkg/jsonpos"
+	"github.com/senayuki/mosaic/types"
+)
+
+// fill positions of detected pairs, input is scanned again only if anything is detected
+func locate(input []byte, found []detected) {
+	if len(found) == 0 {
+		return
+	}
+	idx, err := jsonpos.Parse(input)
+	if err != nil {
+		// accepted by fastjson, not expected
+		return
+	}
+	for i := range found {
+		found[i].pair.Pos = locatePair(idx, found[i].pair)
+	}
+}
+
+func locatePair(idx *jsonpos.Index, pair types.KVPair) *types.KVPos {
+	loc, ok := idx.Locate(pair.ValJSONPath)
+	if !ok {
+		return nil
+	}
+	path := pair.ValJSONPath.Elements()
+	if !pair.ObjectKey || isEmbedded(path) {
+		return newPos(idx.Lines, loc.Key, loc.Val)
+	}
+	// value is the member key, key is the member key of the object
+	var key jsonpos.Span
+	if parent, ok := idx.Locate(types.NewJSONPath().Append(path[:len(path)-1]...)); ok {
+		key = parent.Key
+	}
+	return newPos(idx.Lines, key, loc.Key)
+}
+
+func isEmbedded(path []interface{}) bool {
+	for _, elem := range path {
+		if _, ok := elem.(types.JSONPathDecode); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func newPos(lines *jsonpos.Lines, key, val jsonpos.Span) *types.KVPos {
+	pos := &types.KVPos{KeyStart: key.Start, KeyEnd: key.End, ValStart: val.Start, ValEnd: val.End}
+	if key.End > key.Start {
+		keyStart := lines.Position(key.Start)
+		pos.KeyLine, pos.KeyColumn = keyStart.Line, keyStart.Column
+	}
+	start, end := lines.Position(val.Start), lines.Position(val.End)
+	pos.Line, pos.Column = start.Line, start.Column
+	pos.EndLine, pos.EndColumn = end.Line, end.Column
+	return pos
+}
@@ -0,0 +1,86 @@
+package processer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+type wantPos struct {
+	path         string
+	key, val     string
+	line, column int
+}
+
+func checkPos(t *testing.T, input string, pairs []types.KVPair, want []wantPos) {
+	t.Helper()
+	if len(pairs) != len(want) {
+		t.Fatalf("got %d pairs, want %d", len(pairs), len(want))
+	}
+	for idx, pair := range pairs {
+		w, pos := want[idx], pair.Pos
+		if pair.ValJSONPath.String() != w.path || pos == nil {
+			t.Errorf("pair %d at %s, pos %v, want %s", idx, pair.ValJSONPath, pos, w.path)
+			continue
+		}
+		if got := input[pos.KeyStart:pos.KeyEnd]; got != w.key {
+			t.Errorf("%s key = %v, want %v", w.path, got, w.key)
+		}
+		if got := input[pos.ValStart:pos.ValEnd]; got != w.val {
+			t.Errorf("%s val = %v, want %v", w.path, got, w.val)
+		}
+		if pos.Line != w.line || pos.Column != w.column {
+			t.Errorf("%s at %d:%d, want %d:%d", w.path, pos.Line, pos.Column, w.line, w.column)
+		}
+	}
+}
+
+func TestKVProcesser_DetectPos(t *testing.T) {
+	input := "{\n  \"password\": \"abc\",\n  \"list\": [\"secret\"],\n  \"users\": {\"a@b.c\": 1},\n  \"payload\": \"{\\\"password\\\":1}\"\n}"
+	m := NewKVProcesser(types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{KeyEqs: []string{"password"}},
+			{ValEqs: []string{"secret"}},
+			{ValRegex: []string{`@`}, MatchObjectKey: true},
+		},
+		Decoders: []types.KVDecoder{types.KVDecoderJSON},
+	})
+	want := []wantPos{
+		{path: "password", key: `"password"`, val: `"abc"`, line: 2, column: 15},
+		{path: "list->0", val: `"secret"`, line: 3, column: 12},
+		{path: "users->a@b.c", key: `"users"`, val: `"a@b.c"`, line: 4, column: 13},
+		{path: "payload-><json>->password", key: `"payload"`, val: `"{\"password\":1}"`, line: 5, column: 14},
+	}
+	pairs, err := m.Detect([]byte(input))
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	checkPos(t, input, pairs, want)
+	_, pairs, err = m.Mask(context.Background(), []byte(input))
+	if err != nil {
+		t.Fatalf("Mask() error = %v", err)
+	}
+	checkPos(t, input, pairs, want)
+}
+
+func TestKVProcesser_MaskTextPos(t *testing.T) {
+	input := "login\n\"password\": \"a b\", phone=123 from 张三 secret"
+	m := NewKVProcesser(types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{KeyEqs: []string{"password", "phone"}},
+			{ValEqs: []string{"secret"}},
+		},
+	})
+	want := []wantPos{
+		{path: "password", key: `"password"`, val: `"a b"`, line: 2, column: 13},
+		{path: "phone", key: `phone`, val: `123`, line: 2, column: 26},
+		{path: "", val: `secret`, line: 2, column: 38},
+	}
+	_, pairs, err := m.MaskText(context.Background(), input)
+	if err != nil {
+		t.Fatalf("MaskText() error = %v", err)
+	}
+	checkPos(t, input, pairs, want)
+	checkPos(t, input, m.DetectText(input), want)
+}
@@ -1,35 +1,148 @@
 package processer
 
 import (
+	"fmt"
 	"regexp"
 
+	"github.com/senayuki/mosaic/mask"
+	"github.com/senayuki/mosaic/pkg/dict"
 	"github.com/senayuki/mosaic/types"
 )
 
 type KVProcesser struct {
-	detectConfig  []types.KVDetectConfig
-	detectKVField map[string]map[string]*types.KVField // key:val fields in config
-	detectExp     []detectExp                          // compiled regex
+	detectConfig   []types.KVDetectConfig
+	detectKVField  map[string][]*types.KVField // key field -> k-v fields relations in config order
+	detectExp      []detectExp                 // compiled regex & expression
+	detectMeta     []*types.KVRuleMeta         // metadata of rules, shared by detected pairs
+	maskers        map[string]mask.Masker      // RuleName -> masker, "" is default masker
+	decoders       []types.KVDecoder           // decoders of embedded payloads in order
+	dictionaries   map[string]*dict.Dictionary // name -> dictionary of ValDictionary
+	matchObjectKey bool                        // any config matches object keys
 }
 
+// compile rules to processer, panics if rules is invalid
 func NewKVProcesser(rules types.KVRules) KVProcesser {
-	m := KVProcesser{detectConfig: rules.DetectRules, detectKVField: map[string]map[string]*types.KVField{}}
+	m, err := CompileKVProcesser(rules)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// compile rules to processer, returns error if rules is invalid
+func CompileKVProcesser(rules types.KVRules) (KVProcesser, error) {
+	return CompileKVProcesserWith(rules, nil)
+}
+
+/*
+compile rules with dictionaries opened by caller, e.g. from embed.FS, or shared by processers,
+dictionaries of KVRules.Dictionaries not given are opened by file path
+*/
+func CompileKVProcesserWith(rules types.KVRules, dicts map[string]*dict.Dictionary) (KVProcesser, error) {
+	m := KVProcesser{detectConfig: rules.DetectRules, detectKVField: map[string][]*types.KVField{}}
+	maskers, err := compileMaskers(rules.MaskRules)
+	if err != nil {
+		return m, err
+	}
+	if m.dictionaries, err = compileDictionaries(rules.Dictionaries, dicts); err != nil {
+		return m, fmt.Errorf("Dictionaries: %w", err)
+	}
+	m.maskers = maskers
+	if m.decoders, err = compileDecoders(rules.Decoders); err != nil {
+		return m, fmt.Errorf("Decoders: %w", err)
+	}
+	globalKeyNorm, err := parseKeyNorm(rules.KeyNormalize)
+	if err != nil {
+		return m, fmt.Errorf("KeyNormalize: %w", err)
+	}
+	ids := map[string]int{}
 	for idx, config := range m.detectConfig {
-		// find all KVField
+		if config.ID != "" {
+			if exist, ok := ids[config.ID]; ok {
+				return m, fmt.Errorf("detect rule %d: ID: %q is used by detect rule %d", idx, config.ID, exist)
+			}
+			ids[config.ID] = idx
+		}
+		meta, err := compileMeta(&m.detectConfig[idx])
+		if err != nil {
+			return m, fmt.Errorf("detect rule %d: %w", idx, err)
+		}
+		m.detectMeta = append(m.detectMeta, meta)
+		// find all KVField, rules with same k-v fields share the extraction
 		if config.KVFieldOpt != nil {
-			if _, ok := m.detectKVField[config.KVFieldOpt.Key]; !ok {
-				m.detectKVField[config.KVFieldOpt.Key] = map[string]*types.KVField{}
+			for _, valField := range config.KVFieldOpt.ValFields() {
+				m.addKVField(types.KVField{Key: config.KVFieldOpt.Key, Val: valField})
+			}
+		}
+		m.matchObjectKey = m.matchObjectKey || config.MatchObjectKey
+		if _, ok := m.maskers[config.MaskRef]; !ok {
+			return m, fmt.Errorf("detect rule %d: MaskRef: unknown mask rule %q", idx, config.MaskRef)
+		}
+		// normalize key criteria
+		m.detectExp = append(m.detectExp, detectExp{KeyNorm: globalKeyNorm})
+		if len(config.KeyNormalize) > 0 {
+			if m.detectExp[idx].KeyNorm, err = parseKeyNorm(config.KeyNormalize); err != nil {
+				return m, fmt.Errorf("detect rule %d: KeyNormalize: %w", idx, err)
 			}
-			m.detectKVField[config.KVFieldOpt.Key][config.KVFieldOpt.Val] = config.KVFieldOpt
 		}
+		m.detectExp[idx].KeyEqs = m.detectExp[idx].KeyNorm.applyAll(config.KeyEqs)
+		m.detectExp[idx].KeyContains = m.detectExp[idx].KeyNorm.applyAll(config.KeyContains)
 		// compile regexp
-		m.detectExp = append(m.detectExp, detectExp{})
 		for _, regex := range config.KeyRegex {
-			m.detectExp[idx].KeyRegex = append(m.detectExp[idx].KeyRegex, regexp.MustCompile(regex))
+			exp, err := regexp.Compile(regex)
+			if err != nil {
+				return m, fmt.Errorf("detect rule %d: KeyRegex: %w", idx, err)
+			}
+			m.detectExp[idx].KeyRegex = append(m.detectExp[idx].KeyRegex, exp)
 		}
 		for _, regex := range config.ValRegex {
-			m.detectExp[idx].ValRegex = append(m.detectExp[idx].ValRegex, regexp.MustCompile(regex))
+			exp, err := regexp.Compile(regex)
+			if err != nil {
+				return m, fmt.Errorf("detect rule %d: ValRegex: %w", idx, err)
+			}
+			m.detectExp[idx].ValRegex = append(m.detectExp[idx].ValRegex, exp)
+		}
+		// parse value constraints
+		if err := validValTypes(config.ValTypes); err != nil {
+			return m, fmt.Errorf("detect rule %d: ValTypes: %w", idx, err)
+		}
+		if err := validChecksumType(config.ValChecksum); err != nil {
+			return m, fmt.Errorf("detect rule %d: ValChecksum: %w", idx, err)
+		}
+		if config.ValDictionary != "" {
+			if config.Expr != "" {
+				return m, fmt.Errorf("detect rule %d: ValDictionary: can not be used with Expr, which replaces criteria", idx)
+			}
+			d, ok := m.dictionaries[config.ValDictionary]
+			if !ok {
+				return m, fmt.Errorf("detect rule %d: ValDictionary: unknown dictionary %q", idx, config.ValDictionary)
+			}
+			m.detectExp[idx].ValDict = d
+		}
+		if config.ValRange != "" {
+			r, err := parseNumRange(config.ValRange)
+			if err != nil {
+				return m, fmt.Errorf("detect rule %d: ValRange: %w", idx, err)
+			}
+			m.detectExp[idx].ValRange = r
+		}
+		// parse expression
+		if config.Expr != "" {
+			expr, err := parseExpr(config.Expr, rules.Patterns, m.detectExp[idx].KeyNorm)
+			if err != nil {
+				return m, fmt.Errorf("detect rule %d: Expr: %w", idx, err)
+			}
+			m.detectExp[idx].Expr = expr
 		}
 	}
-	return m
+	return m, nil
+}
+
+func (m *KVProcesser) addKVField(rel types.KVField) {
+	for _, exist := range m.detectKVField[rel.Key] {
+		if exist.Val == rel.Val {
+			return
+		}
+	}
+	m.detectKVField[rel.Key] = append(m.detectKVField[rel.Key], &rel)
 }
@@ -0,0 +1,416 @@
+package processer
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/senayuki/mosaic/mask"
+	"github.com/senayuki/mosaic/types"
+)
+
+// struct tag of masking options, e.g. `mosaic:"rule=phone"`, `mosaic:"mask=cover"` or `mosaic:"-"`
+const structTagName = "mosaic"
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+/*
+mask a Go value by reflection, returns masked deep copy & masked pairs
+
+fields tagged by mosaic are masked by the mask rule referenced by rule=RuleName,
+or by mask=MaskType with default params, rule= wins if both are set;
+other fields are detected by detect rules, keyed by JSON name of field or key of map;
+fields tagged by mosaic:"-" or json:"-" are copied as is.
+tag of field applies to elements of its slices, maps & pointers, but not to fields of nested structs.
+
+unexported fields are copied shallowly and never masked, pointers, maps & slices are copied once so cycles are kept;
+encoding.TextMarshaler & []byte are masked as string, masked text is restored by
+encoding.TextUnmarshaler, string or numbers, value is set to zero value if masked text can not be restored,
+e.g. a covered int; values held by interfaces, e.g. map[string]interface{}, are replaced by masked text instead.
+*/
+func (m KVProcesser) MaskStruct(ctx context.Context, v interface{}) (interface{}, []types.KVPair, error) {
+	if v == nil {
+		return nil, nil, nil
+	}
+	w := structWalker{m: m, ctx: ctx, copied: map[structPointer]reflect.Value{}, maskers: map[types.MaskType]mask.Masker{}}
+	out, err := w.copy(reflect.ValueOf(v), types.NewJSONPath(), "", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out.Interface(), w.pairs, nil
+}
+
+// parsed mosaic tag of field
+type structTag struct {
+	skip   bool
+	masker mask.Masker
+}
+
+// identity of pointer, map or slice, slices sharing array are told apart by length
+type structPointer struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+type structWalker struct {
+	m       KVProcesser
+	ctx     context.Context
+	copied  map[structPointer]reflect.Value // copied pointers, maps & slices, keeps cycles & shared references
+	maskers map[types.MaskType]mask.Masker  // maskers of mask= tags with default params
+	pairs   []types.KVPair
+}
+
+// parse tag in form of comma separated key=value options
+func (w *structWalker) parseTag(tag string) (*structTag, error) {
+	if tag == "-" {
+		return &structTag{skip: true}, nil
+	}
+	var result structTag
+	var ruleName, maskType string
+	var hasRule bool
+	for _, opt := range strings.Split(tag, ",") {
+		opt = strings.TrimSpace(opt)
+		if opt == "" {
+			continue
+		}
+		name, val, _ := strings.Cut(opt, "=")
+		switch name {
+		case "rule":
+			ruleName, hasRule = val, true
+		case "mask":
+			maskType = val
+		default:
+			return nil, fmt.Errorf("unknown option %q", name)
+		}
+	}
+	switch {
+	case hasRule:
+		masker, ok := w.m.Masker(ruleName)
+		if !ok {
+			return nil, fmt.Errorf("unknown mask rule %q", ruleName)
+		}
+		result.masker = masker
+	case maskType != "":
+		masker, ok := w.maskers[types.MaskType(maskType)]
+		if !ok {
+			var err error
+			if masker, err = mask.New(&types.KVMaskConfig{MaskType: types.MaskType(maskType)}); err != nil {
+				return nil, err
+			}
+			w.maskers[types.MaskType(maskType)] = masker
+		}
+		result.masker = masker
+	default:
+		return nil, nil
+	}
+	return &result, nil
+}
+
+// deep copy value, leaves are masked by tag, or by detect rules if tag is nil
+func (w *structWalker) copy(v reflect.Value, path types.JSONPath, key string, tag *structTag) (reflect.Value, error) {
+	if tag != nil && tag.skip {
+		return v, nil
+	}
+	t := v.Type()
+	if isTextLeaf(t) {
+		return w.maskLeaf(v, path, key, tag, false)
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, nil
+		}
+		ptr := structPointer{ptr: v.Pointer(), typ: t}
+		if copied, ok := w.copied[ptr]; ok {
+			return copied, nil
+		}
+		out := reflect.New(t.Elem())
+		w.copied[ptr] = out
+		elem, err := w.copy(v.Elem(), path, key, tag)
+		if err != nil {
+			return v, err
+		}
+		out.Elem().Set(elem)
+		return out, nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		var elem reflect.Value
+		var err error
+		if isScalar(v.Elem().Kind()) && !isTextLeaf(v.Elem().Type()) {
+			elem, err = w.maskLeaf(v.Elem(), path, key, tag, true)
+		} else {
+			elem, err = w.copy(v.Elem(), path, key, tag)
+		}
+		if err != nil {
+			return v, err
+		}
+		out := reflect.New(t).Elem()
+		if elem.IsValid() {
+			out.Set(elem)
+		}
+		return out, nil
+	case reflect.Struct:
+		return w.copyStruct(v, path)
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+		ptr := structPointer{ptr: v.Pointer(), typ: t, len: v.Len()}
+		if copied, ok := w.copied[ptr]; ok {
+			return copied, nil
+		}
+		out := reflect.MakeSlice(t, v.Len(), v.Len())
+		w.copied[ptr] = out
+		for i := 0; i < v.Len(); i++ {
+			elem, err := w.copy(v.Index(i), path.Append(i), key, tag)
+			if err != nil {
+				return v, err
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+	case reflect.Array:
+		out := reflect.New(t).Elem()
+		for i := 0; i < v.Len(); i++ {
+			elem, err := w.copy(v.Index(i), path.Append(i), key, tag)
+			if err != nil {
+				return v, err
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+		ptr := structPointer{ptr: v.Pointer(), typ: t}
+		if copied, ok := w.copied[ptr]; ok {
+			return copied, nil
+		}
+		out := reflect.MakeMapWithSize(t, v.Len())
+		w.copied[ptr] = out
+		iter := v.MapRange()
+		for iter.Next() {
+			mapKey := mapKeyString(iter.Key())
+			elem, err := w.copy(iter.Value(), path.Append(mapKey), mapKey, tag)
+			if err != nil {
+				return v, err
+			}
+			out.SetMapIndex(iter.Key(), elem)
+		}
+		return out, nil
+	default:
+		if isScalar(t.Kind()) {
+			return w.maskLeaf(v, path, key, tag, false)
+		}
+		// channels, functions & complex numbers are kept as is
+		return v, nil
+	}
+}
+
+func isScalar(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// shallow copy struct, then replace exported fields by masked copies
+func (w *structWalker) copyStruct(v reflect.Value, path types.JSONPath) (reflect.Value, error) {
+	t := v.Type()
+	out := reflect.New(t).Elem()
+	out.Set(v)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || jsonIgnored(field) {
+			continue
+		}
+		tag, err := w.parseTag(field.Tag.Get(structTagName))
+		if err != nil {
+			return v, fmt.Errorf("%s.%s: %w", t, field.Name, err)
+		}
+		name, named := jsonFieldName(field)
+		fieldPath := path.Append(name)
+		if field.Anonymous && !named {
+			// fields of embedded struct are promoted like encoding/json
+			fieldPath = path
+		}
+		fieldVal, err := w.copy(v.Field(i), fieldPath, name, tag)
+		if err != nil {
+			return v, err
+		}
+		out.Field(i).Set(fieldVal)
+	}
+	return out, nil
+}
+
+/*
+mask leaf value and convert masked value back to type of value
+leaf held by interface is replaced by masked text if not convertible, and by nil if masked to null or dropped
+*/
+func (w *structWalker) maskLeaf(v reflect.Value, path types.JSONPath, key string, tag *structTag, inInterface bool) (reflect.Value, error) {
+	pair := types.KVPair{Key: key, ValJSONPath: path}
+	switch {
+	case isTextLeaf(v.Type()):
+		text, err := leafText(v)
+		if err != nil {
+			return v, fmt.Errorf("mask %s: %w", path, err)
+		}
+		pair.Val = text
+	case v.Kind() == reflect.String:
+		pair.Val = v.String()
+	case v.Kind() == reflect.Bool:
+		pair.Val = v.Bool()
+	case v.Kind() == reflect.Float32:
+		pair.Val = json.Number(strconv.FormatFloat(v.Float(), 'g', -1, 32))
+	case v.Kind() == reflect.Float64:
+		pair.Val = json.Number(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case v.CanInt():
+		pair.Val = json.Number(strconv.FormatInt(v.Int(), 10))
+	default:
+		pair.Val = json.Number(strconv.FormatUint(v.Uint(), 10))
+	}
+	if tag != nil {
+		valMasked, err := maskPairWith(w.ctx, tag.masker, pair)
+		if err != nil {
+			return v, fmt.Errorf("mask %s: %w", path, err)
+		}
+		pair.ValMasked = valMasked
+		pair.DetectRule = -1
+	} else {
+		var matched bool
+		var err error
+		pair, matched, err = w.m.MaskPair(w.ctx, pair)
+		if err != nil {
+			return v, fmt.Errorf("mask %s: %w", path, err)
+		}
+		if !matched {
+			return v, nil
+		}
+	}
+	w.pairs = append(w.pairs, pair)
+	if !inInterface {
+		return restoreLeaf(v.Type(), pair.ValMasked), nil
+	}
+	switch pair.ValMasked.(type) {
+	case nil, types.KVDropped:
+		return reflect.Value{}, nil
+	}
+	if out, ok := convertLeaf(v.Type(), pair.ValMasked); ok {
+		return out, nil
+	}
+	return reflect.ValueOf(MaskedText(pair.ValMasked)), nil
+}
+
+// masked as string: encoding.TextMarshaler or []byte
+func isTextLeaf(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface {
+		return false
+	}
+	if t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return true
+	}
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+func leafText(v reflect.Value) (string, error) {
+	// addressable copy for methods with pointer receiver
+	addr := reflect.New(v.Type())
+	addr.Elem().Set(v)
+	if marshaler, ok := addr.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+	return string(v.Bytes()), nil
+}
+
+// convert masked value to type t, zero value if not convertible
+func restoreLeaf(t reflect.Type, valMasked interface{}) reflect.Value {
+	out, _ := convertLeaf(t, valMasked)
+	return out
+}
+
+// convert masked value to type t, false with zero value if not convertible
+func convertLeaf(t reflect.Type, valMasked interface{}) (reflect.Value, bool) {
+	out := reflect.New(t)
+	switch valMasked.(type) {
+	case nil, types.KVDropped:
+		return out.Elem(), false
+	}
+	text := MaskedText(valMasked)
+	if out.Type().Implements(textUnmarshalerType) {
+		if err := out.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+			return reflect.New(t).Elem(), false
+		}
+		return out.Elem(), true
+	}
+	elem := out.Elem()
+	var err error
+	switch t.Kind() {
+	case reflect.String:
+		elem.SetString(text)
+	case reflect.Slice:
+		elem.SetBytes([]byte(text))
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(text); err == nil {
+			elem.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(text, 10, t.Bits()); err == nil {
+			elem.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		if u, err = strconv.ParseUint(text, 10, t.Bits()); err == nil {
+			elem.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(text, t.Bits()); err == nil {
+			elem.SetFloat(f)
+		}
+	}
+	return elem, err == nil
+}
+
+// field never serialized by encoding/json, tagged json:"-"
+func jsonIgnored(field reflect.StructField) bool {
+	return field.Tag.Get("json") == "-"
+}
+
+// name of field in JSON, false if not named by json tag, json:"-," names the field "-"
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name, false
+	}
+	return name, true
+}
+
+func mapKeyString(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	if isTextLeaf(k.Type()) {
+		if text, err := leafText(k); err == nil {
+			return text
+		}
+	}
+	return fmt.Sprint(k.Interface())
+}
@@ -0,0 +1,246 @@
+package processer
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+type structUser struct {
+	Name     string            `json:"name"`
+	Phone    string            `json:"phone" mosaic:"rule=phone"`
+	Password string            `json:"password"`
+	Secret   string            `json:"secret" mosaic:"mask=const"`
+	Emails   []string          `json:"emails" mosaic:"mask=email"`
+	Age      int               `json:"age" mosaic:"mask=cover"`
+	Birthday time.Time         `json:"birthday" mosaic:"mask=null"`
+	IP       net.IP            `json:"ip" mosaic:"mask=ip"`
+	Token    string            `json:"token" mosaic:"-"`
+	Labels   map[string]string `json:"labels"`
+	Friend   *structUser       `json:"friend"`
+	Extra    interface{}       `json:"extra"`
+	internal string
+	StructAddress
+}
+
+type StructAddress struct {
+	Password string `mosaic:"mask=drop"`
+}
+
+func TestKVProcesser_MaskStruct(t *testing.T) {
+	rule := types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{KeyEqs: []string{"password", "token"}},
+		},
+		MaskRules: []types.KVMaskConfig{
+			{RuleName: "phone", MaskType: types.MaskTypePhone},
+		},
+	}
+	birthday := time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)
+	user := &structUser{
+		Name:     "alice",
+		Phone:    "+8613812345678",
+		Password: "123456",
+		Secret:   "s3cret",
+		Emails:   []string{"alice@example.com"},
+		Age:      30,
+		Birthday: birthday,
+		IP:       net.ParseIP("192.168.1.23"),
+		Token:    "abc",
+		Labels:   map[string]string{"token": "xyz", "team": "a"},
+		Extra:    map[string]interface{}{"password": "abc", "count": 1},
+		internal: "keep",
+	}
+	user.Friend = user
+	user.StructAddress.Password = "p"
+
+	m := NewKVProcesser(rule)
+	gotOut, gotPairs, err := m.MaskStruct(context.Background(), user)
+	if err != nil {
+		t.Fatalf("MaskStruct() error = %v", err)
+	}
+	got, ok := gotOut.(*structUser)
+	if !ok {
+		t.Fatalf("MaskStruct() type = %T, want *structUser", gotOut)
+	}
+	want := structUser{
+		Name:     "alice",
+		Phone:    "+86*******5678",
+		Password: "******",
+		Secret:   "[REDACTED]",
+		Emails:   []string{"a****@example.com"},
+		Age:      0,
+		IP:       net.ParseIP("192.168.1.0"),
+		Token:    "abc",
+		Labels:   map[string]string{"token": "***", "team": "a"},
+		Extra:    map[string]interface{}{"password": "***", "count": 1},
+		internal: "keep",
+	}
+	if got == user {
+		t.Fatalf("MaskStruct() returns input pointer, want copy")
+	}
+	if got.Friend != got {
+		t.Errorf("MaskStruct() cycle not kept, Friend = %p, want %p", got.Friend, got)
+	}
+	got.Friend = nil
+	if got.StructAddress.Password != "" {
+		t.Errorf("MaskStruct() embedded Password = %q, want empty", got.StructAddress.Password)
+	}
+	got.StructAddress = StructAddress{}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("MaskStruct() = %+v, want %+v", *got, want)
+	}
+	// input is not changed
+	if user.Password != "123456" || user.Labels["token"] != "xyz" || !user.Birthday.Equal(birthday) {
+		t.Errorf("MaskStruct() changed input: %+v", *user)
+	}
+	wantPaths := map[string]bool{
+		`["phone"]`:            true,
+		`["password"]`:         true,
+		`["secret"]`:           true,
+		`["emails",0]`:         true,
+		`["age"]`:              true,
+		`["birthday"]`:         true,
+		`["ip"]`:               true,
+		`["labels","token"]`:   true,
+		`["extra","password"]`: true,
+		`["Password"]`:         true,
+	}
+	if len(gotPairs) != len(wantPaths) {
+		t.Errorf("MaskStruct() got %d pairs, want %d", len(gotPairs), len(wantPaths))
+	}
+	for _, pair := range gotPairs {
+		path, _ := json.Marshal(pair.ValJSONPath)
+		if !wantPaths[string(path)] {
+			t.Errorf("MaskStruct() unexpected pair at %s", string(path))
+		}
+	}
+}
+
+func TestKVProcesser_MaskStruct_Values(t *testing.T) {
+	type numbers struct {
+		Account uint64  `json:"account"`
+		Score   float64 `json:"score" mosaic:"mask=round"`
+		Code    int     `json:"code" mosaic:"mask=const"`
+		Raw     []byte  `json:"raw" mosaic:"mask=cover"`
+	}
+	rule := types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{KeyEqs: []string{"account"}, MaskRef: "digits"},
+			{KeyEqs: []string{"pin", "-"}},
+		},
+		MaskRules: []types.KVMaskConfig{
+			{
+				RuleName:   "digits",
+				MaskType:   types.MaskTypeCover,
+				CoverParam: types.MaskRuleCoverParam{Char: "0", Offset: 1, Padding: 2, DigitsOnly: true},
+			},
+		},
+	}
+	tests := []struct {
+		name    string
+		input   interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name:  "numbers & bytes",
+			input: numbers{Account: 123456, Score: 3.7, Code: 7, Raw: []byte("abc")},
+			want:  numbers{Account: 100056, Score: 4, Code: 0, Raw: []byte("***")},
+		},
+		{
+			name:  "map of slices",
+			input: map[string][]interface{}{"account": {"1234", 5678}},
+			want:  map[string][]interface{}{"account": {"1034", 5078}},
+		},
+		{
+			name:  "interface leaves",
+			input: map[string]interface{}{"pin": 1234, "account": 5678},
+			want:  map[string]interface{}{"pin": "****", "account": 5078},
+		},
+		{
+			name: "fields ignored by json",
+			input: struct {
+				Pin  string `json:"-"`
+				Code string `json:"pin"`
+			}{Pin: "1234", Code: "5678"},
+			want: struct {
+				Pin  string `json:"-"`
+				Code string `json:"pin"`
+			}{Pin: "1234", Code: "****"},
+		},
+		{
+			name: "field named - by json",
+			input: struct {
+				Dash string `json:"-,"`
+			}{Dash: "1234"},
+			want: struct {
+				Dash string `json:"-,"`
+			}{Dash: "****"},
+		},
+		{
+			name:  "nil",
+			input: nil,
+			want:  nil,
+		},
+		{
+			name: "unknown rule",
+			input: struct {
+				Phone string `mosaic:"rule=unknown"`
+			}{},
+			wantErr: true,
+		},
+		{
+			name: "unknown option",
+			input: struct {
+				Phone string `mosaic:"cover"`
+			}{},
+			wantErr: true,
+		},
+	}
+	m := NewKVProcesser(rule)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := m.MaskStruct(context.Background(), tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MaskStruct() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MaskStruct() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKVProcesser_MaskStruct_Cycles(t *testing.T) {
+	m := NewKVProcesser(types.KVRules{DetectRules: []types.KVDetectConfig{{KeyEqs: []string{"phone"}}}})
+	mm := map[string]interface{}{"phone": "123"}
+	mm["self"] = mm
+	got, pairs, err := m.MaskStruct(context.Background(), mm)
+	if err != nil {
+		t.Fatalf("MaskStruct() error = %v", err)
+	}
+	gotMap := got.(map[string]interface{})
+	if gotMap["phone"] != "***" || reflect.ValueOf(gotMap["self"]).Pointer() != reflect.ValueOf(gotMap).Pointer() || len(pairs) != 1 {
+		t.Errorf("MaskStruct() map cycle = %v, pairs = %d", gotMap["phone"], len(pairs))
+	}
+	if mm["phone"] != "123" {
+		t.Errorf("MaskStruct() changed input map")
+	}
+
+	s := []interface{}{map[string]interface{}{"phone": "123"}, nil}
+	s[1] = s
+	got, _, err = m.MaskStruct(context.Background(), s)
+	if err != nil {
+		t.Fatalf("MaskStruct() error = %v", err)
+	}
+	gotSlice := got.([]interface{})
+	if gotSlice[0].(map[string]interface{})["phone"] != "***" || &gotSlice[1].([]interface{})[0] != &gotSlice[0] {
+		t.Errorf("MaskStruct() slice cycle = %v", gotSlice[0])
+	}
+}
@@ -0,0 +1,177 @@
+package processer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/senayuki/mosaic/pkg/jsonpos"
+	"github.com/senayuki/mosaic/types"
+)
+
+/*
+key-value in free text, e.g. password=abc, "phone": "123" or token: 'abc'
+key starts with a letter, so times like 12:30 are not pairs
+value is a quoted string or runs until space or delimiter
+*/
+var textKVExp = regexp.MustCompile(`["']?([\p{L}_][\p{L}\p{N}_.\-]*)["']?[ \t]*[=:][ \t]*("(?:[^"\\]|\\.)*"|'[^']*'|[^\s,;&{}\[\]"'<>()]+)`)
+
+// delimiters of bare tokens in free text
+const textDelimiters = " \t\r\n,;()[]{}<>\"'`"
+
+// trailing punctuation of sentences trimmed from bare tokens
+const textTrailing = ".:!?"
+
+// candidate in free text, [start, end) is byte span of value without quotes
+type textCandidate struct {
+	pair             types.KVPair
+	start, end       int
+	quote            byte
+	keyStart, keyEnd int // with quotes, empty for bare tokens
+}
+
+// position of candidate, spans of quoted values include quotes
+func (c textCandidate) pos(lines *jsonpos.Lines) *types.KVPos {
+	val := jsonpos.Span{Start: c.start, End: c.end}
+	if c.quote != 0 {
+		val = jsonpos.Span{Start: c.start - 1, End: c.end + 1}
+	}
+	return newPos(lines, jsonpos.Span{Start: c.keyStart, End: c.keyEnd}, val)
+}
+
+// detect pairs in free text, e.g. log messages, pairs are located in text by KVPair.Pos
+// key-value like password=abc is a pair keyed by key, other tokens are pairs with empty key
+func (m KVProcesser) DetectText(text string) []types.KVPair {
+	var matched []types.KVPair
+	var lines *jsonpos.Lines
+	for _, c := range scanText(text) {
+		if configIdx, ok := m.firstMatch(c.pair); ok {
+			if lines == nil {
+				lines = jsonpos.NewLines([]byte(text))
+			}
+			c.pair.DetectRule = configIdx
+			c.pair.Rule = m.ruleMeta(configIdx)
+			c.pair.Pos = c.pos(lines)
+			matched = append(matched, c.pair)
+		}
+	}
+	return matched
+}
+
+// mask pairs in free text by the first matched rule, returns masked text & masked pairs located in text
+// masked value is written as text, dropped value is removed and quotes are kept
+func (m KVProcesser) MaskText(ctx context.Context, text string) (string, []types.KVPair, error) {
+	var out strings.Builder
+	var pairs []types.KVPair
+	var lines *jsonpos.Lines
+	last := 0
+	for _, c := range scanText(text) {
+		pair, ok, err := m.MaskPair(ctx, c.pair)
+		if err != nil {
+			return "", nil, fmt.Errorf("mask %q: %w", c.pair.Key, err)
+		}
+		if !ok {
+			continue
+		}
+		if lines == nil {
+			lines = jsonpos.NewLines([]byte(text))
+		}
+		pair.Pos = c.pos(lines)
+		pairs = append(pairs, pair)
+		if out.Len() == 0 {
+			out.Grow(len(text))
+		}
+		out.WriteString(text[last:c.start])
+		out.WriteString(textOf(pair.ValMasked, c.quote))
+		last = c.end
+	}
+	if pairs == nil {
+		return text, nil, nil
+	}
+	out.WriteString(text[last:])
+	return out.String(), pairs, nil
+}
+
+// key-values first, then bare tokens outside of key-values, in order of position
+func scanText(text string) []textCandidate {
+	var candidates []textCandidate
+	last := 0
+	for _, loc := range textKVExp.FindAllStringSubmatchIndex(text, -1) {
+		candidates = appendTokens(candidates, text, last, loc[0])
+		last = loc[1]
+		key := text[loc[2]:loc[3]]
+		keyStart, keyEnd := loc[2], loc[3]
+		if keyStart > loc[0] && keyEnd < len(text) && text[keyStart-1] == text[keyEnd] {
+			// quoted key
+			keyStart, keyEnd = keyStart-1, keyEnd+1
+		}
+		start, end := loc[4], loc[5]
+		var quote byte
+		if q := text[start]; q == '"' || q == '\'' {
+			quote = q
+			start, end = start+1, end-1
+		}
+		val := text[start:end]
+		if quote == '"' {
+			val = unescapeText(val)
+		}
+		candidates = append(candidates, textCandidate{
+			pair:     types.KVPair{Key: key, Val: val, ValJSONPath: types.NewJSONPath().Append(key)},
+			start:    start,
+			end:      end,
+			quote:    quote,
+			keyStart: keyStart,
+			keyEnd:   keyEnd,
+		})
+	}
+	return appendTokens(candidates, text, last, len(text))
+}
+
+// bare tokens in text[start:end]
+func appendTokens(candidates []textCandidate, text string, start, end int) []textCandidate {
+	for idx := start; idx < end; {
+		for idx < end && strings.IndexByte(textDelimiters, text[idx]) >= 0 {
+			idx++
+		}
+		tokenStart := idx
+		for idx < end && strings.IndexByte(textDelimiters, text[idx]) < 0 {
+			idx++
+		}
+		tokenEnd := idx
+		for tokenEnd > tokenStart && strings.IndexByte(textTrailing, text[tokenEnd-1]) >= 0 {
+			tokenEnd--
+		}
+		if tokenEnd > tokenStart {
+			candidates = append(candidates, textCandidate{
+				pair:  types.KVPair{Val: text[tokenStart:tokenEnd], ValJSONPath: types.NewJSONPath()},
+				start: tokenStart,
+				end:   tokenEnd,
+			})
+		}
+	}
+	return candidates
+}
+
+func unescapeText(s string) string {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s
+	}
+	var buf strings.Builder
+	for idx := 0; idx < len(s); idx++ {
+		if s[idx] == '\\' && idx+1 < len(s) {
+			idx++
+		}
+		buf.WriteByte(s[idx])
+	}
+	return buf.String()
+}
+
+// masked value as text, escaped in double quotes
+func textOf(valMasked interface{}, quote byte) string {
+	text := MaskedText(valMasked)
+	if quote == '"' {
+		text = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text)
+	}
+	return text
+}
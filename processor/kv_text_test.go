@@ -0,0 +1,76 @@
+package processer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+func TestKVProcesser_MaskText(t *testing.T) {
+	rule := types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{KeyEqs: []string{"password", "token"}},
+			{ValRegex: []string{`^[^@\s]+@[^@\s]+\.[a-z]+$`}, MaskRef: "email"},
+			{KeyEqs: []string{"secret"}, MaskRef: "drop"},
+		},
+		MaskRules: []types.KVMaskConfig{
+			{RuleName: "email", MaskType: types.MaskTypeEmail},
+			{RuleName: "drop", MaskType: types.MaskTypeDrop},
+		},
+	}
+	tests := []struct {
+		name      string
+		input     string
+		want      string
+		wantPairs int
+	}{
+		{
+			name:  "nothing matched",
+			input: "user alice logged in at 12:30:00",
+			want:  "user alice logged in at 12:30:00",
+		},
+		{
+			name:      "key-values",
+			input:     `login failed, password=123456 token: 'abc'`,
+			want:      `login failed, password=****** token: '***'`,
+			wantPairs: 2,
+		},
+		{
+			name:      "quoted JSON in text",
+			input:     `request {"password":"a\"b","user":"alice"}`,
+			want:      `request {"password":"***","user":"alice"}`,
+			wantPairs: 1,
+		},
+		{
+			name:      "bare token",
+			input:     "mail sent to alice@example.com.",
+			want:      "mail sent to a****@example.com.",
+			wantPairs: 1,
+		},
+		{
+			name:      "value of key-value matched by value rule",
+			input:     "contact=alice@example.com secret=abc;",
+			want:      "contact=a****@example.com secret=;",
+			wantPairs: 2,
+		},
+	}
+	m := NewKVProcesser(rule)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, pairs, err := m.MaskText(context.Background(), tt.input)
+			if err != nil {
+				t.Fatalf("MaskText() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("MaskText() = %v, want %v", got, tt.want)
+			}
+			if len(pairs) != tt.wantPairs {
+				t.Errorf("MaskText() got %d pairs, want %d", len(pairs), tt.wantPairs)
+			}
+			if detected := m.DetectText(tt.input); len(detected) != tt.wantPairs {
+				t.Errorf("DetectText() got %d pairs, want %d", len(detected), tt.wantPairs)
+			}
+		})
+	}
+}
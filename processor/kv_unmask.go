@@ -0,0 +1,149 @@
+package processer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/senayuki/mosaic/types"
+	"github.com/valyala/fastjson"
+)
+
+/*
+restore masked JSON by pairs returned from Mask, e.g. a form submitted back with masked values
+
+a value is restored only if it still equals to the masked value, values changed since masked are kept;
+renamed keys are renamed back if original key is not present, dropped object members are added back if absent,
+dropped array elements are inserted back at original index;
+pairs inside restored values and values not found (e.g. removed since masked) are ignored
+*/
+func (m KVProcesser) Unmask(ctx context.Context, input []byte, pairs []types.KVPair) ([]byte, error) {
+	var arena fastjson.Arena
+	root, err := fastjson.ParseBytes(input)
+	if err != nil {
+		return nil, err
+	}
+	// deduplicate pairs matched by several rules
+	restored := make(map[string]interface{}, len(pairs))
+	var values, structs []types.KVPair
+	for _, pair := range pairs {
+		key := pathKey(pair.ValJSONPath.Elements())
+		if pair.ObjectKey {
+			key += objectKeySuffix
+		}
+		if _, ok := restored[key]; ok {
+			continue
+		}
+		restored[key] = pair.Val
+		_, dropped := pair.ValMasked.(types.KVDropped)
+		if (dropped && len(pair.ValJSONPath.Elements()) > 0) || pair.ObjectKey {
+			structs = append(structs, pair)
+		} else {
+			values = append(values, pair)
+		}
+	}
+	// paths of pairs are paths before masking, restore structure with shallower containers first then values
+	sort.SliceStable(structs, func(i, j int) bool {
+		return unmaskLess(structs[i].ValJSONPath.Elements(), structs[j].ValJSONPath.Elements())
+	})
+	for _, pair := range structs {
+		path := pair.ValJSONPath.Elements()
+		if hasMaskedAncestor(restored, path, len(path)) {
+			continue
+		}
+		pair := pair
+		root = m.updateIfFound(&arena, root, path[:len(path)-1], func(container *fastjson.Value) (*fastjson.Value, error) {
+			if pair.ObjectKey {
+				return restoreKey(&arena, container, pair.GetValMaskedString(), pair.GetValString())
+			}
+			val, err := toJSONValue(&arena, pair.Val)
+			if err != nil {
+				return nil, err
+			}
+			return restoreMember(&arena, container, path[len(path)-1], val)
+		})
+	}
+	for _, pair := range values {
+		path := pair.ValJSONPath.Elements()
+		if hasMaskedAncestor(restored, path, len(path)) {
+			continue
+		}
+		masked, err := toJSONValue(&arena, pair.ValMasked)
+		if err != nil {
+			return nil, fmt.Errorf("unmask %s: %w", pair.ValJSONPath, err)
+		}
+		val, err := toJSONValue(&arena, pair.Val)
+		if err != nil {
+			return nil, fmt.Errorf("unmask %s: %w", pair.ValJSONPath, err)
+		}
+		maskedJSON := string(masked.MarshalTo(nil))
+		root = m.updateIfFound(&arena, root, path, func(cur *fastjson.Value) (*fastjson.Value, error) {
+			if string(cur.MarshalTo(nil)) != maskedJSON {
+				return cur, nil
+			}
+			return val, nil
+		})
+	}
+	return root.MarshalTo(nil), nil
+}
+
+// update value in path, keep root as is if path is not found any more
+func (m KVProcesser) updateIfFound(arena *fastjson.Arena, root *fastjson.Value, path []interface{}, update func(*fastjson.Value) (*fastjson.Value, error)) *fastjson.Value {
+	newRoot, err := m.setByPath(arena, root, path, update)
+	if err != nil {
+		return root
+	}
+	return newRoot
+}
+
+// shallower first, then smaller index first to insert dropped elements back in order
+func unmaskLess(path, other []interface{}) bool {
+	if len(path) != len(other) {
+		return len(path) < len(other)
+	}
+	idx, ok := path[len(path)-1].(int)
+	otherIdx, otherOk := other[len(other)-1].(int)
+	return ok && otherOk && idx < otherIdx
+}
+
+// rename masked key back in original order
+func restoreKey(arena *fastjson.Arena, obj *fastjson.Value, maskedKey, key string) (*fastjson.Value, error) {
+	if obj.Type() != fastjson.TypeObject || obj.Get(maskedKey) == nil || obj.Get(key) != nil {
+		return obj, nil
+	}
+	newObj := arena.NewObject()
+	obj.GetObject().Visit(func(k []byte, v *fastjson.Value) {
+		if string(k) == maskedKey {
+			newObj.Set(key, v)
+			return
+		}
+		newObj.Set(string(k), v)
+	})
+	return newObj, nil
+}
+
+// add dropped member back to object, or insert dropped element back to array
+func restoreMember(arena *fastjson.Arena, container *fastjson.Value, member interface{}, val *fastjson.Value) (*fastjson.Value, error) {
+	switch e := member.(type) {
+	case string:
+		if container.Type() == fastjson.TypeObject && container.Get(e) == nil {
+			container.Set(e, val)
+		}
+	case int:
+		if container.Type() != fastjson.TypeArray || len(container.GetArray()) < e {
+			return container, nil
+		}
+		arr := arena.NewArray()
+		items := container.GetArray()
+		for idx := 0; idx <= len(items); idx++ {
+			if idx == e {
+				arr.SetArrayItem(len(arr.GetArray()), val)
+			}
+			if idx < len(items) {
+				arr.SetArrayItem(len(arr.GetArray()), items[idx])
+			}
+		}
+		return arr, nil
+	}
+	return container, nil
+}
@@ -0,0 +1,101 @@
+package processer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+func TestKVProcesser_Unmask(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  types.KVRules
+		input string
+		// edit masked output before unmask, e.g. by front-end form
+		edit       func(masked string) string
+		wantOutput string
+	}{
+		{
+			name: "values",
+			rule: types.KVRules{
+				DetectRules: []types.KVDetectConfig{
+					{KeyEqs: []string{"password", "account", "active"}},
+				},
+			},
+			input:      `{"password":"123456","user":{"password":"abc","account":1234567890123456789012},"active":true}`,
+			wantOutput: `{"password":"123456","user":{"password":"abc","account":1234567890123456789012},"active":true}`,
+		},
+		{
+			name: "changed value is kept",
+			rule: types.KVRules{
+				DetectRules: []types.KVDetectConfig{
+					{KeyEqs: []string{"password"}},
+				},
+			},
+			input: `{"password":"123456","user":{"password":"abc"}}`,
+			edit: func(string) string {
+				return `{"password":"new","user":{"password":"***"}}`
+			},
+			wantOutput: `{"password":"new","user":{"password":"abc"}}`,
+		},
+		{
+			name: "dropped members & elements",
+			rule: types.KVRules{
+				DetectRules: []types.KVDetectConfig{
+					{KeyEqs: []string{"token"}, MaskRef: "drop"},
+					{ValEqs: []string{"secret"}, MaskRef: "drop"},
+				},
+				MaskRules: []types.KVMaskConfig{
+					{RuleName: "drop", MaskType: types.MaskTypeDrop},
+				},
+			},
+			input:      `{"token":"abc","list":["secret","a","secret","b",{"token":"c","name":"d"}],"name":"e"}`,
+			wantOutput: `{"list":["secret","a","secret","b",{"name":"d","token":"c"}],"name":"e","token":"abc"}`,
+		},
+		{
+			name: "object keys",
+			rule: types.KVRules{
+				DetectRules: []types.KVDetectConfig{
+					{ValRegex: []string{`@`}, MatchObjectKey: true},
+					{KeyEqs: []string{"phone"}},
+				},
+			},
+			input:      `{"users":{"a@b.c":{"phone":"123"},"name":"x"}}`,
+			wantOutput: `{"users":{"a@b.c":{"phone":"123"},"name":"x"}}`,
+		},
+		{
+			name: "embedded payload",
+			rule: types.KVRules{
+				DetectRules: []types.KVDetectConfig{
+					{KeyEqs: []string{"password"}},
+				},
+				Decoders: []types.KVDecoder{types.KVDecoderJSON},
+			},
+			input:      `{"payload":"{\"password\":\"abc\"}"}`,
+			wantOutput: `{"payload":"{\"password\":\"abc\"}"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewKVProcesser(tt.rule)
+			masked, pairs, err := m.Mask(context.Background(), []byte(tt.input))
+			if err != nil {
+				t.Fatalf("Mask() error = %v", err)
+			}
+			if string(masked) == tt.input {
+				t.Fatalf("Mask() not masked: %v", string(masked))
+			}
+			if tt.edit != nil {
+				masked = []byte(tt.edit(string(masked)))
+			}
+			got, err := m.Unmask(context.Background(), masked, pairs)
+			if err != nil {
+				t.Fatalf("Unmask() error = %v", err)
+			}
+			if string(got) != tt.wantOutput {
+				t.Errorf("Unmask() = %v, want %v", string(got), tt.wantOutput)
+			}
+		})
+	}
+}
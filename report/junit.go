@@ -0,0 +1,87 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+/*
+write report as JUnit XML, a test suite per file and a failed test case per finding
+files without findings have a passed test case
+*/
+func (r Report) WriteJUnit(w io.Writer) error {
+	var files []string
+	byFile := map[string][]Finding{}
+	for _, file := range r.Files {
+		if _, ok := byFile[file]; !ok {
+			files = append(files, file)
+			byFile[file] = nil
+		}
+	}
+	for _, f := range r.Findings {
+		if _, ok := byFile[f.File]; !ok {
+			files = append(files, f.File)
+		}
+		byFile[f.File] = append(byFile[f.File], f)
+	}
+	suites := junitTestSuites{Name: "mosaic"}
+	for _, file := range files {
+		uri := fileURI(file)
+		suite := junitTestSuite{Name: uri}
+		for _, f := range byFile[file] {
+			id := ruleID(r.rule(f))
+			at := fmt.Sprintf("%s:%d:%d", uri, f.Region.StartLine, f.Region.StartColumn)
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      fmt.Sprintf("%s %s", id, at),
+				ClassName: uri,
+				Failure:   &junitFailure{Message: message(f.Pair), Type: id, Text: at + ": " + message(f.Pair)},
+			})
+			suite.Failures++
+		}
+		if len(suite.Cases) == 0 {
+			suite.Cases = append(suite.Cases, junitTestCase{Name: "no sensitive data", ClassName: uri})
+		}
+		suite.Tests = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,239 @@
+// SARIF & JUnit reports of detections, located in source documents
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/senayuki/mosaic/pkg/jsonpos"
+	"github.com/senayuki/mosaic/types"
+)
+
+// metadata of detect rule
+type Rule struct {
+	ID          string // KVDetectConfig.ID, or "detect/<index>" by index in KVRules.DetectRules
+	Name        string
+	Description string // KVDetectConfig.Description, or criteria of rule, e.g. keyEqs: password, token
+	MaskRef     string
+	Severity    types.KVSeverity
+	Category    types.KVCategory
+	Tags        []string
+	Owner       string
+}
+
+// rules of detect rules in order, so KVPair.DetectRule indexes rules
+// rules without ID are identified by index, which changes if rules are reordered
+func NewRules(rules types.KVRules) []Rule {
+	result := make([]Rule, 0, len(rules.DetectRules))
+	for idx, config := range rules.DetectRules {
+		rule := Rule{
+			ID:          config.ID,
+			Name:        config.Name,
+			Description: config.Description,
+			MaskRef:     config.MaskRef,
+			Severity:    config.Severity,
+			Category:    config.Category,
+			Tags:        config.Tags,
+			Owner:       config.Owner,
+		}
+		if rule.ID == "" {
+			rule.ID = fmt.Sprintf("detect/%d", idx)
+		}
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("Detect%d", idx)
+		}
+		if rule.Description == "" {
+			rule.Description = describe(config)
+		}
+		result = append(result, rule)
+	}
+	return result
+}
+
+// criteria of rule in form of "keyEqs: password, token; matchMode: and"
+func describe(config types.KVDetectConfig) string {
+	var parts []string
+	add := func(name string, values ...string) {
+		if len(values) > 0 && values[0] != "" {
+			parts = append(parts, name+": "+strings.Join(values, ", "))
+		}
+	}
+	if config.Expr != "" {
+		add("expr", config.Expr)
+	} else {
+		add("keyEqs", config.KeyEqs...)
+		add("valEqs", config.ValEqs...)
+		add("keyContains", config.KeyContains...)
+		add("valContains", config.ValContains...)
+		add("keyRegex", config.KeyRegex...)
+		add("valRegex", config.ValRegex...)
+		add("valDictionary", config.ValDictionary)
+		add("matchMode", string(config.MatchMode))
+	}
+	if config.KVFieldOpt != nil {
+		add("kvField", config.KVFieldOpt.Key+" -> "+strings.Join(config.KVFieldOpt.ValFields(), ", "))
+	}
+	if len(parts) == 0 {
+		return "sensitive data"
+	}
+	return strings.Join(parts, "; ")
+}
+
+/*
+region in file, lines & columns are 1-based, columns count unicode code points
+end is exclusive, ByteLength is 0 if span is unknown
+*/
+type Region struct {
+	StartLine   int
+	StartColumn int
+	EndLine     int
+	EndColumn   int
+	ByteOffset  int
+	ByteLength  int
+}
+
+type Finding struct {
+	File   string
+	Pair   types.KVPair
+	Region Region
+}
+
+// document in file, e.g. the whole JSON file or a line of NDJSON
+type Document struct {
+	File   string
+	Input  []byte
+	Offset int // byte offset of document in file
+	Line   int // line of document start in file, document starts at column 1
+}
+
+/*
+findings of pairs detected in document, located by KVPair.Pos,
+or by path in document if pair has no position, e.g. pairs of MaskPair
+pairs can not be located are reported at document start
+*/
+func (d Document) Findings(pairs []types.KVPair) []Finding {
+	line := d.Line
+	if line == 0 {
+		line = 1
+	}
+	var idx *jsonpos.Index
+	var parsed bool
+	findings := make([]Finding, 0, len(pairs))
+	for _, pair := range pairs {
+		pos := pair.Pos
+		if pos == nil {
+			if !parsed {
+				idx, _ = jsonpos.Parse(d.Input)
+				parsed = true
+			}
+			pos = locate(idx, pair)
+		}
+		finding := Finding{File: d.File, Pair: pair, Region: Region{StartLine: line, StartColumn: 1, ByteOffset: d.Offset}}
+		if pos != nil {
+			finding.Region = Region{
+				StartLine:   pos.Line + line - 1,
+				StartColumn: pos.Column,
+				EndLine:     pos.EndLine + line - 1,
+				EndColumn:   pos.EndColumn,
+				ByteOffset:  d.Offset + pos.ValStart,
+				ByteLength:  pos.ValEnd - pos.ValStart,
+			}
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// value span of pair in document, nil if document is not JSON or path is not found
+func locate(idx *jsonpos.Index, pair types.KVPair) *types.KVPos {
+	if idx == nil {
+		return nil
+	}
+	loc, ok := idx.Locate(pair.ValJSONPath)
+	if !ok {
+		return nil
+	}
+	span := loc.Val
+	if pair.ObjectKey && loc.Key != (jsonpos.Span{}) {
+		span = loc.Key
+	}
+	start, end := idx.Position(span.Start), idx.Position(span.End)
+	return &types.KVPos{ValStart: span.Start, ValEnd: span.End, Line: start.Line, Column: start.Column, EndLine: end.Line, EndColumn: end.Column}
+}
+
+type Report struct {
+	Rules    []Rule
+	Files    []string // files scanned, files without findings are passed cases of JUnit
+	Findings []Finding
+}
+
+// rule of finding, nil if pair is not detected by rules, e.g. masked by struct tag
+func (r Report) rule(f Finding) *Rule {
+	if f.Pair.DetectRule < 0 || f.Pair.DetectRule >= len(r.Rules) {
+		return nil
+	}
+	return &r.Rules[f.Pair.DetectRule]
+}
+
+func ruleID(rule *Rule) string {
+	if rule == nil {
+		return "mosaic"
+	}
+	return rule.ID
+}
+
+// SARIF level by severity, rules without severity are errors
+func level(rule *Rule) string {
+	if rule == nil {
+		return "error"
+	}
+	switch rule.Severity {
+	case types.KVSeverityMedium:
+		return "warning"
+	case types.KVSeverityLow:
+		return "note"
+	}
+	return "error"
+}
+
+// message without original value
+func message(pair types.KVPair) string {
+	path := pair.ValJSONPath.String()
+	switch {
+	case pair.ObjectKey:
+		return fmt.Sprintf("sensitive object key at %s", path)
+	case pair.Key == "":
+		return "sensitive value"
+	case path == "":
+		return fmt.Sprintf("sensitive value of key %q", pair.Key)
+	default:
+		return fmt.Sprintf("sensitive value of key %q at %s", pair.Key, path)
+	}
+}
+
+// file path in slashes, stdin is "stdin"
+func fileURI(file string) string {
+	if file == "-" || file == "" {
+		return "stdin"
+	}
+	return filepath.ToSlash(file)
+}
+
+/*
+fingerprints of findings, stable when lines move: hash of rule, file, path & key
+repeated findings of the same fingerprint are numbered in order
+*/
+func fingerprints(r Report) []string {
+	result := make([]string, len(r.Findings))
+	seen := map[string]int{}
+	for idx, f := range r.Findings {
+		sum := sha256.Sum256([]byte(strings.Join([]string{ruleID(r.rule(f)), fileURI(f.File), f.Pair.ValJSONPath.String(), f.Pair.Key}, "\x00")))
+		fp := hex.EncodeToString(sum[:16])
+		seen[fp]++
+		result[idx] = fmt.Sprintf("%s:%d", fp, seen[fp])
+	}
+	return result
+}
@@ -0,0 +1,189 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+)
+
+var testRules = types.KVRules{
+	DetectRules: []types.KVDetectConfig{
+		{KeyEqs: []string{"password", "phone"}},
+		{ValRegex: []string{`@`}, MatchObjectKey: true, MaskRef: "drop"},
+	},
+	MaskRules: []types.KVMaskConfig{
+		{RuleName: "drop", MaskType: types.MaskTypeDrop},
+	},
+}
+
+func TestDocument_Findings(t *testing.T) {
+	input := "{\n  \"user\": {\"phone\": \"123\"},\n  \"emails\": {\"a@b.c\": 1}\n}"
+	m := processer.NewKVProcesser(testRules)
+	pairs, err := m.Detect([]byte(input))
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	tests := []struct {
+		name  string
+		doc   Document
+		noPos bool // pairs without KVPair.Pos, located by path
+		want  []Region
+	}{
+		{
+			name: "file",
+			doc:  Document{File: "a.json", Input: []byte(input)},
+			want: []Region{
+				{StartLine: 2, StartColumn: 21, EndLine: 2, EndColumn: 26, ByteOffset: 22, ByteLength: 5},
+				{StartLine: 3, StartColumn: 14, EndLine: 3, EndColumn: 21, ByteOffset: 43, ByteLength: 7},
+			},
+		},
+		{
+			name: "document in file",
+			doc:  Document{File: "a.ndjson", Input: []byte(input), Offset: 100, Line: 10},
+			want: []Region{
+				{StartLine: 11, StartColumn: 21, EndLine: 11, EndColumn: 26, ByteOffset: 122, ByteLength: 5},
+				{StartLine: 12, StartColumn: 14, EndLine: 12, EndColumn: 21, ByteOffset: 143, ByteLength: 7},
+			},
+		},
+		{
+			name:  "located by path",
+			doc:   Document{File: "a.json", Input: []byte(input)},
+			noPos: true,
+			want: []Region{
+				{StartLine: 2, StartColumn: 21, EndLine: 2, EndColumn: 26, ByteOffset: 22, ByteLength: 5},
+				{StartLine: 3, StartColumn: 14, EndLine: 3, EndColumn: 21, ByteOffset: 43, ByteLength: 7},
+			},
+		},
+		{
+			name:  "not JSON",
+			doc:   Document{File: "a.log", Input: []byte("phone=123"), Offset: 5, Line: 3},
+			noPos: true,
+			want: []Region{
+				{StartLine: 3, StartColumn: 1, ByteOffset: 5},
+				{StartLine: 3, StartColumn: 1, ByteOffset: 5},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := append([]types.KVPair(nil), pairs...)
+			for idx := range input {
+				if tt.noPos {
+					input[idx].Pos = nil
+				}
+			}
+			findings := tt.doc.Findings(input)
+			if len(findings) != len(tt.want) {
+				t.Fatalf("Findings() got %d findings, want %d", len(findings), len(tt.want))
+			}
+			for idx, f := range findings {
+				if f.Region != tt.want[idx] {
+					t.Errorf("Findings()[%d] = %+v, want %+v", idx, f.Region, tt.want[idx])
+				}
+			}
+		})
+	}
+}
+
+func testReport(t *testing.T) Report {
+	m := processer.NewKVProcesser(testRules)
+	input := `{"password":"abc","list":[{"password":"x"},{"password":"y"}]}`
+	_, pairs, err := m.Mask(context.Background(), []byte(input))
+	if err != nil {
+		t.Fatalf("Mask() error = %v", err)
+	}
+	return Report{
+		Rules:    NewRules(testRules),
+		Files:    []string{"a.json", "clean.json"},
+		Findings: Document{File: "a.json", Input: []byte(input)}.Findings(pairs),
+	}
+}
+
+func TestNewRules(t *testing.T) {
+	rules := NewRules(types.KVRules{DetectRules: []types.KVDetectConfig{
+		{KeyEqs: []string{"token"}},
+		{ID: "password", Name: "Password", Severity: types.KVSeverityLow, Category: types.KVCategoryCredential, Tags: []string{"auth"}, KeyEqs: []string{"password"}},
+	}})
+	if rules[0].ID != "detect/0" || rules[0].Name != "Detect0" || rules[0].Description != "keyEqs: token" {
+		t.Errorf("NewRules() rule without metadata = %+v", rules[0])
+	}
+	if rules[1].ID != "password" || rules[1].Name != "Password" || rules[1].Category != types.KVCategoryCredential {
+		t.Errorf("NewRules() rule with metadata = %+v", rules[1])
+	}
+
+	var buf bytes.Buffer
+	r := Report{Rules: rules, Findings: []Finding{{File: "a.json", Pair: types.KVPair{Key: "password", DetectRule: 1}, Region: Region{StartLine: 1}}}}
+	if err := r.WriteSARIF(&buf); err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+	var log sarifLog
+	json.Unmarshal(buf.Bytes(), &log)
+	rule, result := log.Runs[0].Tool.Driver.Rules[1], log.Runs[0].Results[0]
+	if rule.DefaultConfiguration.Level != "note" || rule.Properties["category"] != "credential" || result.RuleID != "password" || result.Level != "note" {
+		t.Errorf("WriteSARIF() rule = %+v, result = %+v", rule, result)
+	}
+}
+
+func TestReport_WriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testReport(t).WriteSARIF(&buf); err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("WriteSARIF() version = %v, runs = %d", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[0].ShortDescription.Text != "keyEqs: password, phone" {
+		t.Errorf("WriteSARIF() rules = %+v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 3 {
+		t.Fatalf("WriteSARIF() got %d results, want 3", len(run.Results))
+	}
+	fps := map[string]bool{}
+	for _, result := range run.Results {
+		if result.RuleID != "detect/0" || result.RuleIndex == nil || *result.RuleIndex != 0 {
+			t.Errorf("WriteSARIF() rule = %v", result.RuleID)
+		}
+		region := result.Locations[0].PhysicalLocation.Region
+		if region.StartLine != 1 || region.ByteOffset == nil || region.ByteLength == nil {
+			t.Errorf("WriteSARIF() region = %+v", region)
+		}
+		fps[result.PartialFingerprints["mosaicFinding/v1"]] = true
+		if strings.Contains(result.Message.Text, "abc") {
+			t.Errorf("WriteSARIF() message has original value: %v", result.Message.Text)
+		}
+	}
+	if len(fps) != 3 {
+		t.Errorf("WriteSARIF() fingerprints not unique: %v", fps)
+	}
+}
+
+func TestReport_WriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testReport(t).WriteJUnit(&buf); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("xml.Unmarshal() error = %v", err)
+	}
+	if suites.Tests != 4 || suites.Failures != 3 || len(suites.Suites) != 2 {
+		t.Fatalf("WriteJUnit() tests = %d, failures = %d, suites = %d", suites.Tests, suites.Failures, len(suites.Suites))
+	}
+	if got := suites.Suites[0].Cases[0].Name; got != "detect/0 a.json:1:13" {
+		t.Errorf("WriteJUnit() case = %v", got)
+	}
+	if clean := suites.Suites[1]; clean.Name != "clean.json" || clean.Failures != 0 || clean.Cases[0].Failure != nil {
+		t.Errorf("WriteJUnit() clean suite = %+v", clean)
+	}
+}
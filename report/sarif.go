@@ -0,0 +1,162 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration     `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           *int              `json:"ruleIndex,omitempty"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int  `json:"startLine"`
+	StartColumn int  `json:"startColumn,omitempty"`
+	EndLine     int  `json:"endLine,omitempty"`
+	EndColumn   int  `json:"endColumn,omitempty"`
+	ByteOffset  *int `json:"byteOffset,omitempty"`
+	ByteLength  *int `json:"byteLength,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// write report as SARIF 2.1.0, relative paths are relative to %SRCROOT%
+func (r Report) WriteSARIF(w io.Writer) error {
+	rules := make([]sarifRule, 0, len(r.Rules))
+	for _, rule := range r.Rules {
+		sr := sarifRule{
+			ID:                   rule.ID,
+			Name:                 rule.Name,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{Level: level(&rule)},
+		}
+		properties := map[string]interface{}{}
+		for k, v := range map[string]string{"maskRef": rule.MaskRef, "severity": string(rule.Severity), "category": string(rule.Category), "owner": rule.Owner} {
+			if v != "" {
+				properties[k] = v
+			}
+		}
+		if len(rule.Tags) > 0 {
+			properties["tags"] = rule.Tags
+		}
+		if len(properties) > 0 {
+			sr.Properties = properties
+		}
+		rules = append(rules, sr)
+	}
+	fps := fingerprints(r)
+	results := make([]sarifResult, 0, len(r.Findings))
+	for idx, f := range r.Findings {
+		result := sarifResult{
+			RuleID:              ruleID(r.rule(f)),
+			Level:               level(r.rule(f)),
+			Message:             sarifMessage{Text: message(f.Pair)},
+			PartialFingerprints: map[string]string{"mosaicFinding/v1": fps[idx]},
+		}
+		if r.rule(f) != nil {
+			ruleIndex := f.Pair.DetectRule
+			result.RuleIndex = &ruleIndex
+		}
+		location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: fileURI(f.File)},
+			Region: sarifRegion{
+				StartLine:   f.Region.StartLine,
+				StartColumn: f.Region.StartColumn,
+				EndLine:     f.Region.EndLine,
+				EndColumn:   f.Region.EndColumn,
+			},
+		}}
+		if !filepath.IsAbs(f.File) {
+			location.PhysicalLocation.ArtifactLocation.URIBaseID = "%SRCROOT%"
+		}
+		if f.Region.ByteLength > 0 {
+			offset, length := f.Region.ByteOffset, f.Region.ByteLength
+			location.PhysicalLocation.Region.ByteOffset = &offset
+			location.PhysicalLocation.Region.ByteLength = &length
+		}
+		if path := f.Pair.ValJSONPath.String(); path != "" {
+			location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: path, Kind: "member"}}
+		}
+		result.Locations = []sarifLocation{location}
+		results = append(results, result)
+	}
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool:       sarifTool{Driver: sarifDriver{Name: "mosaic", InformationURI: "https://github.com/senayuki/mosaic", Rules: rules}},
+			ColumnKind: "unicodeCodePoints",
+			Results:    results,
+		}},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
@@ -0,0 +1,194 @@
+/*
+curated rule packs of regulations, used as base rule sets:
+
+	extends: pci-dss        # latest version shipped
+	extends: pci-dss@1.0.0  # pinned, keeps resolving after the pack is upgraded
+
+every released version is embedded as packs/<name>/<version>.yaml and never changed,
+changes of rules are released as new versions
+rules files of rulefile resolve packs by extends, rule set registries take them by
+ruleset.NewRegistry(append(rulepack.Sets(), sets...)...)
+rule IDs are prefixed by pack, e.g. pci.cvv, so rules can be overridden or disabled by ID
+*/
+package rulepack
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/senayuki/mosaic/types"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed packs
+var packFS embed.FS
+
+type Pack struct {
+	Name        string
+	Version     string // semantic version major.minor.patch
+	Regulation  string // regulation & version the pack maps to, e.g. PCI DSS v4.0
+	Description string
+	Rules       types.KVRules // detect rules with IDs & metadata, and recommended mask rules
+}
+
+// ID of pinned version, e.g. pci-dss@1.0.0
+func (p Pack) ID() string {
+	return p.Name + "@" + p.Version
+}
+
+// rule set of pack named by name
+func (p Pack) Set() types.KVRuleSet {
+	return types.KVRuleSet{Name: p.Name, KVRules: p.Rules}
+}
+
+// all versions of packs, in order of name & version
+var packs = mustLoad(packFS)
+
+// latest versions of packs in order of name
+func Packs() []Pack {
+	var result []Pack
+	for idx, p := range packs {
+		if idx+1 == len(packs) || packs[idx+1].Name != p.Name {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// versions of pack in order of version, nil if pack is unknown
+func Versions(name string) []Pack {
+	var result []Pack
+	for _, p := range packs {
+		if p.Name == name {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// latest version of pack by name, or pinned version by ID
+func Get(name string) (Pack, bool) {
+	if _, _, pinned := strings.Cut(name, "@"); pinned {
+		for _, p := range packs {
+			if p.ID() == name {
+				return p, true
+			}
+		}
+		return Pack{}, false
+	}
+	versions := Versions(name)
+	if len(versions) == 0 {
+		return Pack{}, false
+	}
+	return versions[len(versions)-1], true
+}
+
+// rule sets of packs, latest versions named by name & every version by pinned ID
+func Sets() []types.KVRuleSet {
+	latest := Packs()
+	sets := make([]types.KVRuleSet, 0, len(latest)+len(packs))
+	for _, p := range latest {
+		sets = append(sets, p.Set())
+	}
+	for _, p := range packs {
+		set := p.Set()
+		set.Name = p.ID()
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+func mustLoad(fsys fs.FS) []Pack {
+	result, err := loadAll(fsys)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// packs/<name>/<version>.yaml of fsys, in order of name & version
+func loadAll(fsys fs.FS) ([]Pack, error) {
+	names, err := fs.Glob(fsys, "packs/*/*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Pack, 0, len(names))
+	for _, name := range names {
+		p, err := load(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("rule pack %s: %w", name, err)
+		}
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return compareVersion(result[i].Version, result[j].Version) < 0
+	})
+	return result, nil
+}
+
+// YAML converted to JSON, fields are matched case-insensitively like rulefile
+func load(fsys fs.FS, name string) (Pack, error) {
+	var p Pack
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return p, err
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return p, err
+	}
+	if data, err = json.Marshal(doc); err != nil {
+		return p, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&p); err != nil {
+		return p, err
+	}
+	wantName, wantVersion := path.Base(path.Dir(name)), strings.TrimSuffix(path.Base(name), ".yaml")
+	if p.Name != wantName || p.Version != wantVersion {
+		return p, fmt.Errorf("name %q & version %q, want name %q & version %q", p.Name, p.Version, wantName, wantVersion)
+	}
+	if _, ok := parseVersion(p.Version); !ok {
+		return p, fmt.Errorf("version %q is not major.minor.patch", p.Version)
+	}
+	return p, nil
+}
+
+func parseVersion(version string) ([3]int, bool) {
+	var result [3]int
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return result, false
+	}
+	for idx, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return result, false
+		}
+		result[idx] = n
+	}
+	return result, true
+}
+
+// compare valid versions numerically, 1.10.0 is after 1.9.0
+func compareVersion(a, b string) int {
+	va, _ := parseVersion(a)
+	vb, _ := parseVersion(b)
+	for idx := range va {
+		if va[idx] != vb[idx] {
+			return va[idx] - vb[idx]
+		}
+	}
+	return 0
+}
@@ -0,0 +1,159 @@
+package rulepack
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/ruleset"
+	"github.com/senayuki/mosaic/types"
+)
+
+// labelled sample of corpus, want is rule IDs by path of detected pairs
+type sample struct {
+	Name   string              `json:"name"`
+	Input  json.RawMessage     `json:"input"`
+	Want   map[string][]string `json:"want"`
+	Output json.RawMessage     `json:"output"` // masked input, not checked if empty
+}
+
+func readCorpus(t *testing.T, name string) []sample {
+	f, err := os.Open("testdata/" + name + ".jsonl")
+	if err != nil {
+		t.Fatalf("os.Open() error = %v", err)
+	}
+	defer f.Close()
+	var samples []sample
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s sample
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		samples = append(samples, s)
+	}
+	return samples
+}
+
+func TestPacks_Corpus(t *testing.T) {
+	if len(Packs()) != 4 {
+		t.Errorf("Packs() = %d packs, want 4", len(Packs()))
+	}
+	for _, p := range Packs() {
+		t.Run(p.Name, func(t *testing.T) {
+			m, err := processer.CompileKVProcesser(p.Rules)
+			if err != nil {
+				t.Fatalf("CompileKVProcesser() error = %v", err)
+			}
+			for _, rule := range p.Rules.DetectRules {
+				if rule.ID == "" || rule.Severity == "" || rule.Category == "" {
+					t.Errorf("rule %+v has no ID, severity or category", rule)
+				}
+			}
+			samples := readCorpus(t, p.Name)
+			if len(samples) == 0 {
+				t.Fatalf("no samples of %s", p.Name)
+			}
+			for _, s := range samples {
+				pairs, err := m.Detect(s.Input)
+				if err != nil {
+					t.Fatalf("%s: Detect() error = %v", s.Name, err)
+				}
+				got := map[string][]string{}
+				for _, pair := range pairs {
+					path := pair.ValJSONPath.String()
+					got[path] = append(got[path], pair.Rule.ID)
+				}
+				if !reflect.DeepEqual(got, s.Want) {
+					t.Errorf("%s: Detect() = %v, want %v", s.Name, got, s.Want)
+				}
+				if len(s.Output) == 0 {
+					continue
+				}
+				output, _, err := m.Mask(context.Background(), s.Input)
+				if err != nil {
+					t.Fatalf("%s: Mask() error = %v", s.Name, err)
+				}
+				if !jsonEqual(output, s.Output) {
+					t.Errorf("%s: Mask() = %s, want %s", s.Name, output, s.Output)
+				}
+			}
+		})
+	}
+}
+
+func jsonEqual(a, b []byte) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func TestGet(t *testing.T) {
+	p, ok := Get("pci-dss")
+	if !ok {
+		t.Fatalf("Get() pci-dss not found")
+	}
+	if pinned, ok := Get(p.ID()); !ok || pinned.Name != "pci-dss" {
+		t.Errorf("Get(%q) = %v, %v", p.ID(), pinned.Name, ok)
+	}
+	if _, ok := Get("pci-dss@0.0.0"); ok {
+		t.Errorf("Get() unknown version found")
+	}
+	if got := len(Sets()); got != 2*len(Packs()) {
+		t.Errorf("Sets() = %d sets, want %d", got, 2*len(Packs()))
+	}
+}
+
+func TestVersions(t *testing.T) {
+	pack := func(version, key string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte("name: demo\nversion: " + version + "\nrules:\n  detectRules:\n    - id: demo.key\n      keyEqs: [" + key + "]\n")}
+	}
+	loaded, err := loadAll(fstest.MapFS{
+		"packs/demo/1.9.0.yaml":  pack("1.9.0", "b"),
+		"packs/demo/1.10.0.yaml": pack("1.10.0", "c"),
+		"packs/demo/1.0.0.yaml":  pack("1.0.0", "a"),
+	})
+	if err != nil {
+		t.Fatalf("loadAll() error = %v", err)
+	}
+	defer func(embedded []Pack) { packs = embedded }(packs)
+	packs = loaded
+
+	var versions []string
+	for _, p := range Versions("demo") {
+		versions = append(versions, p.Version)
+	}
+	if want := []string{"1.0.0", "1.9.0", "1.10.0"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("Versions() = %v, want %v", versions, want)
+	}
+	if latest, _ := Get("demo"); latest.Version != "1.10.0" || len(Packs()) != 1 {
+		t.Errorf("Get() latest = %v, Packs() = %d", latest.Version, len(Packs()))
+	}
+	// pinned versions keep resolving after upgrade
+	sets := map[string]types.KVRuleSet{}
+	for _, set := range Sets() {
+		sets[set.Name] = set
+	}
+	for name, key := range map[string]string{"demo": "c", "demo@1.0.0": "a", "demo@1.9.0": "b"} {
+		rules, err := ruleset.Resolve(sets, name)
+		if err != nil || rules.DetectRules[0].KeyEqs[0] != key {
+			t.Errorf("Resolve(%q) = %v, %v, want key %q", name, rules.DetectRules, err, key)
+		}
+	}
+
+	for name, data := range map[string]string{
+		"packs/demo/1.0.yaml":    "name: demo\nversion: \"1.0\"\n",
+		"packs/other/1.0.0.yaml": "name: demo\nversion: 1.0.0\n",
+	} {
+		if _, err := loadAll(fstest.MapFS{name: {Data: []byte(data)}}); err == nil {
+			t.Errorf("loadAll() %s error = nil", name)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package ruleset
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+)
+
+/*
+rule sets of tenants, tenant is name of rule set
+processers are resolved & compiled on first Get and cached until the rule set or one of its bases changes
+*/
+type Registry struct {
+	mu    sync.RWMutex
+	sets  map[string]types.KVRuleSet
+	cache map[string]processer.KVProcesser
+}
+
+func NewRegistry(sets ...types.KVRuleSet) (*Registry, error) {
+	r := &Registry{}
+	if err := r.Replace(sets); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// processer of tenant, compiled once until rules change
+func (r *Registry) Get(tenant string) (processer.KVProcesser, error) {
+	r.mu.RLock()
+	m, ok := r.cache[tenant]
+	r.mu.RUnlock()
+	if ok {
+		return m, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// compiled by another caller while waiting
+	if m, ok := r.cache[tenant]; ok {
+		return m, nil
+	}
+	rules, err := Resolve(r.sets, tenant)
+	if err != nil {
+		return processer.KVProcesser{}, err
+	}
+	if m, err = processer.CompileKVProcesser(rules); err != nil {
+		return m, fmt.Errorf("rule set %q: %w", tenant, err)
+	}
+	r.cacheSet(tenant, m)
+	return m, nil
+}
+
+// resolved rules of tenant, e.g. for reports
+func (r *Registry) Rules(tenant string) (types.KVRules, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return Resolve(r.sets, tenant)
+}
+
+// names of rule sets in order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.sets))
+	for name := range r.sets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/*
+add or replace rule set, processers of it and rule sets extending it are invalidated
+rule set is compiled and rule sets extending it must still resolve, otherwise nothing is changed
+*/
+func (r *Registry) Set(set types.KVRuleSet) error {
+	if set.Name == "" {
+		return fmt.Errorf("rule set name is empty")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sets := make(map[string]types.KVRuleSet, len(r.sets)+1)
+	for name, s := range r.sets {
+		sets[name] = s
+	}
+	sets[set.Name] = set
+	affected := dependents(sets, set.Name)
+	for _, name := range affected {
+		if _, err := Resolve(sets, name); err != nil {
+			return err
+		}
+	}
+	rules, err := Resolve(sets, set.Name)
+	if err != nil {
+		return err
+	}
+	m, err := processer.CompileKVProcesser(rules)
+	if err != nil {
+		return fmt.Errorf("rule set %q: %w", set.Name, err)
+	}
+	r.sets = sets
+	for _, name := range affected {
+		delete(r.cache, name)
+	}
+	r.cacheSet(set.Name, m)
+	return nil
+}
+
+// delete rule set, rule sets extending it must be deleted or changed first
+func (r *Registry) Delete(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sets[name]; !ok {
+		return nil
+	}
+	if affected := dependents(r.sets, name); len(affected) > 0 {
+		return fmt.Errorf("rule set %q is extended by %q", name, affected[0])
+	}
+	delete(r.sets, name)
+	delete(r.cache, name)
+	return nil
+}
+
+// replace all rule sets, e.g. reloaded from files, all of them must resolve
+func (r *Registry) Replace(sets []types.KVRuleSet) error {
+	byName := make(map[string]types.KVRuleSet, len(sets))
+	for _, set := range sets {
+		if set.Name == "" {
+			return fmt.Errorf("rule set name is empty")
+		}
+		if _, ok := byName[set.Name]; ok {
+			return fmt.Errorf("rule set %q is defined twice", set.Name)
+		}
+		byName[set.Name] = set
+	}
+	for name := range byName {
+		if _, err := Resolve(byName, name); err != nil {
+			return err
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sets = byName
+	r.cache = map[string]processer.KVProcesser{}
+	return nil
+}
+
+// zero Registry is empty and usable
+func (r *Registry) cacheSet(name string, m processer.KVProcesser) {
+	if r.cache == nil {
+		r.cache = map[string]processer.KVProcesser{}
+	}
+	r.cache[name] = m
+}
+
+// names of rule sets extending name directly or indirectly, in order
+func dependents(sets map[string]types.KVRuleSet, name string) []string {
+	var result []string
+	seen := map[string]bool{name: true}
+	for queue := []string{name}; len(queue) > 0; queue = queue[1:] {
+		for n, set := range sets {
+			if set.Extends == queue[0] && !seen[n] {
+				seen[n] = true
+				result = append(result, n)
+				queue = append(queue, n)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
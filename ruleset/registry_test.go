@@ -0,0 +1,79 @@
+package ruleset
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+func maskString(t *testing.T, r *Registry, tenant, input string) string {
+	m, err := r.Get(tenant)
+	if err != nil {
+		t.Fatalf("Get(%q) error = %v", tenant, err)
+	}
+	output, _, err := m.Mask(context.Background(), []byte(input))
+	if err != nil {
+		t.Fatalf("Mask() error = %v", err)
+	}
+	return string(output)
+}
+
+func TestRegistry(t *testing.T) {
+	r, err := NewRegistry(baseline,
+		types.KVRuleSet{Name: "payments", Extends: "baseline", KVRules: types.KVRules{
+			DetectRules: []types.KVDetectConfig{{KeyEqs: []string{"pan"}}},
+		}},
+		types.KVRuleSet{Name: "support", Extends: "baseline", Disable: []string{"phone"}},
+	)
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	if got, want := r.Names(), []string{"baseline", "payments", "support"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+	input := `{"password":"ab","phone":"12","pan":"34","email":"a@b"}`
+	if got, want := maskString(t, r, "payments", input), `{"password":"**","phone":"**","pan":"**","email":"a@b"}`; got != want {
+		t.Errorf("payments = %s, want %s", got, want)
+	}
+	if got, want := maskString(t, r, "support", input), `{"password":"**","phone":"12","pan":"34","email":"a@b"}`; got != want {
+		t.Errorf("support = %s, want %s", got, want)
+	}
+
+	// changing baseline invalidates tenants extending it
+	changed := baseline
+	changed.DetectRules = append(append([]types.KVDetectConfig(nil), baseline.DetectRules...), types.KVDetectConfig{ID: "email", KeyEqs: []string{"email"}})
+	if err := r.Set(changed); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, want := maskString(t, r, "payments", input), `{"password":"**","phone":"**","pan":"**","email":"***"}`; got != want {
+		t.Errorf("payments after Set = %s, want %s", got, want)
+	}
+
+	// change breaking tenants extending it is rejected
+	broken := baseline
+	broken.DetectRules = baseline.DetectRules[:1]
+	if err := r.Set(broken); err == nil {
+		t.Errorf("Set() removing rule disabled by support error = nil")
+	}
+	if err := r.Set(types.KVRuleSet{Name: "baseline", Extends: "support"}); err == nil {
+		t.Errorf("Set() cycle error = nil")
+	}
+	if err := r.Set(types.KVRuleSet{Name: "invalid", KVRules: types.KVRules{DetectRules: []types.KVDetectConfig{{KeyRegex: []string{"("}}}}}); err == nil {
+		t.Errorf("Set() invalid regex error = nil")
+	}
+	if got, want := maskString(t, r, "support", input), `{"password":"**","phone":"12","pan":"34","email":"***"}`; got != want {
+		t.Errorf("support after rejected Set = %s, want %s", got, want)
+	}
+
+	if err := r.Delete("baseline"); err == nil {
+		t.Errorf("Delete() extended rule set error = nil")
+	}
+	if err := r.Delete("support"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := r.Get("support"); err == nil {
+		t.Errorf("Get() deleted error = nil")
+	}
+}
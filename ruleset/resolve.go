@@ -0,0 +1,137 @@
+// rule sets extending each other, resolved & compiled per tenant
+package ruleset
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+var ErrNotFound = errors.New("rule set not found")
+
+// resolve rule set by name with its bases, see types.KVRuleSet
+func Resolve(sets map[string]types.KVRuleSet, name string) (types.KVRules, error) {
+	return resolve(sets, name, nil)
+}
+
+// chain is names of rule sets extending name, for detecting cycles
+func resolve(sets map[string]types.KVRuleSet, name string, chain []string) (types.KVRules, error) {
+	for _, n := range chain {
+		if n == name {
+			return types.KVRules{}, fmt.Errorf("extends cycle %s -> %s", strings.Join(chain, " -> "), name)
+		}
+	}
+	set, ok := sets[name]
+	if !ok {
+		return types.KVRules{}, fmt.Errorf("%q: %w", name, ErrNotFound)
+	}
+	var base types.KVRules
+	if set.Extends != "" {
+		var err error
+		if base, err = resolve(sets, set.Extends, append(chain, name)); err != nil {
+			return types.KVRules{}, err
+		}
+	}
+	rules, err := merge(base, set)
+	if err != nil {
+		return rules, fmt.Errorf("rule set %q: %w", name, err)
+	}
+	return rules, nil
+}
+
+// rules of set on top of base, base is not changed
+func merge(base types.KVRules, set types.KVRuleSet) (types.KVRules, error) {
+	rules := types.KVRules{KeyNormalize: base.KeyNormalize, Decoders: base.Decoders}
+	if set.KeyNormalize != nil {
+		rules.KeyNormalize = set.KeyNormalize
+	}
+	if set.Decoders != nil {
+		rules.Decoders = set.Decoders
+	}
+
+	// detect rules: disable, override by ID, then append
+	disabled := make(map[string]bool, len(set.Disable))
+	for _, id := range set.Disable {
+		disabled[id] = false
+	}
+	for _, rule := range base.DetectRules {
+		if _, ok := disabled[rule.ID]; ok && rule.ID != "" {
+			disabled[rule.ID] = true
+			continue
+		}
+		rules.DetectRules = append(rules.DetectRules, rule)
+	}
+	for _, id := range set.Disable {
+		if !disabled[id] {
+			return rules, fmt.Errorf("Disable: no inherited detect rule %q", id)
+		}
+	}
+	own := map[string]struct{}{}
+	for idx, rule := range set.DetectRules {
+		if rule.ID == "" {
+			rules.DetectRules = append(rules.DetectRules, rule)
+			continue
+		}
+		if _, ok := own[rule.ID]; ok {
+			return rules, fmt.Errorf("detect rule %d: ID: %q is used twice", idx, rule.ID)
+		}
+		own[rule.ID] = struct{}{}
+		if at := detectIndex(rules.DetectRules, rule.ID); at >= 0 {
+			rules.DetectRules[at] = rule
+		} else {
+			rules.DetectRules = append(rules.DetectRules, rule)
+		}
+	}
+
+	// mask rules by name
+	rules.MaskRules = append([]types.KVMaskConfig(nil), base.MaskRules...)
+	for _, rule := range set.MaskRules {
+		if idx := maskIndex(rules.MaskRules, rule.RuleName); idx >= 0 {
+			rules.MaskRules[idx] = rule
+		} else {
+			rules.MaskRules = append(rules.MaskRules, rule)
+		}
+	}
+
+	// patterns by name
+	if len(base.Patterns)+len(set.Patterns) > 0 {
+		rules.Patterns = make(map[string]string, len(base.Patterns)+len(set.Patterns))
+		for k, v := range base.Patterns {
+			rules.Patterns[k] = v
+		}
+		for k, v := range set.Patterns {
+			rules.Patterns[k] = v
+		}
+	}
+	// dictionaries by name
+	if len(base.Dictionaries)+len(set.Dictionaries) > 0 {
+		rules.Dictionaries = make(map[string]types.KVDictionary, len(base.Dictionaries)+len(set.Dictionaries))
+		for k, v := range base.Dictionaries {
+			rules.Dictionaries[k] = v
+		}
+		for k, v := range set.Dictionaries {
+			rules.Dictionaries[k] = v
+		}
+	}
+	return rules, nil
+}
+
+func detectIndex(rules []types.KVDetectConfig, id string) int {
+	for idx, rule := range rules {
+		if rule.ID == id {
+			return idx
+		}
+	}
+	return -1
+}
+
+func maskIndex(rules []types.KVMaskConfig, name string) int {
+	for idx, rule := range rules {
+		if rule.RuleName == name {
+			return idx
+		}
+	}
+	return -1
+}
@@ -0,0 +1,129 @@
+package ruleset
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+var baseline = types.KVRuleSet{
+	Name: "baseline",
+	KVRules: types.KVRules{
+		DetectRules: []types.KVDetectConfig{
+			{ID: "password", KeyEqs: []string{"password"}},
+			{ID: "phone", KeyEqs: []string{"phone"}, MaskRef: "phone"},
+			{KeyEqs: []string{"secret"}},
+		},
+		MaskRules: []types.KVMaskConfig{
+			{RuleName: "phone", MaskType: types.MaskTypeCover},
+		},
+		Patterns: map[string]string{"card": `^\d{16}$`},
+		Decoders: []types.KVDecoder{types.KVDecoderJSON},
+	},
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		sets    []types.KVRuleSet
+		want    types.KVRules
+		wantErr bool
+	}{
+		{
+			name: "no base",
+			sets: []types.KVRuleSet{baseline},
+			want: baseline.KVRules,
+		},
+		{
+			name: "override, disable & add",
+			sets: []types.KVRuleSet{baseline, {
+				Name:    "payments",
+				Extends: "baseline",
+				Disable: []string{"phone"},
+				KVRules: types.KVRules{
+					DetectRules: []types.KVDetectConfig{
+						{KeyEqs: []string{"pan"}, MaskRef: "drop"},
+						{ID: "password", KeyEqs: []string{"password", "pin"}},
+					},
+					MaskRules: []types.KVMaskConfig{
+						{RuleName: "phone", MaskType: types.MaskTypePhone},
+						{RuleName: "drop", MaskType: types.MaskTypeDrop},
+					},
+					Patterns:     map[string]string{"iban": `^[A-Z]{2}\d+$`},
+					Decoders:     []types.KVDecoder{},
+					Dictionaries: map[string]types.KVDictionary{"employees": {File: "employees.txt", CaseFold: true}},
+				},
+			}},
+			want: types.KVRules{
+				DetectRules: []types.KVDetectConfig{
+					{ID: "password", KeyEqs: []string{"password", "pin"}},
+					{KeyEqs: []string{"secret"}},
+					{KeyEqs: []string{"pan"}, MaskRef: "drop"},
+				},
+				MaskRules: []types.KVMaskConfig{
+					{RuleName: "phone", MaskType: types.MaskTypePhone},
+					{RuleName: "drop", MaskType: types.MaskTypeDrop},
+				},
+				Patterns:     map[string]string{"card": `^\d{16}$`, "iban": `^[A-Z]{2}\d+$`},
+				Decoders:     []types.KVDecoder{},
+				Dictionaries: map[string]types.KVDictionary{"employees": {File: "employees.txt", CaseFold: true}},
+			},
+		},
+		{
+			name: "two levels",
+			sets: []types.KVRuleSet{baseline,
+				{Name: "eu", Extends: "baseline", Disable: []string{"password"}},
+				{Name: "eu-payments", Extends: "eu", KVRules: types.KVRules{DetectRules: []types.KVDetectConfig{{ID: "phone", KeyEqs: []string{"tel"}}}}},
+			},
+			want: types.KVRules{
+				DetectRules: []types.KVDetectConfig{
+					{ID: "phone", KeyEqs: []string{"tel"}},
+					{KeyEqs: []string{"secret"}},
+				},
+				MaskRules: baseline.MaskRules,
+				Patterns:  baseline.Patterns,
+				Decoders:  baseline.Decoders,
+			},
+		},
+		{
+			name:    "disable unknown ID",
+			sets:    []types.KVRuleSet{baseline, {Name: "payments", Extends: "baseline", Disable: []string{"email"}}},
+			wantErr: true,
+		},
+		{
+			name:    "missing base",
+			sets:    []types.KVRuleSet{{Name: "payments", Extends: "baseline"}},
+			wantErr: true,
+		},
+		{
+			name:    "cycle",
+			sets:    []types.KVRuleSet{{Name: "a", Extends: "payments"}, {Name: "payments", Extends: "a"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sets := map[string]types.KVRuleSet{}
+			for _, set := range tt.sets {
+				sets[set.Name] = set
+			}
+			name := tt.sets[len(tt.sets)-1].Name
+			got, err := Resolve(sets, name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resolve() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	// base is not changed by sets extending it
+	if !reflect.DeepEqual(baseline.DetectRules[0].KeyEqs, []string{"password"}) {
+		t.Errorf("Resolve() changed base: %+v", baseline.DetectRules)
+	}
+	if _, err := Resolve(map[string]types.KVRuleSet{}, "x"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Resolve() missing error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,162 @@
+package serverless
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+)
+
+// proxy request of API gateway, REST API payload format 1.0
+type APIGatewayProxyRequest struct {
+	Resource                        string              `json:"resource"`
+	Path                            string              `json:"path"`
+	HTTPMethod                      string              `json:"httpMethod"`
+	Headers                         map[string]string   `json:"headers"`
+	MultiValueHeaders               map[string][]string `json:"multiValueHeaders"`
+	QueryStringParameters           map[string]string   `json:"queryStringParameters"`
+	MultiValueQueryStringParameters map[string][]string `json:"multiValueQueryStringParameters"`
+	PathParameters                  map[string]string   `json:"pathParameters"`
+	StageVariables                  map[string]string   `json:"stageVariables"`
+	RequestContext                  json.RawMessage     `json:"requestContext,omitempty"`
+	Body                            string              `json:"body"`
+	IsBase64Encoded                 bool                `json:"isBase64Encoded"`
+}
+
+type APIGatewayProxyResponse struct {
+	StatusCode        int                 `json:"statusCode"`
+	Headers           map[string]string   `json:"headers,omitempty"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders,omitempty"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded,omitempty"`
+}
+
+type ProxyHandler func(ctx context.Context, req APIGatewayProxyRequest) (APIGatewayProxyResponse, error)
+
+type Direction string
+
+const (
+	DirectionRequest  Direction = "request"
+	DirectionResponse Direction = "response"
+)
+
+type ProxyConfig struct {
+	MaskRequest bool // mask JSON request body before it reaches handler
+	// called with detected pairs of each masked body, for auditing
+	OnDetect func(ctx context.Context, direction Direction, pairs []types.KVPair)
+}
+
+/*
+handler of masking service, responds JSON request body masked
+invalid JSON is rejected by 400 with {"error": "..."}
+*/
+func MaskProxyHandler(m processer.KVProcesser) ProxyHandler {
+	return func(ctx context.Context, req APIGatewayProxyRequest) (APIGatewayProxyResponse, error) {
+		body, err := req.body()
+		if err == nil {
+			body, _, err = maskPayload(ctx, m, body)
+		}
+		if err != nil {
+			return errorResponse(http.StatusBadRequest, err), nil
+		}
+		return APIGatewayProxyResponse{
+			StatusCode: http.StatusOK,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       string(body),
+		}, nil
+	}
+}
+
+/*
+wrap handler, JSON & NDJSON response bodies are masked, and request bodies if MaskRequest
+fails closed: invalid request body is rejected by 400, response failed to mask is replaced by 500
+*/
+func WrapProxy(m processer.KVProcesser, next ProxyHandler, config ProxyConfig) ProxyHandler {
+	return func(ctx context.Context, req APIGatewayProxyRequest) (APIGatewayProxyResponse, error) {
+		if config.MaskRequest && isJSON(header(req.Headers, req.MultiValueHeaders, "Content-Type")) {
+			body, err := req.body()
+			var pairs []types.KVPair
+			if err == nil {
+				body, pairs, err = maskPayload(ctx, m, body)
+			}
+			if err != nil {
+				return errorResponse(http.StatusBadRequest, fmt.Errorf("mask request: %w", err)), nil
+			}
+			config.onDetect(ctx, DirectionRequest, pairs)
+			req.Body, req.IsBase64Encoded = string(body), false
+		}
+		resp, err := next(ctx, req)
+		if err != nil || !isJSON(header(resp.Headers, resp.MultiValueHeaders, "Content-Type")) {
+			return resp, err
+		}
+		body := []byte(resp.Body)
+		if resp.IsBase64Encoded {
+			if body, err = base64.StdEncoding.DecodeString(resp.Body); err != nil {
+				return errorResponse(http.StatusInternalServerError, fmt.Errorf("mask response failed")), nil
+			}
+		}
+		masked, pairs, err := maskPayload(ctx, m, body)
+		if err != nil {
+			return errorResponse(http.StatusInternalServerError, fmt.Errorf("mask response failed")), nil
+		}
+		config.onDetect(ctx, DirectionResponse, pairs)
+		resp.Body, resp.IsBase64Encoded = string(masked), false
+		return resp, nil
+	}
+}
+
+func (c ProxyConfig) onDetect(ctx context.Context, direction Direction, pairs []types.KVPair) {
+	if c.OnDetect != nil && len(pairs) > 0 {
+		c.OnDetect(ctx, direction, pairs)
+	}
+}
+
+func (req APIGatewayProxyRequest) body() ([]byte, error) {
+	if req.IsBase64Encoded {
+		return base64.StdEncoding.DecodeString(req.Body)
+	}
+	return []byte(req.Body), nil
+}
+
+// header by case-insensitive name, single value headers first
+func header(headers map[string]string, multiValueHeaders map[string][]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	for k, v := range multiValueHeaders {
+		if strings.EqualFold(k, name) && len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// JSON, NDJSON or +json media types
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "application/json", "application/x-ndjson", "application/jsonl":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+json")
+}
+
+func errorResponse(status int, err error) APIGatewayProxyResponse {
+	body, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return APIGatewayProxyResponse{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(body),
+	}
+}
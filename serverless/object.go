@@ -0,0 +1,86 @@
+package serverless
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+)
+
+/*
+object storage events with payload of object inline, e.g. S3 event notifications
+forwarded with content by a small object or an upstream function
+*/
+type ObjectEvent struct {
+	Records []ObjectRecord `json:"Records"`
+}
+
+type ObjectRecord struct {
+	EventName       string       `json:"eventName"`
+	EventSource     string       `json:"eventSource"`
+	S3              ObjectEntity `json:"s3"`
+	Payload         string       `json:"payload"`
+	PayloadEncoding string       `json:"payloadEncoding"` // empty for text, or base64
+}
+
+type ObjectEntity struct {
+	Bucket ObjectBucket `json:"bucket"`
+	Object ObjectInfo   `json:"object"`
+}
+
+type ObjectBucket struct {
+	Name string `json:"name"`
+	ARN  string `json:"arn"`
+}
+
+type ObjectInfo struct {
+	Key       string `json:"key"`
+	Size      int64  `json:"size"`
+	ETag      string `json:"eTag"`
+	VersionID string `json:"versionId"`
+}
+
+// object of event with payload masked, JSON document or NDJSON
+type Object struct {
+	Bucket string
+	Key    string
+	Data   []byte // masked payload
+	Pairs  []types.KVPair
+}
+
+type ObjectHandlerFunc func(ctx context.Context, object Object) error
+
+/*
+handler of object events, payload of each record is masked and passed to next
+object events have no partial response, so the event fails with the first failed record
+*/
+func ObjectHandler(m processer.KVProcesser, next ObjectHandlerFunc) func(ctx context.Context, event ObjectEvent) error {
+	return func(ctx context.Context, event ObjectEvent) error {
+		for _, record := range event.Records {
+			object := Object{Bucket: record.S3.Bucket.Name, Key: record.S3.Object.Key}
+			data, err := record.payload()
+			if err == nil {
+				object.Data, object.Pairs, err = maskPayload(ctx, m, data)
+			}
+			if err != nil {
+				return fmt.Errorf("mask object %s/%s: %w", object.Bucket, object.Key, err)
+			}
+			if err := next(ctx, object); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func (r ObjectRecord) payload() ([]byte, error) {
+	switch r.PayloadEncoding {
+	case "":
+		return []byte(r.Payload), nil
+	case "base64":
+		return base64.StdEncoding.DecodeString(r.Payload)
+	}
+	return nil, fmt.Errorf("unsupported payload encoding %q", r.PayloadEncoding)
+}
@@ -0,0 +1,105 @@
+package serverless
+
+import (
+	"context"
+	"fmt"
+
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+)
+
+// batch of queue messages, e.g. SQS
+type QueueEvent struct {
+	Records []QueueMessage `json:"Records"`
+}
+
+type QueueMessage struct {
+	MessageID      string            `json:"messageId"`
+	ReceiptHandle  string            `json:"receiptHandle"`
+	Body           string            `json:"body"`
+	Attributes     map[string]string `json:"attributes"`
+	MD5OfBody      string            `json:"md5OfBody"`
+	EventSource    string            `json:"eventSource"`
+	EventSourceARN string            `json:"eventSourceARN"`
+	AWSRegion      string            `json:"awsRegion"`
+}
+
+// batch of stream records, e.g. Kinesis, data is base64 in JSON
+type StreamEvent struct {
+	Records []StreamRecord `json:"Records"`
+}
+
+type StreamRecord struct {
+	EventID        string     `json:"eventID"`
+	EventName      string     `json:"eventName"`
+	EventSource    string     `json:"eventSource"`
+	EventSourceARN string     `json:"eventSourceARN"`
+	Kinesis        StreamData `json:"kinesis"`
+}
+
+type StreamData struct {
+	PartitionKey   string `json:"partitionKey"`
+	SequenceNumber string `json:"sequenceNumber"`
+	Data           []byte `json:"data"`
+}
+
+// record of batch with payload masked
+type Record struct {
+	ID    string // message id of queue, sequence number of stream
+	Data  []byte // masked payload
+	Pairs []types.KVPair
+}
+
+type RecordHandler func(ctx context.Context, record Record) error
+
+// partial batch response, failed items are retried by platform
+type BatchResponse struct {
+	BatchItemFailures []BatchItemFailure `json:"batchItemFailures"`
+}
+
+type BatchItemFailure struct {
+	ItemIdentifier string `json:"itemIdentifier"`
+}
+
+/*
+handler of queue batches, body of each message is masked and passed to next
+fails closed: message failed to mask or handle is reported as failure and never reaches next unmasked
+*/
+func QueueHandler(m processer.KVProcesser, next RecordHandler) func(ctx context.Context, event QueueEvent) (BatchResponse, error) {
+	return func(ctx context.Context, event QueueEvent) (BatchResponse, error) {
+		resp := BatchResponse{BatchItemFailures: []BatchItemFailure{}}
+		for _, msg := range event.Records {
+			if err := handleRecord(ctx, m, next, msg.MessageID, []byte(msg.Body)); err != nil {
+				resp.BatchItemFailures = append(resp.BatchItemFailures, BatchItemFailure{ItemIdentifier: msg.MessageID})
+			}
+		}
+		return resp, nil
+	}
+}
+
+/*
+handler of stream batches, data of each record is masked and passed to next in order
+records of a shard are processed in order, so handling stops at the first failure,
+which is reported by sequence number and retried with records after it
+*/
+func StreamHandler(m processer.KVProcesser, next RecordHandler) func(ctx context.Context, event StreamEvent) (BatchResponse, error) {
+	return func(ctx context.Context, event StreamEvent) (BatchResponse, error) {
+		resp := BatchResponse{BatchItemFailures: []BatchItemFailure{}}
+		for _, record := range event.Records {
+			id := record.Kinesis.SequenceNumber
+			if err := handleRecord(ctx, m, next, id, record.Kinesis.Data); err != nil {
+				resp.BatchItemFailures = append(resp.BatchItemFailures, BatchItemFailure{ItemIdentifier: id})
+				break
+			}
+		}
+		return resp, nil
+	}
+}
+
+func handleRecord(ctx context.Context, m processer.KVProcesser, next RecordHandler, id string, data []byte) error {
+	masked, pairs, err := maskPayload(ctx, m, data)
+	if err != nil {
+		return fmt.Errorf("mask record %s: %w", id, err)
+	}
+	return next(ctx, Record{ID: id, Data: masked, Pairs: pairs})
+}
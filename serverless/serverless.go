@@ -0,0 +1,72 @@
+/*
+adapters of function platforms: API gateway proxy, queue & stream batches and object storage events
+event types follow JSON of common platforms (e.g. AWS Lambda events) without depending on their SDKs,
+so handlers can be registered to runtimes directly, e.g. lambda.Start(serverless.QueueHandler(m, next))
+*/
+package serverless
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+
+	"github.com/senayuki/mosaic/pkg/rulefile"
+	processer "github.com/senayuki/mosaic/processor"
+	"github.com/senayuki/mosaic/types"
+)
+
+/*
+load rules file in YAML or JSON, called once per cold start in package scope:
+
+	//go:embed rules.yaml
+	var rulesFS embed.FS
+	var m = serverless.MustLoadRules(rulesFS, "rules.yaml")
+
+bundled file is loaded by os.DirFS, e.g. os.DirFS(os.Getenv("LAMBDA_TASK_ROOT"))
+*/
+func LoadRules(fsys fs.FS, name string) (processer.KVProcesser, error) {
+	_, m, err := rulefile.Load(fsys, name)
+	return m, err
+}
+
+// LoadRules or panic, function fails to start with invalid rules
+func MustLoadRules(fsys fs.FS, name string) processer.KVProcesser {
+	m, err := LoadRules(fsys, name)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// mask JSON document, or NDJSON line by line if payload is not a JSON document
+func maskPayload(ctx context.Context, m processer.KVProcesser, data []byte) ([]byte, []types.KVPair, error) {
+	if json.Valid(data) {
+		masked, pairs, err := m.Mask(ctx, data)
+		if err != nil || len(pairs) == 0 {
+			return data, nil, err
+		}
+		return masked, pairs, nil
+	}
+	var out bytes.Buffer
+	var pairs []types.KVPair
+	for idx, line := range bytes.SplitAfter(data, []byte("\n")) {
+		content := bytes.TrimRight(line, "\r\n")
+		if len(bytes.TrimSpace(content)) == 0 {
+			out.Write(line)
+			continue
+		}
+		masked, linePairs, err := m.Mask(ctx, content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", idx+1, err)
+		}
+		out.Write(masked)
+		out.Write(line[len(content):])
+		pairs = append(pairs, linePairs...)
+	}
+	if len(pairs) == 0 {
+		return data, nil, nil
+	}
+	return out.Bytes(), pairs, nil
+}
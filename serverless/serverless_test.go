@@ -0,0 +1,190 @@
+package serverless
+
+import (
+	"context"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/senayuki/mosaic/types"
+)
+
+//go:embed testdata/rules.yaml
+var rulesFS embed.FS
+
+var m = MustLoadRules(rulesFS, "testdata/rules.yaml")
+
+func loadEvent(t *testing.T, name string, event interface{}) {
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+	if err := json.Unmarshal(data, event); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	if _, err := LoadRules(fstest.MapFS{}, "rules.yaml"); err == nil {
+		t.Errorf("LoadRules() missing file error = nil")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustLoadRules() invalid rules did not panic")
+		}
+	}()
+	MustLoadRules(fstest.MapFS{"rules.yaml": {Data: []byte("detectRules:\n  - valRegex: ['(']\n")}}, "rules.yaml")
+}
+
+func TestMaskProxyHandler(t *testing.T) {
+	var req APIGatewayProxyRequest
+	loadEvent(t, "apigateway.json", &req)
+	h := MaskProxyHandler(m)
+	resp, err := h(context.Background(), req)
+	if err != nil {
+		t.Fatalf("MaskProxyHandler() error = %v", err)
+	}
+	if resp.StatusCode != 200 || resp.Body != `{"user":"a","password":"***"}` {
+		t.Errorf("MaskProxyHandler() = %+v", resp)
+	}
+
+	req.Body, req.IsBase64Encoded = "{", false
+	resp, _ = h(context.Background(), req)
+	if resp.StatusCode != 400 {
+		t.Errorf("MaskProxyHandler() invalid body status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestWrapProxy(t *testing.T) {
+	var req APIGatewayProxyRequest
+	loadEvent(t, "apigateway.json", &req)
+	var detected []Direction
+	config := ProxyConfig{
+		MaskRequest: true,
+		OnDetect: func(ctx context.Context, direction Direction, pairs []types.KVPair) {
+			detected = append(detected, direction)
+		},
+	}
+	var received string
+	h := WrapProxy(m, func(ctx context.Context, req APIGatewayProxyRequest) (APIGatewayProxyResponse, error) {
+		received = req.Body
+		return APIGatewayProxyResponse{
+			StatusCode:      200,
+			Headers:         map[string]string{"Content-Type": "application/json; charset=utf-8"},
+			Body:            base64.StdEncoding.EncodeToString([]byte(`{"phone":"123","token":"t"}`)),
+			IsBase64Encoded: true,
+		}, nil
+	}, config)
+	resp, err := h(context.Background(), req)
+	if err != nil {
+		t.Fatalf("WrapProxy() error = %v", err)
+	}
+	if received != `{"user":"a","password":"***"}` {
+		t.Errorf("WrapProxy() request body = %s", received)
+	}
+	if resp.Body != `{"phone":"***"}` || resp.IsBase64Encoded {
+		t.Errorf("WrapProxy() response = %+v", resp)
+	}
+	if !reflect.DeepEqual(detected, []Direction{DirectionRequest, DirectionResponse}) {
+		t.Errorf("WrapProxy() detected = %v", detected)
+	}
+
+	// responses failed to mask are not sent
+	h = WrapProxy(m, func(ctx context.Context, req APIGatewayProxyRequest) (APIGatewayProxyResponse, error) {
+		return APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       "password=abc",
+		}, nil
+	}, ProxyConfig{})
+	resp, _ = h(context.Background(), req)
+	if resp.StatusCode != 500 || resp.Body == "password=abc" {
+		t.Errorf("WrapProxy() unmasked response = %+v", resp)
+	}
+}
+
+func TestQueueHandler(t *testing.T) {
+	var event QueueEvent
+	loadEvent(t, "sqs.json", &event)
+	got := map[string]string{}
+	h := QueueHandler(m, func(ctx context.Context, record Record) error {
+		got[record.ID] = string(record.Data)
+		if record.ID == "2e1424d4-f796-459a-8184-9c92662be6da" {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+	resp, err := h(context.Background(), event)
+	if err != nil {
+		t.Fatalf("QueueHandler() error = %v", err)
+	}
+	want := map[string]string{
+		"059f36b4-87a3-44ab-83d2-661975830a7d": `{"user":"a","password":"***"}`,
+		"2e1424d4-f796-459a-8184-9c92662be6da": "{\"phone\":\"***\"}\n{\"user\":\"b\"}\n",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("QueueHandler() records = %v, want %v", got, want)
+	}
+	wantFailures := []BatchItemFailure{
+		{ItemIdentifier: "2e1424d4-f796-459a-8184-9c92662be6da"},
+		{ItemIdentifier: "a7f1c9d2-3b1e-4c5f-9a8b-0d6e2f4a1b3c"},
+	}
+	if !reflect.DeepEqual(resp.BatchItemFailures, wantFailures) {
+		t.Errorf("QueueHandler() failures = %v, want %v", resp.BatchItemFailures, wantFailures)
+	}
+}
+
+func TestStreamHandler(t *testing.T) {
+	var event StreamEvent
+	loadEvent(t, "kinesis.json", &event)
+	var got []string
+	h := StreamHandler(m, func(ctx context.Context, record Record) error {
+		got = append(got, string(record.Data))
+		if len(record.Pairs) != 2 {
+			t.Errorf("StreamHandler() pairs = %+v", record.Pairs)
+		}
+		return nil
+	})
+	resp, err := h(context.Background(), event)
+	if err != nil {
+		t.Fatalf("StreamHandler() error = %v", err)
+	}
+	if want := []string{`{"phone":"***"}`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("StreamHandler() records = %v, want %v", got, want)
+	}
+	// records after the first failure are retried
+	wantFailures := []BatchItemFailure{{ItemIdentifier: "49590338271490256608559692540925702759324208523137515618"}}
+	if !reflect.DeepEqual(resp.BatchItemFailures, wantFailures) {
+		t.Errorf("StreamHandler() failures = %v, want %v", resp.BatchItemFailures, wantFailures)
+	}
+}
+
+func TestObjectHandler(t *testing.T) {
+	var event ObjectEvent
+	loadEvent(t, "object.json", &event)
+	got := map[string]string{}
+	h := ObjectHandler(m, func(ctx context.Context, object Object) error {
+		got[object.Bucket+"/"+object.Key] = string(object.Data)
+		return nil
+	})
+	if err := h(context.Background(), event); err != nil {
+		t.Fatalf("ObjectHandler() error = %v", err)
+	}
+	want := map[string]string{
+		"logs/2026/10/19/app.ndjson": "{\"user\":\"a\",\"password\":\"***\"}\n{\"user\":\"b\"}\n",
+		"logs/2026/10/19/user.json":  `{"phone":"***"}`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ObjectHandler() objects = %v, want %v", got, want)
+	}
+
+	event.Records[0].PayloadEncoding = "gzip"
+	if err := h(context.Background(), event); err == nil {
+		t.Errorf("ObjectHandler() unsupported encoding error = nil")
+	}
+}
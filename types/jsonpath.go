@@ -10,6 +10,10 @@ type JSONPath struct {
 	path []interface{}
 }
 
+// payload decoded from string value, e.g. JSON in string or JWT claims
+// marshaled as {"decode": "jwt"}
+type JSONPathDecode KVDecoder
+
 func NewJSONPath() JSONPath {
 	return JSONPath{path: []interface{}{}}
 }
@@ -19,6 +23,12 @@ func (j JSONPath) Append(key ...interface{}) JSONPath {
 	newPath = append(newPath, key...)
 	return JSONPath{path: newPath}
 }
+
+// elements of path, string for object key and int for array index
+func (j JSONPath) Elements() []interface{} {
+	return j.path
+}
+
 func (j JSONPath) String() string {
 	return strings.Join(j.ToStrings(), "->")
 }
@@ -28,8 +38,27 @@ func (j JSONPath) MarshalJSON() ([]byte, error) {
 }
 
 func (j *JSONPath) UnmarshalJSON(data []byte) error {
-	err := json.Unmarshal(data, &j.path)
-	return err
+	var path []interface{}
+	if err := json.Unmarshal(data, &path); err != nil {
+		return err
+	}
+	j.path = make([]interface{}, 0, len(path))
+	for _, elem := range path {
+		switch val := elem.(type) {
+		case float64:
+			j.path = append(j.path, int(val))
+		case map[string]interface{}:
+			decode, _ := val["decode"].(string)
+			j.path = append(j.path, JSONPathDecode(decode))
+		default:
+			j.path = append(j.path, val)
+		}
+	}
+	return nil
+}
+
+func (d JSONPathDecode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"decode": string(d)})
 }
 
 func (j JSONPath) ToStrings() []string {
@@ -40,6 +69,8 @@ func (j JSONPath) ToStrings() []string {
 			result = append(result, val)
 		case int:
 			result = append(result, strconv.Itoa(val))
+		case JSONPathDecode:
+			result = append(result, "<"+string(val)+">")
 		}
 	}
 	return result
@@ -2,6 +2,16 @@ package types
 
 type (
 	KVDetectConfig struct {
+		/*metadata passed through to detected pairs by KVPair.Rule, all optional
+		ID is unique in rules, referenced by rule sets, reports & metrics regardless of order of rules
+		*/
+		ID          string
+		Name        string
+		Description string
+		Severity    KVSeverity
+		Category    KVCategory
+		Tags        []string
+		Owner       string      // team or person responsible for rule
 		KeyEqs      []string    // key absolutely equal an element in array
 		ValEqs      []string    // val absolutely equal an element in array
 		KeyContains []string    // key contains an element in array
@@ -9,20 +19,81 @@ type (
 		KeyRegex    []string    // keys matched an regex
 		ValRegex    []string    // vals matched an regex
 		MatchMode   KVMatchMode // (key || val) matched or (key && val) matched
-		ValueMode   KVMaskMode  // mask whole value or matched segments
-		MaskRef     string      // reference mask processes
+		/*vals matched by dictionary named in KVRules.Dictionaries, e.g. employee names,
+		matched like ValEqs or ValContains by match of the dictionary, and in the same way by MatchMode, not allowed with Expr
+		*/
+		ValDictionary string
+		/*normalize keys before matching, KVRules.KeyNormalize is used if empty
+		criteria of key are normalized in the same way, regex is matched with normalized key
+		*/
+		KeyNormalize []KVKeyNormalize
+		/*boolean expression over criteria, replaces criteria above & MatchMode if set
+		(keyContains("card") AND valRegex(luhn16)) OR keyEq("pan")
+		keyContains("id") AND NOT keyEq("request_id")
+		criteria: keyEq, valEq, keyContains, valContains, keyRegex, valRegex
+		operators: AND(&&), OR(||), NOT(!), parentheses
+		identifier as argument references a pattern in KVRules.Patterns
+		*/
+		Expr      string
+		ValTypes  []KVValType // allowed JSON types of value, any type is allowed if empty
+		ValMinLen int         // min length of value in runes, unlimited if 0
+		ValMaxLen int         // max length of value in runes, unlimited if 0
+		/*numeric range in interval notation, only number values in range are matched
+		"[1e10, 1e11)" means 1e10 <= val < 1e11
+		"(0,]" means val > 0, bound is unlimited if empty
+		*/
+		ValRange string
+		/*check digit of value, spaces & dashes are ignored, values failing the check are not matched
+		"luhn" for card numbers, "cnid" for Chinese resident ID numbers (GB 11643),
+		"dni" for Spanish DNI & NIE (mod 23), "codice-fiscale" for Italian codice fiscale
+		*/
+		ValChecksum KVChecksum
+		ValueMode   KVMaskMode // mask whole value or matched segments
+		MaskRef     string     // reference mask processes
 		/*treat specified field as key-value pair
 		{
 			"name": "as key, val must be string",
-			"content": "as value, value can be any type exclude object"
-		} */
+			"content": "as value, value can be any type"
+		}
+		header-style arrays are supported as well
+		[{"name": "Authorization", "value": "..."}]
+		if value is an object or array, all of its members are treated as the value of the key
+		*/
 		KVFieldOpt *KVField
+		/*match normal key-value pairs too when KVFieldOpt is set
+		only pairs extracted by KVFieldOpt are matched by default
+		*/
+		KVFieldPlain bool
+		/*check object keys against criteria as values, e.g. {"alice@example.com": {...}}
+		key of the object is treated as key, matched keys are rewritten in masked output
+		if masked keys collide with other keys of the object, "~1", "~2"... is appended in order of the object
+		*/
+		MatchObjectKey bool
 	}
 	KVMatchMode string // (key || val) matched or (key && val) matched
-	KVMaskMode  string // mask whole value or matched value
-	KVField     struct {
-		Key string
-		Val string
+	KVValType   string // JSON type of value
+	/*normalization of key, applied in order of nfkc, words, lower regardless of declared order
+	"phoneNumber", "phone_number", "PHONE-NUMBER" are all normalized to "phone_number" by words & lower
+	*/
+	KVKeyNormalize string
+	KVMaskMode     string // mask whole value or matched value
+	KVChecksum     string // check digit algorithm of value
+	KVSeverity     string // severity of data detected by rule
+	KVCategory     string // category of data detected by rule
+	// metadata of detect rule, see KVDetectConfig
+	KVRuleMeta struct {
+		ID          string
+		Name        string
+		Description string
+		Severity    KVSeverity
+		Category    KVCategory
+		Tags        []string
+		Owner       string
+	}
+	KVField struct {
+		Key  string   // field treated as key
+		Val  string   // field treated as value
+		Vals []string // more fields treated as value, e.g. "values", "content"
 	}
 )
 
@@ -31,8 +102,69 @@ const (
 	KVMatchOr      KVMatchMode = "or"  // key or val matched
 	KVMatchAnd     KVMatchMode = "and" // key and val matched
 
+	KVKeyNormalizeLower KVKeyNormalize = "lower" // lowercase
+	KVKeyNormalizeWords KVKeyNormalize = "words" // split camelCase, snake_case, kebab-case and rejoin by "_"
+	KVKeyNormalizeNFKC  KVKeyNormalize = "nfkc"  // unicode NFKC & width folding, full-width keys are folded to half-width
+
+	KVValTypeString  KVValType = "string"
+	KVValTypeNumber  KVValType = "number"
+	KVValTypeInteger KVValType = "integer" // number without fractional part
+	KVValTypeBool    KVValType = "bool"
+	KVValTypeNull    KVValType = "null"
+
+	KVChecksumLuhn KVChecksum = "luhn"
+	KVChecksumCNID KVChecksum = "cnid"
+	KVChecksumDNI  KVChecksum = "dni"
+	KVChecksumCF   KVChecksum = "codice-fiscale"
+
+	KVSeverityLow      KVSeverity = "low"
+	KVSeverityMedium   KVSeverity = "medium"
+	KVSeverityHigh     KVSeverity = "high"
+	KVSeverityCritical KVSeverity = "critical"
+
+	KVCategoryPII        KVCategory = "pii"        // personally identifiable information
+	KVCategoryPCI        KVCategory = "pci"        // payment card data
+	KVCategoryPHI        KVCategory = "phi"        // protected health information
+	KVCategoryCredential KVCategory = "credential" // passwords, tokens & keys
+
 	// TODO: mask mode support
 	KVMaskModeDefault KVMaskMode = ""        // "whole" is default mode
 	KVMaskModeWhole   KVMaskMode = "whole"   // whole value
 	KVMaskModeSegment KVMaskMode = "segment" // matched segments in value
 )
+
+// metadata of rule, nil if rule has none
+func (c *KVDetectConfig) Meta() *KVRuleMeta {
+	meta := KVRuleMeta{ID: c.ID, Name: c.Name, Description: c.Description, Severity: c.Severity, Category: c.Category, Tags: c.Tags, Owner: c.Owner}
+	if meta.ID == "" && meta.Name == "" && meta.Description == "" && meta.Severity == "" && meta.Category == "" && len(meta.Tags) == 0 && meta.Owner == "" {
+		return nil
+	}
+	return &meta
+}
+
+// all fields treated as value, without empty or duplicated field
+func (f KVField) ValFields() []string {
+	fields := make([]string, 0, len(f.Vals)+1)
+	seen := map[string]struct{}{}
+	for _, field := range append([]string{f.Val}, f.Vals...) {
+		if _, ok := seen[field]; ok || field == "" {
+			continue
+		}
+		seen[field] = struct{}{}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// whether pair extracted by rel is declared by the k-v fields, compared by value
+func (f KVField) Has(rel KVField) bool {
+	if f.Key != rel.Key {
+		return false
+	}
+	for _, field := range f.ValFields() {
+		if field == rel.Val {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,19 @@ type KVMaskConfig struct {
 	RuleName   string
 	MaskType   MaskType
 	CoverParam MaskRuleCoverParam
+	ConstParam MaskRuleConstParam
+	DropParam  MaskRuleDropParam
+	EmailParam MaskRuleEmailParam
+	PhoneParam MaskRulePhoneParam
+	CardParam  MaskRuleCardParam
+	IPParam    MaskRuleIPParam
+	NameParam  MaskRuleNameParam
+	DateParam  MaskRuleDateParam
+	AgeParam   MaskRuleAgeParam
+	RoundParam MaskRuleRoundParam
+	GeoParam   MaskRuleGeoParam
+	PostParam  MaskRulePostcodeParam
+	FakeParam  MaskRuleFakeParam
 }
 
 type (
@@ -11,9 +24,35 @@ type (
 )
 
 const (
-	MaskTypeCover MaskType = "cover"
+	MaskTypeCover   MaskType = "cover"
+	MaskTypeDrop    MaskType = "drop"    // remove field from parent object or element from array
+	MaskTypeNull    MaskType = "null"    // set to null
+	MaskTypeConst   MaskType = "const"   // replace with a constant value, e.g. "[REDACTED]"
+	MaskTypeDefault MaskType = "default" // replace with default value of the same type, "", 0, false or null
+	MaskTypeEmail   MaskType = "email"   // a****@example.com
+	MaskTypePhone   MaskType = "phone"   // +86 138****8000
+	MaskTypeCard    MaskType = "card"    // 411111******1111
+	MaskTypeIP      MaskType = "ip"      // 192.168.1.0, 2001:db8:1::
+	MaskTypeName    MaskType = "name"    // 张*, J*** S****
+
+	// generalization, value is written back in the same JSON type
+	MaskTypeDate     MaskType = "date"     // 1990-05-17 -> 1990-05-01
+	MaskTypeAgeBand  MaskType = "ageband"  // birthdate 1990-05-17 -> "30-39", age 34 -> 30
+	MaskTypeRound    MaskType = "round"    // 12345.67 -> 12000
+	MaskTypeGeo      MaskType = "geo"      // 31.230416 -> 31.23, "31.230416,121.473701" -> "31.23,121.47"
+	MaskTypePostcode MaskType = "postcode" // 100080 -> 100
+
+	// realistic fake value of the same kind, e.g. 张三 -> 李伟
+	MaskTypeFake MaskType = "fake"
 )
 
+// masked value of dropped field
+type KVDropped struct{}
+
+func (KVDropped) String() string {
+	return "<dropped>"
+}
+
 type MaskRuleCoverParam struct {
 	/*cover by char，*，0，# etc.
 	* is default value
@@ -39,4 +78,126 @@ type MaskRuleCoverParam struct {
 	Length int
 	/*cover string start at the tail*/
 	Reverse bool
+	/*only digits are covered, other chars are kept and not counted by offset & padding
+	length is ignored to keep count of digits
+	useful to mask numbers while keeping a number, e.g. "3.14159" -> "3.00000" with Char "0" & Offset 1
+	*/
+	DigitsOnly bool
+}
+
+type MaskRuleConstParam struct {
+	/*constant value in any JSON type, "[REDACTED]" by default*/
+	Value interface{}
+}
+
+type MaskRuleDropParam struct {
+	/*elements dropped from array are left as null, instead of shifting the following elements*/
+	KeepArrayIndex bool
+}
+
+type MaskRuleEmailParam struct {
+	/*cover by char, * is default value*/
+	Char string
+	/*chars kept at the head of local part, 1 by default, negative means none*/
+	KeepLocal int
+	/*length of covered local part, same as content if 0, hides the real length if set*/
+	Length int
+	/*cover domain except top-level domain as well, a****@*******.com*/
+	CoverDomain bool
+}
+
+type MaskRulePhoneParam struct {
+	/*cover by char, * is default value*/
+	Char string
+	/*digits kept at the tail, 4 by default, negative means none*/
+	KeepLast int
+	/*cover country code after "+" as well, country code is kept by default*/
+	CoverCountryCode bool
+}
+
+type MaskRuleCardParam struct {
+	/*cover by char, * is default value*/
+	Char string
+	/*length of BIN kept at the head, 6 by default, 8 for 8-digit BIN
+	BIN is covered as well if card has less than 13 digits
+	last 4 digits are kept as PCI DSS allowed, all digits are covered if card has no more than 4 digits
+	*/
+	BINLength int
+}
+
+type MaskRuleIPParam struct {
+	/*prefix length of IPv4 kept, 24 by default, host bits are set to 0*/
+	IPv4Prefix int
+	/*prefix length of IPv6 kept, 48 by default, host bits are set to 0*/
+	IPv6Prefix int
+}
+
+type MaskRuleNameParam struct {
+	/*cover by char, * is default value*/
+	Char string
+	/*chars kept at the head of each word for names in latin script, 1 by default
+	surname (include compound surname) is kept for chinese names
+	*/
+	KeepFirst int
+}
+
+type MaskRuleDateParam struct {
+	/*truncate date to "year", "month" or "day", "month" by default*/
+	Truncate string
+	/*more layouts to parse date in Go time format, tried before common layouts
+	common layouts: RFC3339, 2006-01-02 15:04:05, 2006-01-02, 2006/01/02, 20060102, 2006-01
+	number is treated as unix timestamp in seconds, or in milliseconds if bigger than 1e11
+	*/
+	Layouts []string
+}
+
+type MaskRuleAgeParam struct {
+	/*width of age band in years, 10 by default*/
+	Width int
+	/*age is calculated at this date in format 2006-01-02, today by default*/
+	Reference string
+	/*more layouts to parse birthdate, same as MaskRuleDateParam.Layouts
+	birthdate is masked to band label like "30-39", number is treated as age and masked to lower bound of band
+	*/
+	Layouts []string
+}
+
+type MaskRuleRoundParam struct {
+	/*round to multiple of step, 1 by default*/
+	Step float64
+	/*"round", "floor" or "ceil", "round" by default*/
+	Mode string
+	/*bucket boundaries in ascending order, value is replaced by the lower bound of its bucket
+	value less than the first boundary is replaced by the first boundary
+	Step is ignored if set
+	*/
+	Buckets []float64
+}
+
+type MaskRuleGeoParam struct {
+	/*decimal places of coordinates kept, 2 by default (about 1km)*/
+	Decimals int
+	/*precision of geohash (1-12), coordinates are replaced by center of geohash cell if set
+	single number is treated as latitude unless Axis is "lon"
+	*/
+	Geohash int
+	Axis    string
+}
+
+type MaskRulePostcodeParam struct {
+	/*chars kept at the head, 3 by default*/
+	Keep int
+	/*the rest chars are covered by Char instead of cut if set*/
+	Char string
+}
+
+type MaskRuleFakeParam struct {
+	/*kind of fake value, "name", "email", "phone", "address", "card" or "auto"
+	"auto" (default) guesses kind by value, address is never guessed
+	*/
+	Kind string
+	/*same input always gets the same fake value with the same seed, keeps referential integrity across tables
+	a random seed is generated per masker if empty, so fake values are consistent in process only
+	*/
+	Seed string
 }
@@ -3,16 +3,89 @@ package types
 import "fmt"
 
 type KVRules struct {
-	DetectRules []KVDetectConfig
-	MaskRules   []KVMaskConfig
+	DetectRules  []KVDetectConfig
+	MaskRules    []KVMaskConfig
+	Patterns     map[string]string // named regex patterns, referenced by identifiers in KVDetectConfig.Expr
+	KeyNormalize []KVKeyNormalize  // global key normalization, overridden by KVDetectConfig.KeyNormalize
+	/*decode payloads embedded in string values, tried in order, disabled by default
+	rules are applied inside decoded payloads, paths extend into it by JSONPathDecode
+	masked payloads are encoded back to the original encoding
+	*/
+	Decoders []KVDecoder
+	// named word lists, referenced by KVDetectConfig.ValDictionary
+	Dictionaries map[string]KVDictionary
 }
 
+/*
+named rules extending another rule set, resolved into KVRules by ruleset.Resolve:
+inherited detect rules listed in Disable are removed, own detect rules replace inherited rules
+with the same ID in place and others are appended; mask rules replace inherited ones by RuleName,
+patterns & dictionaries by name; own KeyNormalize & Decoders replace inherited ones if set
+*/
+type KVRuleSet struct {
+	Name    string
+	Extends string   // name of base rule set, empty for none
+	Disable []string // IDs of inherited detect rules to remove
+	KVRules
+}
+
+/*
+word list in file, one word per line, spaces around words, blank lines & lines starting with # are skipped
+File is relative to working directory, or to the rules file if loaded by rulefile.Load
+*/
+type KVDictionary struct {
+	File     string
+	Match    KVDictMatch // value equals a word, or contains a word
+	CaseFold bool        // match words case-insensitively by unicode case folding
+}
+
+type KVDictMatch string
+
+const (
+	KVDictMatchDefault  KVDictMatch = ""         // "exact" is default match
+	KVDictMatchExact    KVDictMatch = "exact"    // hash set of words
+	KVDictMatchContains KVDictMatch = "contains" // Aho-Corasick automaton of words
+)
+
+type KVDecoder string
+
+const (
+	KVDecoderJSON   KVDecoder = "json"   // JSON object or array in string
+	KVDecoderBase64 KVDecoder = "base64" // JSON object or array encoded by base64 or base64url, padded or not
+	KVDecoderForm   KVDecoder = "form"   // application/x-www-form-urlencoded, repeated keys are decoded as array
+	KVDecoderJWT    KVDecoder = "jwt"    // claims of JWT, signature is kept as is and no longer valid after masking
+)
+
 type KVPair struct {
 	Key         string
 	Val         interface{}
 	ValJSONPath JSONPath
 	ValMasked   interface{}
 	KVFieldRel  *KVField
+	ObjectKey   bool        // Val is an object key at ValJSONPath, Key is key of the object
+	DetectRule  int         // index of matched rule in KVRules.DetectRules, -1 if masked by struct tag
+	Rule        *KVRuleMeta // metadata of matched rule shared by pairs, nil if rule has none or masked by struct tag
+	Pos         *KVPos      // position in input of Detect, Mask or MaskText, nil if pair is not parsed from input
+}
+
+/*
+position of pair in input, spans are byte offsets [start, end), spans of strings include quotes
+key span is empty if pair has no key in input, e.g. array elements, root or bare tokens of text
+value of object key is the member key, and key is the member key of the object
+pairs in embedded payloads are located at the string they are decoded from
+lines & columns are 1-based, columns count unicode code points
+*/
+type KVPos struct {
+	KeyStart  int
+	KeyEnd    int
+	KeyLine   int // 0 if key span is empty
+	KeyColumn int
+	ValStart  int
+	ValEnd    int
+	Line      int // of value start
+	Column    int
+	EndLine   int // of value end
+	EndColumn int
 }
 
 func (kv *KVPair) GetValString() string {
@@ -21,3 +94,24 @@ func (kv *KVPair) GetValString() string {
 	}
 	return fmt.Sprintf("%v", kv.Val)
 }
+
+func (kv *KVPair) GetValMaskedString() string {
+	if kv.ValMasked == nil {
+		return "null"
+	}
+	return fmt.Sprintf("%v", kv.ValMasked)
+}
+
+// JSON type of value, integer is reported as number
+func (kv *KVPair) GetValType() KVValType {
+	switch kv.Val.(type) {
+	case nil:
+		return KVValTypeNull
+	case string:
+		return KVValTypeString
+	case bool:
+		return KVValTypeBool
+	default:
+		return KVValTypeNumber
+	}
+}